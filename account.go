@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //Account represents an Stormpath account object
 //
 //See: http://docs.stormpath.com/rest/product-guide/#accounts
@@ -66,35 +68,60 @@ func NewAccount(username, password, email, givenName, surname string) *Account {
 
 //GetAccount fetches an account by href and criteria
 func GetAccount(href string, criteria AccountCriteria) (*Account, error) {
+	return GetAccountWithContext(context.Background(), href, criteria)
+}
+
+//GetAccountWithContext is the same as GetAccount with the addition of a context.Context
+func GetAccountWithContext(ctx context.Context, href string, criteria AccountCriteria) (*Account, error) {
 	account := &Account{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		account,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return account, nil
+	return account, err
 }
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (account *Account) Refresh() error {
-	return client.get(account.Href, account)
+	return account.RefreshWithContext(account.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (account *Account) RefreshWithContext(ctx context.Context) error {
+	ctx = account.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, account.Href, account)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (account *Account) Update() error {
-	return client.post(account.Href, account, account)
+	return account.UpdateWithContext(account.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (account *Account) UpdateWithContext(ctx context.Context) error {
+	ctx = account.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, account.Href, account, account)
 }
 
 //AddToGroup adds the given account to a given group and returns the respective GroupMembership
 func (account *Account) AddToGroup(group *Group) (*GroupMembership, error) {
+	return account.AddToGroupWithContext(account.clientContext(), group)
+}
+
+//AddToGroupWithContext is the same as AddToGroup with the addition of a context.Context
+func (account *Account) AddToGroupWithContext(ctx context.Context, group *Group) (*GroupMembership, error) {
+	ctx = account.withClient(ctx)
+	c := ClientFromContext(ctx)
 	groupMembership := NewGroupMembership(account.Href, group.Href)
 
-	err := client.post(buildRelativeURL("groupMemberships"), groupMembership, groupMembership)
+	err := c.post(ctx, c.buildRelativeURL("groupMemberships"), groupMembership, groupMembership)
 
 	if err != nil {
 		return nil, err
@@ -106,7 +133,14 @@ func (account *Account) AddToGroup(group *Group) (*GroupMembership, error) {
 //RemoveFromGroup removes the given account from the given group by searching the account groupmemberships,
 //and deleting the corresponding one
 func (account *Account) RemoveFromGroup(group *Group) error {
-	groupMemberships, err := account.GetGroupMemberships(
+	return account.RemoveFromGroupWithContext(account.clientContext(), group)
+}
+
+//RemoveFromGroupWithContext is the same as RemoveFromGroup with the addition of a context.Context
+func (account *Account) RemoveFromGroupWithContext(ctx context.Context, group *Group) error {
+	ctx = account.withClient(ctx)
+	groupMemberships, err := account.GetGroupMembershipsWithContext(
+		ctx,
 		MakeGroupMemershipCriteria().Offset(0).Limit(25),
 	)
 
@@ -117,11 +151,12 @@ func (account *Account) RemoveFromGroup(group *Group) error {
 	for i := 1; len(groupMemberships.Items) > 0; i++ {
 		for _, gm := range groupMemberships.Items {
 			if gm.Group.Href == group.Href {
-				return gm.Delete()
+				return gm.DeleteWithContext(ctx)
 			}
 		}
-		groupMemberships, err = account.GetGroupMemberships(
-			MakeGroupMemershipCriteria().Offset(i * 25).Limit(25),
+		groupMemberships, err = account.GetGroupMembershipsWithContext(
+			ctx,
+			MakeGroupMemershipCriteria().Offset(i*25).Limit(25),
 		)
 		if err != nil {
 			return err
@@ -133,9 +168,16 @@ func (account *Account) RemoveFromGroup(group *Group) error {
 
 //GetGroupMemberships returns a paged result of the group memeberships of the given account
 func (account *Account) GetGroupMemberships(criteria GroupMembershipCriteria) (*GroupMemberships, error) {
+	return account.GetGroupMembershipsWithContext(account.clientContext(), criteria)
+}
+
+//GetGroupMembershipsWithContext is the same as GetGroupMemberships with the addition of a context.Context
+func (account *Account) GetGroupMembershipsWithContext(ctx context.Context, criteria GroupMembershipCriteria) (*GroupMemberships, error) {
+	ctx = account.withClient(ctx)
 	groupMemberships := &GroupMemberships{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(
 			account.GroupMemberships.Href,
 			criteria.toQueryString(),
@@ -143,19 +185,38 @@ func (account *Account) GetGroupMemberships(criteria GroupMembershipCriteria) (*
 		groupMemberships,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return groupMemberships, nil
+	return groupMemberships, err
+}
+
+//IterateGroupMemberships returns a GroupMembershipIterator over all the group memberships of the Account, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (account *Account) IterateGroupMemberships(criteria GroupMembershipCriteria) *GroupMembershipIterator {
+	return account.IterateGroupMembershipsWithContext(account.clientContext(), criteria)
+}
+
+//IterateGroupMembershipsWithContext is the same as IterateGroupMemberships with the addition of a context.Context
+func (account *Account) IterateGroupMembershipsWithContext(ctx context.Context, criteria GroupMembershipCriteria) *GroupMembershipIterator {
+	ctx = account.withClient(ctx)
+	return newGroupMembershipIterator(ctx, account.GroupMemberships.Href, criteria.baseCriteria)
 }
 
 //VerifyEmailToken verifies an email verification token associated with an account
 //
 //See: http://docs.stormpath.com/rest/product-guide/#account-verify-email
 func VerifyEmailToken(token string) (*Account, error) {
+	return VerifyEmailTokenWithContext(context.Background(), token)
+}
+
+//VerifyEmailTokenWithContext is the same as VerifyEmailToken with the addition of a context.Context
+func VerifyEmailTokenWithContext(ctx context.Context, token string) (*Account, error) {
+	c := ClientFromContext(ctx)
 	account := &Account{}
-	err := client.post(buildRelativeURL("accounts/emailVerificationTokens", token), emptyPayload(), account)
+	err := c.post(ctx, c.buildRelativeURL("accounts/emailVerificationTokens", token), emptyPayload(), account)
 
 	if err != nil {
 		return nil, err
@@ -166,44 +227,103 @@ func VerifyEmailToken(token string) (*Account, error) {
 
 //GetRefreshTokens returns the account's refreshToken collection
 func (account *Account) GetRefreshTokens(criteria OAuthTokenCriteria) (*OAuthTokens, error) {
+	return account.GetRefreshTokensWithContext(account.clientContext(), criteria)
+}
+
+//GetRefreshTokensWithContext is the same as GetRefreshTokens with the addition of a context.Context
+func (account *Account) GetRefreshTokensWithContext(ctx context.Context, criteria OAuthTokenCriteria) (*OAuthTokens, error) {
+	ctx = account.withClient(ctx)
 	refreshTokens := &OAuthTokens{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(account.RefreshTokens.Href, criteria.toQueryString()),
 		refreshTokens,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return refreshTokens, nil
+	return refreshTokens, err
+}
+
+//IterateRefreshTokens returns an OAuthTokenIterator over all the refresh tokens of the Account, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (account *Account) IterateRefreshTokens(criteria OAuthTokenCriteria) *OAuthTokenIterator {
+	return account.IterateRefreshTokensWithContext(account.clientContext(), criteria)
+}
+
+//IterateRefreshTokensWithContext is the same as IterateRefreshTokens with the addition of a context.Context
+func (account *Account) IterateRefreshTokensWithContext(ctx context.Context, criteria OAuthTokenCriteria) *OAuthTokenIterator {
+	ctx = account.withClient(ctx)
+	return newOAuthTokenIterator(ctx, account.RefreshTokens.Href, criteria.baseCriteria)
 }
 
 //GetAccessTokens returns the acounts's accessToken collection
 func (account *Account) GetAccessTokens(criteria OAuthTokenCriteria) (*OAuthTokens, error) {
+	return account.GetAccessTokensWithContext(account.clientContext(), criteria)
+}
+
+//GetAccessTokensWithContext is the same as GetAccessTokens with the addition of a context.Context
+func (account *Account) GetAccessTokensWithContext(ctx context.Context, criteria OAuthTokenCriteria) (*OAuthTokens, error) {
+	ctx = account.withClient(ctx)
 	accessTokens := &OAuthTokens{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(account.AccessTokens.Href, criteria.toQueryString()),
 		accessTokens,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return accessTokens, nil
+	return accessTokens, err
+}
+
+//IterateAccessTokens returns an OAuthTokenIterator over all the access tokens of the Account, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (account *Account) IterateAccessTokens(criteria OAuthTokenCriteria) *OAuthTokenIterator {
+	return account.IterateAccessTokensWithContext(account.clientContext(), criteria)
+}
+
+//IterateAccessTokensWithContext is the same as IterateAccessTokens with the addition of a context.Context
+func (account *Account) IterateAccessTokensWithContext(ctx context.Context, criteria OAuthTokenCriteria) *OAuthTokenIterator {
+	ctx = account.withClient(ctx)
+	return newOAuthTokenIterator(ctx, account.AccessTokens.Href, criteria.baseCriteria)
 }
 
 //CreateAPIKey creates a new API key pair for the given account, it returns a pointer to the APIKey pair.
 func (account *Account) CreateAPIKey() (*APIKey, error) {
+	return account.CreateAPIKeyWithContext(account.clientContext())
+}
+
+//CreateAPIKeyWithContext is the same as CreateAPIKey with the addition of a context.Context
+func (account *Account) CreateAPIKeyWithContext(ctx context.Context) (*APIKey, error) {
+	ctx = account.withClient(ctx)
 	apiKey := &APIKey{}
 
-	err := client.post(account.APIKeys.Href, emptyPayload(), apiKey)
+	err := ClientFromContext(ctx).post(ctx, account.APIKeys.Href, emptyPayload(), apiKey)
 	if err != nil {
 		return nil, err
 	}
 
 	return apiKey, nil
 }
+
+//IterateAPIKeys returns an APIKeyIterator over all the API keys of the Account, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (account *Account) IterateAPIKeys(criteria APIKeyCriteria) *APIKeyIterator {
+	return account.IterateAPIKeysWithContext(account.clientContext(), criteria)
+}
+
+//IterateAPIKeysWithContext is the same as IterateAPIKeys with the addition of a context.Context
+func (account *Account) IterateAPIKeysWithContext(ctx context.Context, criteria APIKeyCriteria) *APIKeyIterator {
+	ctx = account.withClient(ctx)
+	return newAPIKeyIterator(ctx, account.APIKeys.Href, criteria.baseCriteria)
+}
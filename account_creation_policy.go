@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //AccountCreationPolicy represents a directory account creation policy object
 //
 //See: http://docs.stormpath.com/rest/product-guide/#directory-account-creation-policy
@@ -15,43 +17,73 @@ type AccountCreationPolicy struct {
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (policy *AccountCreationPolicy) Refresh() error {
-	return client.get(policy.Href, policy)
+	return policy.RefreshWithContext(policy.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (policy *AccountCreationPolicy) RefreshWithContext(ctx context.Context) error {
+	ctx = policy.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, policy.Href, policy)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (policy *AccountCreationPolicy) Update() error {
-	return client.post(policy.Href, policy, policy)
+	return policy.UpdateWithContext(policy.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (policy *AccountCreationPolicy) UpdateWithContext(ctx context.Context) error {
+	ctx = policy.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, policy.Href, policy, policy)
 }
 
 //GetVerificationEmailTemplates loads the policy VerificationEmailTemplates collection and returns it
 func (policy *AccountCreationPolicy) GetVerificationEmailTemplates() (*EmailTemplates, error) {
-	err := client.get(policy.VerificationEmailTemplates.Href, policy.VerificationEmailTemplates)
+	return policy.GetVerificationEmailTemplatesWithContext(policy.clientContext())
+}
 
-	if err != nil {
+//GetVerificationEmailTemplatesWithContext is the same as GetVerificationEmailTemplates with the addition of a context.Context
+func (policy *AccountCreationPolicy) GetVerificationEmailTemplatesWithContext(ctx context.Context) (*EmailTemplates, error) {
+	ctx = policy.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, policy.VerificationEmailTemplates.Href, policy.VerificationEmailTemplates)
+
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return policy.VerificationEmailTemplates, nil
+	return policy.VerificationEmailTemplates, err
 }
 
 //GetVerificationSuccessEmailTemplates loads the policy VerificationSuccessEmailTemplates collection and returns it
 func (policy *AccountCreationPolicy) GetVerificationSuccessEmailTemplates() (*EmailTemplates, error) {
-	err := client.get(policy.VerificationSuccessEmailTemplates.Href, policy.VerificationSuccessEmailTemplates)
+	return policy.GetVerificationSuccessEmailTemplatesWithContext(policy.clientContext())
+}
+
+//GetVerificationSuccessEmailTemplatesWithContext is the same as GetVerificationSuccessEmailTemplates with the addition of a context.Context
+func (policy *AccountCreationPolicy) GetVerificationSuccessEmailTemplatesWithContext(ctx context.Context) (*EmailTemplates, error) {
+	ctx = policy.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, policy.VerificationSuccessEmailTemplates.Href, policy.VerificationSuccessEmailTemplates)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return policy.VerificationSuccessEmailTemplates, nil
+	return policy.VerificationSuccessEmailTemplates, err
 }
 
 //GetWelcomeEmailTemplates loads the policy WelcomeEmailTemplates collection and returns it
 func (policy *AccountCreationPolicy) GetWelcomeEmailTemplates() (*EmailTemplates, error) {
-	err := client.get(policy.WelcomeEmailTemplates.Href, policy.WelcomeEmailTemplates)
+	return policy.GetWelcomeEmailTemplatesWithContext(policy.clientContext())
+}
+
+//GetWelcomeEmailTemplatesWithContext is the same as GetWelcomeEmailTemplates with the addition of a context.Context
+func (policy *AccountCreationPolicy) GetWelcomeEmailTemplatesWithContext(ctx context.Context) (*EmailTemplates, error) {
+	ctx = policy.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, policy.WelcomeEmailTemplates.Href, policy.WelcomeEmailTemplates)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return policy.WelcomeEmailTemplates, nil
+	return policy.WelcomeEmailTemplates, err
 }
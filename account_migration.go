@@ -0,0 +1,393 @@
+package stormpath
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+//AccountFormat is the file format of an account export or import
+type AccountFormat int
+
+//Supported account formats
+//
+//AccountFormatNDJSON writes one JSON AccountRecord per line.
+//
+//AccountFormatCSV writes a header row followed by one row per account, the groups column holds the group names
+//as a JSON array and the customData column holds the custom data as a JSON object.
+const (
+	AccountFormatNDJSON AccountFormat = iota
+	AccountFormatCSV
+)
+
+//MCFPasswordFormat is the passwordFormat value used to create accounts with a password hash in Modular Crypt Format
+//
+//See: https://docs.stormpath.com/rest/product-guide/latest/accnt_mgmt.html#importing-accounts-with-mcf-hash-passwords
+const MCFPasswordFormat = "mcf"
+
+//Custom data attributes managed by Stormpath that are not exported
+var reservedCustomDataAttributes = []string{"href", "createdAt", "modifiedAt"}
+
+//Columns of the CSV account format
+var accountCSVHeader = []string{"username", "email", "givenName", "middleName", "surname", "status", "password", "groups", "customData"}
+
+//AccountRecord is the exported or imported representation of an account, its profile, status,
+//custom data and group names.
+//
+//Password is only used on import and holds the password hash in Modular Crypt Format (e.g. a bcrypt $2a$ hash
+//or a Stormpath $stormpath2$ PBKDF2 hash), Stormpath never returns password hashes so exports leave it empty.
+type AccountRecord struct {
+	Username   string     `json:"username,omitempty"`
+	Email      string     `json:"email"`
+	GivenName  string     `json:"givenName,omitempty"`
+	MiddleName string     `json:"middleName,omitempty"`
+	Surname    string     `json:"surname,omitempty"`
+	Status     string     `json:"status,omitempty"`
+	Password   string     `json:"password,omitempty"`
+	CustomData CustomData `json:"customData,omitempty"`
+	Groups     []string   `json:"groups,omitempty"`
+}
+
+//newAccountRecord creates the AccountRecord for the given account and group names
+func newAccountRecord(account *Account, groups []string) AccountRecord {
+	record := AccountRecord{
+		Username:   account.Username,
+		Email:      account.Email,
+		GivenName:  account.GivenName,
+		MiddleName: account.MiddleName,
+		Surname:    account.Surname,
+		Status:     account.Status,
+		Groups:     groups,
+	}
+
+	if account.CustomData != nil {
+		record.CustomData = CustomData{}
+		for k, v := range *account.CustomData {
+			record.CustomData[k] = v
+		}
+		for _, k := range reservedCustomDataAttributes {
+			delete(record.CustomData, k)
+		}
+		if len(record.CustomData) == 0 {
+			record.CustomData = nil
+		}
+	}
+
+	return record
+}
+
+//toAccount creates a new Account with the record profile, status and password
+func (record AccountRecord) toAccount() *Account {
+	return &Account{
+		Username:   record.Username,
+		Email:      record.Email,
+		GivenName:  record.GivenName,
+		MiddleName: record.MiddleName,
+		Surname:    record.Surname,
+		Status:     record.Status,
+		Password:   record.Password,
+	}
+}
+
+//accountRecordWriter writes AccountRecords in a given AccountFormat
+type accountRecordWriter interface {
+	Write(record AccountRecord) error
+	Flush() error
+}
+
+func newAccountRecordWriter(w io.Writer, format AccountFormat) (accountRecordWriter, error) {
+	switch format {
+	case AccountFormatNDJSON:
+		return ndjsonAccountWriter{json.NewEncoder(w)}, nil
+	case AccountFormatCSV:
+		writer := csv.NewWriter(w)
+		return csvAccountWriter{writer}, writer.Write(accountCSVHeader)
+	}
+	return nil, fmt.Errorf("unsupported account format %d", format)
+}
+
+type ndjsonAccountWriter struct {
+	encoder *json.Encoder
+}
+
+func (w ndjsonAccountWriter) Write(record AccountRecord) error {
+	return w.encoder.Encode(record)
+}
+
+func (w ndjsonAccountWriter) Flush() error {
+	return nil
+}
+
+type csvAccountWriter struct {
+	writer *csv.Writer
+}
+
+func (w csvAccountWriter) Write(record AccountRecord) error {
+	//Group names can hold any character, a JSON array keeps them unambiguous
+	groups := ""
+	if len(record.Groups) > 0 {
+		data, err := json.Marshal(record.Groups)
+		if err != nil {
+			return err
+		}
+		groups = string(data)
+	}
+
+	customData := ""
+	if len(record.CustomData) > 0 {
+		data, err := json.Marshal(record.CustomData)
+		if err != nil {
+			return err
+		}
+		customData = string(data)
+	}
+
+	return w.writer.Write([]string{
+		record.Username,
+		record.Email,
+		record.GivenName,
+		record.MiddleName,
+		record.Surname,
+		record.Status,
+		record.Password,
+		groups,
+		customData,
+	})
+}
+
+func (w csvAccountWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+//accountRecordReader reads AccountRecords in a given AccountFormat, Read returns io.EOF when there are no more records
+type accountRecordReader interface {
+	Read() (AccountRecord, error)
+}
+
+func newAccountRecordReader(r io.Reader, format AccountFormat) (accountRecordReader, error) {
+	switch format {
+	case AccountFormatNDJSON:
+		return &ndjsonAccountReader{decoder: json.NewDecoder(r)}, nil
+	case AccountFormatCSV:
+		reader := csv.NewReader(r)
+		header, err := reader.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		known := map[string]bool{}
+		for _, name := range accountCSVHeader {
+			known[name] = true
+		}
+
+		columns := map[string]int{}
+		for i, name := range header {
+			name = strings.TrimSpace(name)
+			if !known[name] {
+				return nil, fmt.Errorf("unknown account CSV column %q", name)
+			}
+			columns[name] = i
+		}
+		if _, ok := columns["email"]; !ok {
+			return nil, fmt.Errorf("missing account CSV column %q", "email")
+		}
+
+		return &csvAccountReader{reader: reader, columns: columns}, nil
+	}
+	return nil, fmt.Errorf("unsupported account format %d", format)
+}
+
+type ndjsonAccountReader struct {
+	decoder *json.Decoder
+	line    int
+}
+
+func (r *ndjsonAccountReader) Read() (AccountRecord, error) {
+	record := AccountRecord{}
+	r.line++
+
+	err := r.decoder.Decode(&record)
+	if err != nil && err != io.EOF {
+		return record, fmt.Errorf("account record %d: %s", r.line, err)
+	}
+	return record, err
+}
+
+type csvAccountReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+	line    int
+}
+
+func (r *csvAccountReader) Read() (AccountRecord, error) {
+	row, err := r.reader.Read()
+	if err != nil {
+		return AccountRecord{}, err
+	}
+	r.line++
+
+	column := func(name string) string {
+		if i, ok := r.columns[name]; ok {
+			return row[i]
+		}
+		return ""
+	}
+
+	record := AccountRecord{
+		Username:   column("username"),
+		Email:      column("email"),
+		GivenName:  column("givenName"),
+		MiddleName: column("middleName"),
+		Surname:    column("surname"),
+		Status:     column("status"),
+		Password:   column("password"),
+	}
+	if groups := column("groups"); groups != "" {
+		if err := json.Unmarshal([]byte(groups), &record.Groups); err != nil {
+			return record, fmt.Errorf("account record %d: invalid groups: %s", r.line, err)
+		}
+	}
+	if customData := column("customData"); customData != "" {
+		if err := json.Unmarshal([]byte(customData), &record.CustomData); err != nil {
+			return record, fmt.Errorf("account record %d: invalid customData: %s", r.line, err)
+		}
+	}
+
+	return record, nil
+}
+
+//exportAccounts scans the accounts collection writing an AccountRecord for each account
+func exportAccounts(ctx context.Context, href string, criteria AccountCriteria, w io.Writer, format AccountFormat) error {
+	writer, err := newAccountRecordWriter(w, format)
+	if err != nil {
+		return err
+	}
+
+	criteria = criteria.WithCustomData().WithGroups(PageRequest{Limit: DefaultIteratorPageSize})
+
+	err = scanAccounts(ctx, href, criteria.baseCriteria, 0, func(account *Account) error {
+		groups, err := accountGroupNames(ctx, account)
+		if err != nil {
+			return err
+		}
+		return writer.Write(newAccountRecord(account, groups))
+	})
+	if err != nil {
+		return err
+	}
+
+	return writer.Flush()
+}
+
+//accountGroupNames returns the names of the account groups, using the expanded groups page if it holds all of them
+func accountGroupNames(ctx context.Context, account *Account) ([]string, error) {
+	names := []string{}
+	if account.Groups == nil {
+		return names, nil
+	}
+
+	if account.Groups.Size != nil && *account.Groups.Size <= len(account.Groups.Items) {
+		for _, group := range account.Groups.Items {
+			names = append(names, group.Name)
+		}
+		return names, nil
+	}
+
+	it := newGroupIterator(ctx, account.Groups.Href, MakeGroupsCriteria().Limit(DefaultIteratorPageSize).baseCriteria)
+	for it.Next() {
+		names = append(names, it.Value().Name)
+	}
+
+	return names, it.Err()
+}
+
+//importAccounts reads the AccountRecords and registers them into the directory as a bulk job,
+//the groups are looked up by name in the directory and created if missing
+func importAccounts(ctx context.Context, dir *Directory, r io.Reader, format AccountFormat, options BulkOptions) (*BulkReport, error) {
+	reader, err := newAccountRecordReader(r, format)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := map[string]*Group{}
+	it := dir.IterateGroupsWithContext(ctx, MakeGroupsCriteria().Limit(DefaultIteratorPageSize))
+	for it.Next() {
+		groups[it.Value().Name] = it.Value()
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+
+	accounts := make(chan BulkAccount)
+	readErr := make(chan error, 1)
+
+	go func() {
+		defer close(accounts)
+
+		for {
+			record, err := reader.Read()
+			if err == io.EOF {
+				readErr <- nil
+				return
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+
+			account := BulkAccount{Account: record.toAccount(), CustomData: record.CustomData}
+			for _, name := range record.Groups {
+				group, ok := groups[name]
+				if !ok {
+					group = NewGroup(name)
+					if err := dir.CreateGroupWithContext(ctx, group); err != nil {
+						readErr <- err
+						return
+					}
+					groups[name] = group
+				}
+				account.Groups = append(account.Groups, group)
+			}
+
+			select {
+			case accounts <- account:
+			case <-ctx.Done():
+				readErr <- nil
+				return
+			}
+		}
+	}()
+
+	report, err := bulkRegisterAccounts(ctx, dir.Accounts.Href, func(ctx context.Context, account *Account) error {
+		return registerMCFAccount(ctx, dir.Accounts.Href, account)
+	}, accounts, options)
+	if err != nil {
+		return report, err
+	}
+
+	return report, <-readErr
+}
+
+//registerMCFAccount creates the account in the given accounts collection, a non empty password must be
+//a Modular Crypt Format hash and the account is created with passwordFormat=mcf
+func registerMCFAccount(ctx context.Context, accountsHref string, account *Account) error {
+	url := accountsHref
+	if account.Password != "" {
+		if !strings.HasPrefix(account.Password, "$") {
+			return Error{Status: http.StatusBadRequest, Code: ErrorCodeInvalidProperty, Message: "Account password is not a Modular Crypt Format hash."}
+		}
+		url = buildAbsoluteURL(accountsHref, requestParams(map[string][]string{"passwordFormat": {MCFPasswordFormat}}))
+	}
+
+	err := ClientFromContext(ctx).post(ctx, url, account, account)
+	if err == nil {
+		//The password hash should be cleanup so we don't keep it in memory
+		account.Password = ""
+	}
+	return err
+}
@@ -0,0 +1,211 @@
+package stormpath
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type migrationTestServer struct {
+	*testServer
+	mutex  sync.Mutex
+	groups []string
+}
+
+func newMigrationTestServer() *migrationTestServer {
+	s := &migrationTestServer{groups: []string{"admins"}}
+	s.testServer = newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/directories/dir/accounts/":
+			w.Write([]byte(`{"href":"accounts","offset":0,"limit":100,"size":2,"items":[
+				{"href":"accounts/1","username":"john","email":"john@test.org","givenName":"John","surname":"Doe","status":"ENABLED",
+				 "customData":{"href":"accounts/1/customData","createdAt":"2016-01-01T00:00:00Z","plan":"gold"},
+				 "groups":{"href":"accounts/1/groups","size":2,"items":[{"href":"groups/1","name":"admins"},{"href":"groups/2","name":"users"}]}},
+				{"href":"accounts/2","username":"jane","email":"jane@test.org","givenName":"Jane","surname":"Doe","status":"DISABLED",
+				 "customData":{"href":"accounts/2/customData"},
+				 "groups":{"href":"accounts/2/groups","size":0,"items":[]}}
+			]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/v1/directories/dir/groups/":
+			items := []map[string]interface{}{}
+			for _, name := range s.groups {
+				items = append(items, map[string]interface{}{"href": s.URL + "/v1/groups/" + name, "name": name})
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": "groups", "offset": 0, "limit": 100, "size": len(items), "items": items})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/directories/dir/groups":
+			name := payload["name"].(string)
+			s.groups = append(s.groups, name)
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": s.URL + "/v1/groups/" + name, "name": name})
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/directories/dir/accounts"):
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": s.URL + "/v1/accounts/" + payload["email"].(string)})
+		case r.Method == http.MethodPost && r.URL.Path == "/v1/groupMemberships":
+			w.Write([]byte(`{"href":"groupMemberships/1"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return s
+}
+
+//created returns the query and payload of the accounts created in the directory
+func (s *migrationTestServer) created() ([]string, []map[string]interface{}) {
+	queries, payloads := []string{}, []map[string]interface{}{}
+	for _, request := range s.requestsTo(http.MethodPost, "/v1/directories/dir/accounts") {
+		payload := map[string]interface{}{}
+		json.Unmarshal(request.body, &payload)
+		queries = append(queries, request.query.Encode())
+		payloads = append(payloads, payload)
+	}
+	return queries, payloads
+}
+
+func newMigrationTestDirectory(server *migrationTestServer) (context.Context, *Directory) {
+	directory := &Directory{}
+	directory.Href = server.URL + "/v1/directories/dir"
+	directory.Accounts = &Accounts{}
+	directory.Accounts.Href = directory.Href + "/accounts"
+	directory.Groups = &Groups{}
+	directory.Groups.Href = directory.Href + "/groups"
+
+	ctx, _ := newTestContext(server.configuration(), nil)
+	return ctx, directory
+}
+
+func TestExportAccountsNDJSON(t *testing.T) {
+	t.Parallel()
+
+	server := newMigrationTestServer()
+	defer server.Close()
+	ctx, directory := newMigrationTestDirectory(server)
+
+	out := &bytes.Buffer{}
+	err := directory.ExportAccountsWithContext(ctx, out, AccountFormatNDJSON, MakeAccountsCriteria())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "customData,groups(offset:0,limit:100)", server.requestsTo(http.MethodGet, "/v1/directories/dir/accounts")[0].query.Get("expand"))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"username":"john","email":"john@test.org","givenName":"John","surname":"Doe","status":"ENABLED","customData":{"plan":"gold"},"groups":["admins","users"]}`, lines[0])
+	assert.JSONEq(t, `{"username":"jane","email":"jane@test.org","givenName":"Jane","surname":"Doe","status":"DISABLED"}`, lines[1])
+}
+
+func TestExportAccountsCSVRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	server := newMigrationTestServer()
+	defer server.Close()
+	ctx, directory := newMigrationTestDirectory(server)
+
+	out := &bytes.Buffer{}
+	err := directory.ExportAccountsWithContext(ctx, out, AccountFormatCSV, MakeAccountsCriteria())
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "username,email,givenName,middleName,surname,status,password,groups,customData\n"))
+
+	reader, err := newAccountRecordReader(out, AccountFormatCSV)
+	assert.NoError(t, err)
+
+	record, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, "john@test.org", record.Email)
+	assert.Equal(t, []string{"admins", "users"}, record.Groups)
+	assert.Equal(t, CustomData{"plan": "gold"}, record.CustomData)
+
+	record, err = reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, Disabled, record.Status)
+	assert.Empty(t, record.Groups)
+	assert.Nil(t, record.CustomData)
+
+	_, err = reader.Read()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestAccountCSVGroupNames(t *testing.T) {
+	t.Parallel()
+
+	out := &bytes.Buffer{}
+	writer, err := newAccountRecordWriter(out, AccountFormatCSV)
+	assert.NoError(t, err)
+	groups := []string{"R&D; Ops", `"quoted", group`, "[admins]"}
+	assert.NoError(t, writer.Write(AccountRecord{Email: "john@test.org", Groups: groups}))
+	assert.NoError(t, writer.Flush())
+
+	reader, err := newAccountRecordReader(out, AccountFormatCSV)
+	assert.NoError(t, err)
+
+	record, err := reader.Read()
+	assert.NoError(t, err)
+	assert.Equal(t, groups, record.Groups)
+
+	reader, err = newAccountRecordReader(strings.NewReader("email,groups\njohn@test.org,admins;editors\n"), AccountFormatCSV)
+	assert.NoError(t, err)
+
+	_, err = reader.Read()
+	assert.Error(t, err)
+}
+
+func TestImportAccountsMCF(t *testing.T) {
+	t.Parallel()
+
+	server := newMigrationTestServer()
+	defer server.Close()
+	ctx, directory := newMigrationTestDirectory(server)
+
+	in := strings.NewReader(`email,username,password,groups,customData
+john@test.org,john,$2a$10$ya4cVV0pJbZNt3Fdh6Hdt.EPvdNx0i7Lu3XbUm2.Xb2tRxtBdWWLO,"[""admins"",""editors""]","{""plan"":""gold""}"
+jane@test.org,jane,plaintext,,
+`)
+	report, err := directory.ImportAccountsWithContext(ctx, in, AccountFormatCSV, BulkOptions{Workers: 1})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 2)
+	assert.True(t, report.Results[0].Succeeded())
+	assert.Equal(t, ErrorCodeInvalidProperty, report.Results[1].Error.Code)
+
+	queries, created := server.created()
+	assert.Len(t, created, 1)
+	assert.Equal(t, []string{"passwordFormat=mcf"}, queries)
+	assert.Equal(t, "$2a$10$ya4cVV0pJbZNt3Fdh6Hdt.EPvdNx0i7Lu3XbUm2.Xb2tRxtBdWWLO", created[0]["password"])
+	assert.Equal(t, map[string]interface{}{"plan": "gold"}, created[0]["customData"])
+	assert.Equal(t, []string{"admins", "editors"}, server.groups)
+	assert.Equal(t, 2, server.count(http.MethodPost, "/v1/groupMemberships"))
+}
+
+func TestImportAccountsNDJSONReadError(t *testing.T) {
+	t.Parallel()
+
+	server := newMigrationTestServer()
+	defer server.Close()
+	ctx, directory := newMigrationTestDirectory(server)
+
+	in := strings.NewReader(`{"email":"john@test.org","password":"$stormpath2$MD5$1$OGYyYzYwNDc1$iczI4xf2S5d6cvFDZD14Bg=="}
+{"email":
+`)
+	report, err := directory.ImportAccountsWithContext(ctx, in, AccountFormatNDJSON, BulkOptions{})
+
+	assert.Error(t, err)
+	assert.Len(t, report.Results, 1)
+	assert.True(t, report.Results[0].Succeeded())
+}
+
+func TestImportAccountsUnknownCSVColumn(t *testing.T) {
+	t.Parallel()
+
+	_, err := (&Directory{}).ImportAccounts(strings.NewReader("email,phone\n"), AccountFormatCSV, BulkOptions{})
+
+	assert.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package stormpath
 
-import "strings"
+import (
+	"context"
+	"strings"
+)
 
 //ApplicationAccountStoreMapping represents an Stormpath account store mapping
 //
@@ -62,22 +65,36 @@ func NewOrganizationAccountStoreMapping(organizationHref string, accountStoreHre
 
 //Save saves the given ApplicationAccountStoreMapping
 func (mapping *ApplicationAccountStoreMapping) Save() error {
-	url := buildRelativeURL("accountStoreMappings")
+	return mapping.SaveWithContext(mapping.clientContext())
+}
+
+//SaveWithContext is the same as Save with the addition of a context.Context
+func (mapping *ApplicationAccountStoreMapping) SaveWithContext(ctx context.Context) error {
+	ctx = mapping.withClient(ctx)
+	c := ClientFromContext(ctx)
+	url := c.buildRelativeURL("accountStoreMappings")
 	if mapping.Href != "" {
 		url = mapping.Href
 	}
 
-	return client.post(url, mapping, mapping)
+	return c.post(ctx, url, mapping, mapping)
 }
 
 //Save saves the given OrganizationAccountStoreMapping
 func (mapping *OrganizationAccountStoreMapping) Save() error {
-	url := buildRelativeURL("organizationAccountStoreMappings")
+	return mapping.SaveWithContext(mapping.clientContext())
+}
+
+//SaveWithContext is the same as Save with the addition of a context.Context
+func (mapping *OrganizationAccountStoreMapping) SaveWithContext(ctx context.Context) error {
+	ctx = mapping.withClient(ctx)
+	c := ClientFromContext(ctx)
+	url := c.buildRelativeURL("organizationAccountStoreMappings")
 	if mapping.Href != "" {
 		url = mapping.Href
 	}
 
-	return client.post(url, mapping, mapping)
+	return c.post(ctx, url, mapping, mapping)
 }
 
 //IsAccountStoreDirectory checks if a given ApplicationAccountStoreMapping maps an Application to a Directory
@@ -1,6 +1,9 @@
 package stormpath
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 //APIKey represents an Account key id/secret pair resource
 //
@@ -37,27 +40,45 @@ func MakeAPIKeysCriteria() APIKeyCriteria {
 
 //GetAPIKey retrives an APIKey resource by href and optional criteria
 func GetAPIKey(href string, criteria APIKeyCriteria) (*APIKey, error) {
+	return GetAPIKeyWithContext(context.Background(), href, criteria)
+}
+
+//GetAPIKeyWithContext is the same as GetAPIKey with the addition of a context.Context
+func GetAPIKeyWithContext(ctx context.Context, href string, criteria APIKeyCriteria) (*APIKey, error) {
 	apiKey := &APIKey{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		apiKey,
 	)
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return apiKey, nil
+	return apiKey, err
 }
 
 //Delete deletes a given APIKey
 func (k *APIKey) Delete() error {
-	return client.delete(k.Href)
+	return k.DeleteWithContext(k.clientContext())
+}
+
+//DeleteWithContext is the same as Delete with the addition of a context.Context
+func (k *APIKey) DeleteWithContext(ctx context.Context) error {
+	ctx = k.withClient(ctx)
+	return ClientFromContext(ctx).delete(ctx, k.Href)
 }
 
 //Update updates the given APIKey against Stormpath
 func (k *APIKey) Update() error {
-	return client.post(k.Href, map[string]string{"status": k.Status}, k)
+	return k.UpdateWithContext(k.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (k *APIKey) UpdateWithContext(ctx context.Context) error {
+	ctx = k.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, k.Href, map[string]string{"status": k.Status}, k)
 }
 
 //WithAccount adds the account expansion to the given APIKeyCriteria
@@ -1,6 +1,7 @@
 package stormpath
 
 import (
+	"context"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -53,89 +54,139 @@ type IDSiteOptions struct {
 //CreateApplication creates a new application in Stormpath.
 //It also passes the createDirectory param as true so the default directory would be created and mapped to the application.
 func CreateApplication(app *Application) error {
+	return CreateApplicationWithContext(context.Background(), app)
+}
+
+//CreateApplicationWithContext is the same as CreateApplication with the addition of a context.Context
+func CreateApplicationWithContext(ctx context.Context, app *Application) error {
 	var extraParams = url.Values{}
 	extraParams.Add("createDirectory", "true")
 
-	return client.post(buildRelativeURL("applications", requestParams(extraParams)), app, app)
+	c := ClientFromContext(ctx)
+	return c.post(ctx, c.buildRelativeURL("applications", requestParams(extraParams)), app, app)
 }
 
 //GetApplication loads an application by href.
 //It can optionally have its attributes expanded depending on the ApplicationCriteria value.
 func GetApplication(href string, criteria ApplicationCriteria) (*Application, error) {
+	return GetApplicationWithContext(context.Background(), href, criteria)
+}
+
+//GetApplicationWithContext is the same as GetApplication with the addition of a context.Context
+func GetApplicationWithContext(ctx context.Context, href string, criteria ApplicationCriteria) (*Application, error) {
 	application := &Application{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		application,
 	)
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return application, nil
+	return application, err
 }
 
 //Refresh refreshes the application based on the latest state from Stormpath.
 func (app *Application) Refresh() error {
-	return client.get(app.Href, app)
+	return app.RefreshWithContext(app.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (app *Application) RefreshWithContext(ctx context.Context) error {
+	ctx = app.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, app.Href, app)
 }
 
 //Update updates the application in Stormpath.
 func (app *Application) Update() error {
-	return client.post(app.Href, app, app)
+	return app.UpdateWithContext(app.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (app *Application) UpdateWithContext(ctx context.Context) error {
+	ctx = app.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, app.Href, app, app)
 }
 
 //Purge deletes the application and all its account stores.
 func (app *Application) Purge() error {
-	accountStoreMappings, err := app.GetAccountStoreMappings(MakeApplicationAccountStoreMappingsCriteria())
+	return app.PurgeWithContext(app.clientContext())
+}
+
+//PurgeWithContext is the same as Purge with the addition of a context.Context
+func (app *Application) PurgeWithContext(ctx context.Context) error {
+	ctx = app.withClient(ctx)
+	accountStoreMappings, err := app.GetAccountStoreMappingsWithContext(ctx, MakeApplicationAccountStoreMappingsCriteria())
 	if err != nil {
 		return err
 	}
 
 	for _, m := range accountStoreMappings.Items {
-		client.delete(m.AccountStore.Href)
+		ClientFromContext(ctx).delete(ctx, m.AccountStore.Href)
 	}
 
-	return app.Delete()
+	return app.DeleteWithContext(ctx)
 }
 
 //GetAccountStoreMappings retrives the collection of all account store mappings associated with the Application.
 //
 //The collection can be filtered and/or paginated by passing the desire ApplicationAccountStoreMappingCriteria value
 func (app *Application) GetAccountStoreMappings(criteria ApplicationAccountStoreMappingCriteria) (*ApplicationAccountStoreMappings, error) {
+	return app.GetAccountStoreMappingsWithContext(app.clientContext(), criteria)
+}
+
+//GetAccountStoreMappingsWithContext is the same as GetAccountStoreMappings with the addition of a context.Context
+func (app *Application) GetAccountStoreMappingsWithContext(ctx context.Context, criteria ApplicationAccountStoreMappingCriteria) (*ApplicationAccountStoreMappings, error) {
+	ctx = app.withClient(ctx)
 	accountStoreMappings := &ApplicationAccountStoreMappings{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(app.AccountStoreMappings.Href, criteria.toQueryString()),
 		accountStoreMappings,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return accountStoreMappings, nil
+	return accountStoreMappings, err
 }
 
 //GetDefaultAccountStoreMapping retrieves the application default application account store mapping.
 //
 //It can optionally have its attributes expanded depending on the ApplicationAccountStoreMappingCriteria value.
 func (app *Application) GetDefaultAccountStoreMapping(criteria ApplicationAccountStoreMappingCriteria) (*ApplicationAccountStoreMapping, error) {
-	err := client.get(
+	return app.GetDefaultAccountStoreMappingWithContext(app.clientContext(), criteria)
+}
+
+//GetDefaultAccountStoreMappingWithContext is the same as GetDefaultAccountStoreMapping with the addition of a context.Context
+func (app *Application) GetDefaultAccountStoreMappingWithContext(ctx context.Context, criteria ApplicationAccountStoreMappingCriteria) (*ApplicationAccountStoreMapping, error) {
+	ctx = app.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(app.DefaultAccountStoreMapping.Href, criteria.toQueryString()),
 		app.DefaultAccountStoreMapping,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return app.DefaultAccountStoreMapping, nil
+	return app.DefaultAccountStoreMapping, err
 }
 
 //RegisterAccount registers a new account into the application.
 func (app *Application) RegisterAccount(account *Account) error {
-	err := client.post(app.Accounts.Href, account, account)
+	return app.RegisterAccountWithContext(app.clientContext(), account)
+}
+
+//RegisterAccountWithContext is the same as RegisterAccount with the addition of a context.Context
+func (app *Application) RegisterAccountWithContext(ctx context.Context, account *Account) error {
+	ctx = app.withClient(ctx)
+	err := ClientFromContext(ctx).post(ctx, app.Accounts.Href, account, account)
 	if err == nil {
 		//Password should be cleanup so we don't keep an unhash password in memory
 		account.Password = ""
@@ -143,11 +194,43 @@ func (app *Application) RegisterAccount(account *Account) error {
 	return err
 }
 
+//BulkRegisterAccounts registers the given accounts into the application with a pool of workers,
+//setting their custom data and adding them to their groups.
+//
+//It returns a report with the result of every account, a failed account doesn't stop the job.
+func (app *Application) BulkRegisterAccounts(accounts []BulkAccount, options BulkOptions) (*BulkReport, error) {
+	return app.BulkRegisterAccountsWithContext(app.clientContext(), accounts, options)
+}
+
+//BulkRegisterAccountsWithContext is the same as BulkRegisterAccounts with the addition of a context.Context
+func (app *Application) BulkRegisterAccountsWithContext(ctx context.Context, accounts []BulkAccount, options BulkOptions) (*BulkReport, error) {
+	ctx = app.withClient(ctx)
+	return app.BulkRegisterAccountStreamWithContext(ctx, bulkAccountsChannel(accounts), options)
+}
+
+//BulkRegisterAccountStream is the same as BulkRegisterAccounts but reads the accounts from the given channel until it is closed
+func (app *Application) BulkRegisterAccountStream(accounts <-chan BulkAccount, options BulkOptions) (*BulkReport, error) {
+	return app.BulkRegisterAccountStreamWithContext(app.clientContext(), accounts, options)
+}
+
+//BulkRegisterAccountStreamWithContext is the same as BulkRegisterAccountStream with the addition of a context.Context,
+//when it is done the job stops and the report of the accounts processed so far is returned with the context error
+func (app *Application) BulkRegisterAccountStreamWithContext(ctx context.Context, accounts <-chan BulkAccount, options BulkOptions) (*BulkReport, error) {
+	ctx = app.withClient(ctx)
+	return bulkRegisterAccounts(ctx, app.Accounts.Href, app.RegisterAccountWithContext, accounts, options)
+}
+
 //RegisterSocialAccount registers a new account into the application using an external social provider Google, Facebook, GitHub or LinkedIn.
 func (app *Application) RegisterSocialAccount(socialAccount *SocialAccount) (*Account, error) {
+	return app.RegisterSocialAccountWithContext(app.clientContext(), socialAccount)
+}
+
+//RegisterSocialAccountWithContext is the same as RegisterSocialAccount with the addition of a context.Context
+func (app *Application) RegisterSocialAccountWithContext(ctx context.Context, socialAccount *SocialAccount) (*Account, error) {
+	ctx = app.withClient(ctx)
 	account := &Account{}
 
-	err := client.post(app.Accounts.Href, socialAccount, account)
+	err := ClientFromContext(ctx).post(ctx, app.Accounts.Href, socialAccount, account)
 
 	if err != nil {
 		return nil, err
@@ -159,6 +242,12 @@ func (app *Application) RegisterSocialAccount(socialAccount *SocialAccount) (*Ac
 //AuthenticateAccount authenticates an account against the application, using its username and password.
 //It can also include an optional account store HREF, if the accountStoreHref is a zero value string, then it won't be used.
 func (app *Application) AuthenticateAccount(username string, password string, accountStoreHref string) (*Account, error) {
+	return app.AuthenticateAccountWithContext(app.clientContext(), username, password, accountStoreHref)
+}
+
+//AuthenticateAccountWithContext is the same as AuthenticateAccount with the addition of a context.Context
+func (app *Application) AuthenticateAccountWithContext(ctx context.Context, username string, password string, accountStoreHref string) (*Account, error) {
+	ctx = app.withClient(ctx)
 	accountRef := &accountRef{Account: &Account{}}
 
 	loginAttemptPayload := make(map[string]interface{})
@@ -170,14 +259,14 @@ func (app *Application) AuthenticateAccount(username string, password string, ac
 		}
 	}
 
-	err := client.post(buildAbsoluteURL(app.Href, "loginAttempts"), loginAttemptPayload, accountRef)
+	err := ClientFromContext(ctx).post(ctx, buildAbsoluteURL(app.Href, "loginAttempts"), loginAttemptPayload, accountRef)
 	if err != nil {
 		return nil, err
 	}
 
 	account := accountRef.Account
 	//Refresh the account since we only get the account href from the loginAttempts endpoit.
-	err = account.Refresh()
+	err = account.RefreshWithContext(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -189,22 +278,34 @@ func (app *Application) AuthenticateAccount(username string, password string, ac
 //
 //For more info on the Stormpath verification workflow see: http://docs.stormpath.com/rest/product-guide/latest/accnt_mgmt.html#how-to-verify-an-account-s-email
 func (app *Application) ResendVerificationEmail(email string) error {
+	return app.ResendVerificationEmailWithContext(app.clientContext(), email)
+}
+
+//ResendVerificationEmailWithContext is the same as ResendVerificationEmail with the addition of a context.Context
+func (app *Application) ResendVerificationEmailWithContext(ctx context.Context, email string) error {
+	ctx = app.withClient(ctx)
 	resendVerificationEmailPayload := map[string]string{
 		"login": email,
 	}
-	return client.post(buildAbsoluteURL(app.Href, "verificationEmails"), resendVerificationEmailPayload, nil)
+	return ClientFromContext(ctx).post(ctx, buildAbsoluteURL(app.Href, "verificationEmails"), resendVerificationEmailPayload, nil)
 }
 
 //SendPasswordResetEmail triggers a send of the password reset email in Stormpath for a given email address.
 //
 //For more info on the Stormpath password reset workflow see: http://docs.stormpath.com/rest/product-guide/latest/accnt_mgmt.html#password-reset-flow
 func (app *Application) SendPasswordResetEmail(email string) (*AccountPasswordResetToken, error) {
+	return app.SendPasswordResetEmailWithContext(app.clientContext(), email)
+}
+
+//SendPasswordResetEmailWithContext is the same as SendPasswordResetEmail with the addition of a context.Context
+func (app *Application) SendPasswordResetEmailWithContext(ctx context.Context, email string) (*AccountPasswordResetToken, error) {
+	ctx = app.withClient(ctx)
 	passwordResetToken := &AccountPasswordResetToken{}
 
 	passwordResetPayload := make(map[string]string)
 	passwordResetPayload["email"] = email
 
-	err := client.post(buildAbsoluteURL(app.Href, "passwordResetTokens"), passwordResetPayload, passwordResetToken)
+	err := ClientFromContext(ctx).post(ctx, buildAbsoluteURL(app.Href, "passwordResetTokens"), passwordResetPayload, passwordResetToken)
 
 	if err != nil {
 		return nil, err
@@ -215,9 +316,15 @@ func (app *Application) SendPasswordResetEmail(email string) (*AccountPasswordRe
 
 //ValidatePasswordResetToken validates the given password reset token against Stormpath.
 func (app *Application) ValidatePasswordResetToken(token string) (*AccountPasswordResetToken, error) {
+	return app.ValidatePasswordResetTokenWithContext(app.clientContext(), token)
+}
+
+//ValidatePasswordResetTokenWithContext is the same as ValidatePasswordResetToken with the addition of a context.Context
+func (app *Application) ValidatePasswordResetTokenWithContext(ctx context.Context, token string) (*AccountPasswordResetToken, error) {
+	ctx = app.withClient(ctx)
 	passwordResetToken := &AccountPasswordResetToken{}
 
-	err := client.get(buildAbsoluteURL(app.Href, "passwordResetTokens", token), passwordResetToken)
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(app.Href, "passwordResetTokens", token), passwordResetToken)
 
 	if err != nil {
 		return nil, err
@@ -228,13 +335,19 @@ func (app *Application) ValidatePasswordResetToken(token string) (*AccountPasswo
 
 //ResetPassword resets a user password based on the reset password token.
 func (app *Application) ResetPassword(token string, newPassword string) (*Account, error) {
+	return app.ResetPasswordWithContext(app.clientContext(), token, newPassword)
+}
+
+//ResetPasswordWithContext is the same as ResetPassword with the addition of a context.Context
+func (app *Application) ResetPasswordWithContext(ctx context.Context, token string, newPassword string) (*Account, error) {
+	ctx = app.withClient(ctx)
 	accountRef := &accountRef{}
 	account := &Account{}
 
 	resetPasswordPayload := make(map[string]string)
 	resetPasswordPayload["password"] = newPassword
 
-	err := client.post(buildAbsoluteURL(app.Href, "passwordResetTokens", token), resetPasswordPayload, accountRef)
+	err := ClientFromContext(ctx).post(ctx, buildAbsoluteURL(app.Href, "passwordResetTokens", token), resetPasswordPayload, accountRef)
 
 	if err != nil {
 		return nil, err
@@ -247,25 +360,51 @@ func (app *Application) ResetPassword(token string, newPassword string) (*Accoun
 //CreateGroup creates a new application group.
 //Creating a group for an application automatically creates the proper account store mapping between the group and the application.
 func (app *Application) CreateGroup(group *Group) error {
-	return client.post(app.Groups.Href, group, group)
+	return app.CreateGroupWithContext(app.clientContext(), group)
+}
+
+//CreateGroupWithContext is the same as CreateGroup with the addition of a context.Context
+func (app *Application) CreateGroupWithContext(ctx context.Context, group *Group) error {
+	ctx = app.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, app.Groups.Href, group, group)
 }
 
 //GetGroups retrives the collection of all groups associated with the Application.
 //
 //The collection can be filtered and/or paginated by passing the desire GroupCriteria value.
 func (app *Application) GetGroups(criteria GroupCriteria) (*Groups, error) {
+	return app.GetGroupsWithContext(app.clientContext(), criteria)
+}
+
+//GetGroupsWithContext is the same as GetGroups with the addition of a context.Context
+func (app *Application) GetGroupsWithContext(ctx context.Context, criteria GroupCriteria) (*Groups, error) {
+	ctx = app.withClient(ctx)
 	groups := &Groups{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(app.Groups.Href, criteria.toQueryString()),
 		groups,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return groups, nil
+	return groups, err
+}
+
+//IterateGroups returns a GroupIterator over all the groups associated with the Application, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (app *Application) IterateGroups(criteria GroupCriteria) *GroupIterator {
+	return app.IterateGroupsWithContext(app.clientContext(), criteria)
+}
+
+//IterateGroupsWithContext is the same as IterateGroups with the addition of a context.Context
+func (app *Application) IterateGroupsWithContext(ctx context.Context, criteria GroupCriteria) *GroupIterator {
+	ctx = app.withClient(ctx)
+	return newGroupIterator(ctx, app.Groups.Href, criteria.baseCriteria)
 }
 
 //CreateIDSiteURL generates the IDSite URL for the application. This URL is used to initiate an IDSite workflow.
@@ -273,6 +412,17 @@ func (app *Application) GetGroups(criteria GroupCriteria) (*Groups, error) {
 //
 //For more information on Stormpath's IDSite feature see: http://docs.stormpath.com/rest/product-guide/latest/idsite.html
 func (app *Application) CreateIDSiteURL(options IDSiteOptions) (string, error) {
+	return app.CreateIDSiteURLWithContext(app.clientContext(), options)
+}
+
+//CreateIDSiteURLWithContext is the same as CreateIDSiteURL with the addition of a context.Context,
+//the JWT request is signed with the API key of the Client bound to the context.
+func (app *Application) CreateIDSiteURLWithContext(ctx context.Context, options IDSiteOptions) (string, error) {
+	ctx = app.withClient(ctx)
+	c := ClientFromContext(ctx)
+	if c == nil {
+		return "", ErrNoClient
+	}
 	nonce, _ := uuid.NewV4()
 
 	if options.Path == "" {
@@ -282,13 +432,13 @@ func (app *Application) CreateIDSiteURL(options IDSiteOptions) (string, error) {
 	claims := SSOTokenClaims{}
 	claims.Id = nonce.String()
 	claims.IssuedAt = time.Now().Unix()
-	claims.Issuer = client.ClientConfiguration.APIKeyID
+	claims.Issuer = c.ClientConfiguration.APIKeyID
 	claims.Subject = app.Href
 	claims.State = options.State
 	claims.Path = options.Path
 	claims.CallbackURI = options.CallbackURL
 
-	jwtString := JWT(claims, map[string]interface{}{})
+	jwtString := c.JWT(claims, map[string]interface{}{})
 
 	p, _ := url.Parse(app.Href)
 	ssoURL := p.Scheme + "://" + p.Host + "/sso"
@@ -305,6 +455,16 @@ func (app *Application) CreateIDSiteURL(options IDSiteOptions) (string, error) {
 //HandleCallback handles the URL from an ID Site or SAML callback it parses the JWT token
 //validates it and returns a CallbackResult, if the JWT was valid.
 func (app *Application) HandleCallback(URL string) (*CallbackResult, error) {
+	return app.HandleCallbackWithContext(app.clientContext(), URL)
+}
+
+//HandleCallbackWithContext is the same as HandleCallback with the addition of a context.Context
+func (app *Application) HandleCallbackWithContext(ctx context.Context, URL string) (*CallbackResult, error) {
+	ctx = app.withClient(ctx)
+	c := ClientFromContext(ctx)
+	if c == nil {
+		return nil, ErrNoClient
+	}
 	result := &CallbackResult{}
 
 	cbURL, err := url.Parse(URL)
@@ -316,9 +476,9 @@ func (app *Application) HandleCallback(URL string) (*CallbackResult, error) {
 
 	claims := &IDSiteAssertionTokenClaims{}
 
-	ParseJWT(jwtResponse, claims)
+	c.ParseJWT(jwtResponse, claims)
 
-	if claims.Audience != client.ClientConfiguration.APIKeyID {
+	if claims.Audience != c.ClientConfiguration.APIKeyID {
 		return nil, errors.New("ID Site invalid aud")
 	}
 
@@ -327,7 +487,7 @@ func (app *Application) HandleCallback(URL string) (*CallbackResult, error) {
 	}
 
 	if claims.Subject != "" {
-		account, err := GetAccount(claims.Subject, MakeAccountCriteria())
+		account, err := GetAccountWithContext(ctx, claims.Subject, MakeAccountCriteria())
 		if err != nil {
 			return nil, err
 		}
@@ -342,13 +502,19 @@ func (app *Application) HandleCallback(URL string) (*CallbackResult, error) {
 
 //GetOAuthToken creates a OAuth2 token response for an account, using the password grant type.
 func (app *Application) GetOAuthToken(username string, password string) (*OAuthResponse, error) {
+	return app.GetOAuthTokenWithContext(app.clientContext(), username, password)
+}
+
+//GetOAuthTokenWithContext is the same as GetOAuthToken with the addition of a context.Context
+func (app *Application) GetOAuthTokenWithContext(ctx context.Context, username string, password string) (*OAuthResponse, error) {
+	ctx = app.withClient(ctx)
 	values := url.Values{
 		"grant_type": {"password"},
 		"username":   {username},
 		"password":   {password},
 	}
 
-	return app.getOAuthTokenCommon(values)
+	return app.getOAuthTokenCommon(ctx, values)
 }
 
 //GetOAuthTokenStormpathGrantType creates an OAuth2 token response, for a given Stormpath JWT, using the stormpath_token grant type.
@@ -356,22 +522,34 @@ func (app *Application) GetOAuthToken(username string, password string) (*OAuthR
 //
 //For more information on the stormpath_token grant type see: http://docs.stormpath.com/rest/product-guide/latest/idsite.html#exchanging-the-id-site-jwt-for-an-oauth-token
 func (app *Application) GetOAuthTokenStormpathGrantType(token string) (*OAuthResponse, error) {
+	return app.GetOAuthTokenStormpathGrantTypeWithContext(app.clientContext(), token)
+}
+
+//GetOAuthTokenStormpathGrantTypeWithContext is the same as GetOAuthTokenStormpathGrantType with the addition of a context.Context
+func (app *Application) GetOAuthTokenStormpathGrantTypeWithContext(ctx context.Context, token string) (*OAuthResponse, error) {
+	ctx = app.withClient(ctx)
 	values := url.Values{
 		"grant_type": {"stormpath_token"},
 		"token":      {token},
 	}
 
-	return app.getOAuthTokenCommon(values)
+	return app.getOAuthTokenCommon(ctx, values)
 }
 
 func (app *Application) GetOAuthTokenClientCredentialsGrantType(apiKeyID, apiKeySecret string) (*OAuthResponse, error) {
+	return app.GetOAuthTokenClientCredentialsGrantTypeWithContext(app.clientContext(), apiKeyID, apiKeySecret)
+}
+
+//GetOAuthTokenClientCredentialsGrantTypeWithContext is the same as GetOAuthTokenClientCredentialsGrantType with the addition of a context.Context
+func (app *Application) GetOAuthTokenClientCredentialsGrantTypeWithContext(ctx context.Context, apiKeyID, apiKeySecret string) (*OAuthResponse, error) {
+	ctx = app.withClient(ctx)
 	values := url.Values{
 		"grant_type":   {"client_credentials"},
 		"apiKeyId":     {apiKeyID},
 		"apiKeySecret": {apiKeySecret},
 	}
 
-	return app.getOAuthTokenCommon(values)
+	return app.getOAuthTokenCommon(ctx, values)
 }
 
 //GetOAuthTokenSocialGrantType creates a OAuth2 token response, for an account using a socical provider via the stormpath_social grant type.
@@ -381,6 +559,12 @@ func (app *Application) GetOAuthTokenClientCredentialsGrantType(apiKeyID, apiKey
 //
 //For more information on the stormpath_social grant type see: http://docs.stormpath.com/rest/product-guide/latest/auth_n.html#social
 func (app *Application) GetOAuthTokenSocialGrantType(providerID, accessToken, code string) (*OAuthResponse, error) {
+	return app.GetOAuthTokenSocialGrantTypeWithContext(app.clientContext(), providerID, accessToken, code)
+}
+
+//GetOAuthTokenSocialGrantTypeWithContext is the same as GetOAuthTokenSocialGrantType with the addition of a context.Context
+func (app *Application) GetOAuthTokenSocialGrantTypeWithContext(ctx context.Context, providerID, accessToken, code string) (*OAuthResponse, error) {
+	ctx = app.withClient(ctx)
 	values := url.Values{
 		"grant_type": {"stormpath_social"},
 		"providerId": {providerID},
@@ -402,23 +586,30 @@ func (app *Application) GetOAuthTokenSocialGrantType(providerID, accessToken, co
 		values.Add("code", code)
 	}
 
-	return app.getOAuthTokenCommon(values)
+	return app.getOAuthTokenCommon(ctx, values)
 }
 
 //RefreshOAuthToken creates an OAuth2 response using the refresh_token grant type.
 func (app *Application) RefreshOAuthToken(refreshToken string) (*OAuthResponse, error) {
+	return app.RefreshOAuthTokenWithContext(app.clientContext(), refreshToken)
+}
+
+//RefreshOAuthTokenWithContext is the same as RefreshOAuthToken with the addition of a context.Context
+func (app *Application) RefreshOAuthTokenWithContext(ctx context.Context, refreshToken string) (*OAuthResponse, error) {
+	ctx = app.withClient(ctx)
 	values := url.Values{
 		"grant_type":    {"refresh_token"},
 		"refresh_token": {refreshToken},
 	}
 
-	return app.getOAuthTokenCommon(values)
+	return app.getOAuthTokenCommon(ctx, values)
 }
 
-func (app *Application) getOAuthTokenCommon(values url.Values) (*OAuthResponse, error) {
+func (app *Application) getOAuthTokenCommon(ctx context.Context, values url.Values) (*OAuthResponse, error) {
 	response := &OAuthResponse{}
 
-	err := client.postURLEncodedForm(
+	err := ClientFromContext(ctx).postURLEncodedForm(
+		ctx,
 		buildAbsoluteURL(app.Href, "oauth/token"),
 		values.Encode(),
 		response,
@@ -433,9 +624,16 @@ func (app *Application) getOAuthTokenCommon(values url.Values) (*OAuthResponse,
 
 //ValidateToken validates either an OAuth2 access or refresh token against Stormpath.
 func (app *Application) ValidateToken(token string) (*OAuthToken, error) {
+	return app.ValidateTokenWithContext(app.clientContext(), token)
+}
+
+//ValidateTokenWithContext is the same as ValidateToken with the addition of a context.Context
+func (app *Application) ValidateTokenWithContext(ctx context.Context, token string) (*OAuthToken, error) {
+	ctx = app.withClient(ctx)
 	response := &OAuthToken{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(app.Href, "authTokens", token),
 		response,
 	)
@@ -451,9 +649,15 @@ func (app *Application) ValidateToken(token string) (*OAuthToken, error) {
 //
 //It can optionally have its attributes expanded depending on the APIKeyCriteria value.
 func (app *Application) GetAPIKey(apiKeyID string, criteria APIKeyCriteria) (*APIKey, error) {
+	return app.GetAPIKeyWithContext(app.clientContext(), apiKeyID, criteria)
+}
+
+//GetAPIKeyWithContext is the same as GetAPIKey with the addition of a context.Context
+func (app *Application) GetAPIKeyWithContext(ctx context.Context, apiKeyID string, criteria APIKeyCriteria) (*APIKey, error) {
+	ctx = app.withClient(ctx)
 	apiKeys := &APIKeys{}
 
-	err := client.get(buildAbsoluteURL(app.APIKeys.Href, criteria.IDEq(apiKeyID).toQueryString()), apiKeys)
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(app.APIKeys.Href, criteria.IDEq(apiKeyID).toQueryString()), apiKeys)
 	if err != nil {
 		return nil, err
 	}
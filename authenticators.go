@@ -1,6 +1,7 @@
 package stormpath
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"time"
@@ -87,7 +88,12 @@ func NewBasicAuthenticator(application *Application) BasicAuthenticator {
 
 //Authenticate authenticates the given account APIKey and APISecret
 func (a BasicAuthenticator) Authenticate(accountAPIKey, accountAPISecret string) (*AuthenticationResult, error) {
-	apiKey, err := a.Application.GetAPIKey(accountAPIKey, MakeAPIKeysCriteria().WithAccount())
+	return a.AuthenticateWithContext(applicationContext(a.Application), accountAPIKey, accountAPISecret)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a BasicAuthenticator) AuthenticateWithContext(ctx context.Context, accountAPIKey, accountAPISecret string) (*AuthenticationResult, error) {
+	apiKey, err := a.Application.GetAPIKeyWithContext(ctx, accountAPIKey, MakeAPIKeysCriteria().WithAccount())
 	if err != nil {
 		return nil, err
 	}
@@ -121,7 +127,11 @@ func NewOAuthRequestAuthenticator(application *Application) OAuthRequestAuthenti
 // - password
 // - client_credentials
 // - refresh_token
+//
+//The request context.Context is used for all the calls done to Stormpath.
 func (a OAuthRequestAuthenticator) Authenticate(r *http.Request) (*OAuthAccessTokenResult, error) {
+	ctx := r.Context()
+
 	err := r.ParseForm()
 	if err != nil {
 		return nil, err
@@ -131,7 +141,7 @@ func (a OAuthRequestAuthenticator) Authenticate(r *http.Request) (*OAuthAccessTo
 
 	switch grantType {
 	case "password":
-		authResult, err := NewOAuthPasswordAuthenticator(a.Application).Authenticate(r.Form.Get("username"), r.Form.Get("password"))
+		authResult, err := NewOAuthPasswordAuthenticator(a.Application).AuthenticateWithContext(ctx, r.Form.Get("username"), r.Form.Get("password"))
 		if err != nil {
 			return nil, err
 		}
@@ -141,20 +151,20 @@ func (a OAuthRequestAuthenticator) Authenticate(r *http.Request) (*OAuthAccessTo
 		if !ok {
 			return nil, fmt.Errorf("invalid_client")
 		}
-		oauthResponse, err := NewOAuthClientCredentialsAuthenticator(a.Application).Authenticate(accountAPIKeyID, accountAPIKeyScret, r.Form.Get("scope"))
+		oauthResponse, err := NewOAuthClientCredentialsAuthenticator(a.Application).AuthenticateWithContext(ctx, accountAPIKeyID, accountAPIKeyScret, r.Form.Get("scope"))
 		if err != nil {
 			return nil, err
 		}
 		result := OAuthAccessTokenResult(*oauthResponse)
 		return &result, nil
 	case "refresh_token":
-		authResult, err := NewOAuthRefreshTokenAuthenticator(a.Application).Authenticate(r.Form.Get("refresh_token"))
+		authResult, err := NewOAuthRefreshTokenAuthenticator(a.Application).AuthenticateWithContext(ctx, r.Form.Get("refresh_token"))
 		if err != nil {
 			return nil, err
 		}
 		return authResult, nil
 	case "stormpath_social":
-		oauthResponse, err := a.Application.GetOAuthTokenSocialGrantType(r.Form.Get("providerId"), r.Form.Get("accessToken"), "")
+		oauthResponse, err := a.Application.GetOAuthTokenSocialGrantTypeWithContext(ctx, r.Form.Get("providerId"), r.Form.Get("accessToken"), "")
 		if err != nil {
 			return nil, err
 		}
@@ -173,13 +183,18 @@ func NewOAuthClientCredentialsAuthenticator(application *Application) OAuthClien
 }
 
 func (a OAuthClientCredentialsAuthenticator) Authenticate(accountAPIKeyID, accountAPIKeySecret, scope string) (*OAuthClientCredentialsAuthenticationResult, error) {
+	return a.AuthenticateWithContext(applicationContext(a.Application), accountAPIKeyID, accountAPIKeySecret, scope)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a OAuthClientCredentialsAuthenticator) AuthenticateWithContext(ctx context.Context, accountAPIKeyID, accountAPIKeySecret, scope string) (*OAuthClientCredentialsAuthenticationResult, error) {
 	if a.ScopeFactory != nil {
 		if !a.ScopeFactory(scope) {
 			return nil, fmt.Errorf("invalid_scope")
 		}
 	}
 
-	oAuthResponse, err := a.Application.GetOAuthTokenClientCredentialsGrantType(accountAPIKeyID, accountAPIKeySecret)
+	oAuthResponse, err := a.Application.GetOAuthTokenClientCredentialsGrantTypeWithContext(ctx, accountAPIKeyID, accountAPIKeySecret)
 	if err != nil {
 		return nil, fmt.Errorf("invalid_client")
 	}
@@ -194,7 +209,12 @@ func NewOAuthPasswordAuthenticator(application *Application) OAuthPasswordAuthen
 }
 
 func (a OAuthPasswordAuthenticator) Authenticate(username, password string) (*OAuthAccessTokenResult, error) {
-	oauthResponse, err := a.Application.GetOAuthToken(username, password)
+	return a.AuthenticateWithContext(applicationContext(a.Application), username, password)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a OAuthPasswordAuthenticator) AuthenticateWithContext(ctx context.Context, username, password string) (*OAuthAccessTokenResult, error) {
+	oauthResponse, err := a.Application.GetOAuthTokenWithContext(ctx, username, password)
 	if err != nil {
 		return nil, err
 	}
@@ -208,7 +228,12 @@ func NewOAuthRefreshTokenAuthenticator(application *Application) OAuthRefreshTok
 }
 
 func (a OAuthRefreshTokenAuthenticator) Authenticate(refreshToken string) (*OAuthAccessTokenResult, error) {
-	oauthResponse, err := a.Application.RefreshOAuthToken(refreshToken)
+	return a.AuthenticateWithContext(applicationContext(a.Application), refreshToken)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a OAuthRefreshTokenAuthenticator) AuthenticateWithContext(ctx context.Context, refreshToken string) (*OAuthAccessTokenResult, error) {
+	oauthResponse, err := a.Application.RefreshOAuthTokenWithContext(ctx, refreshToken)
 	if err != nil {
 		return nil, err
 	}
@@ -222,7 +247,12 @@ func NewOAuthStormpathTokenAuthenticator(application *Application) OAuthStormpat
 }
 
 func (a OAuthStormpathTokenAuthenticator) Authenticate(stormpathJWT string) (*OAuthAccessTokenResult, error) {
-	oauthResponse, err := a.Application.GetOAuthTokenStormpathGrantType(stormpathJWT)
+	return a.AuthenticateWithContext(applicationContext(a.Application), stormpathJWT)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a OAuthStormpathTokenAuthenticator) AuthenticateWithContext(ctx context.Context, stormpathJWT string) (*OAuthAccessTokenResult, error) {
+	oauthResponse, err := a.Application.GetOAuthTokenStormpathGrantTypeWithContext(ctx, stormpathJWT)
 	if err != nil {
 		return nil, err
 	}
@@ -236,7 +266,12 @@ func NewStormpathAssertionAuthenticator(application *Application) StormpathAsser
 }
 
 func (a StormpathAssertionAuthenticator) Authenticate(stormpathJWT string) (*StormpathAssertionAuthenticationResult, error) {
-	callbackResponse, err := a.Application.HandleCallback("http://fake?jwtResponse=" + stormpathJWT)
+	return a.AuthenticateWithContext(applicationContext(a.Application), stormpathJWT)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a StormpathAssertionAuthenticator) AuthenticateWithContext(ctx context.Context, stormpathJWT string) (*StormpathAssertionAuthenticationResult, error) {
+	callbackResponse, err := a.Application.HandleCallbackWithContext(ctx, "http://fake?jwtResponse="+stormpathJWT)
 	if err != nil {
 		return nil, err
 	}
@@ -250,7 +285,12 @@ func NewOAuthBearerAuthenticator(application *Application) OAuthBearerAuthentica
 }
 
 func (a OAuthBearerAuthenticator) Authenticate(accessTokenJWT string) (*AuthenticationResult, error) {
-	oauthToken, err := a.Application.ValidateToken(accessTokenJWT)
+	return a.AuthenticateWithContext(applicationContext(a.Application), accessTokenJWT)
+}
+
+//AuthenticateWithContext is the same as Authenticate with the addition of a context.Context
+func (a OAuthBearerAuthenticator) AuthenticateWithContext(ctx context.Context, accessTokenJWT string) (*AuthenticationResult, error) {
+	oauthToken, err := a.Application.ValidateTokenWithContext(ctx, accessTokenJWT)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,279 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+//DefaultBulkWorkers is the number of accounts registered in parallel by the bulk operations when no workers are given
+const DefaultBulkWorkers = 4
+
+//ErrMissingBulkAccount is the error of the bulk items without an Account
+var ErrMissingBulkAccount = errors.New("bulk item without an account")
+
+//BulkAccount is an account to provision with optional custom data and group assignments
+type BulkAccount struct {
+	Account    *Account
+	CustomData CustomData
+	Groups     []*Group
+}
+
+//BulkOptions configures a bulk provisioning job
+type BulkOptions struct {
+	//Workers is the number of accounts provisioned in parallel, defaults to DefaultBulkWorkers
+	Workers int
+	//RatePerSecond limits the number of API calls per second across all workers, 0 means no limit.
+	//Every account registration, lookup and group assignment counts as one call
+	RatePerSecond int
+	//Resume is the report of a previous run of the same job, its succeeded items are skipped
+	//and the accounts already created only get their pending group assignments.
+	//The failed accounts without an href are looked up by email before being registered again,
+	//since a failed registration could have been created anyway, e.g. when the response timed out
+	Resume *BulkReport
+}
+
+//BulkResult is the outcome of provisioning a single BulkAccount
+type BulkResult struct {
+	//Index is the position of the item in the job input
+	Index int    `json:"index"`
+	Email string `json:"email,omitempty"`
+	//Href is the href of the created account, it is set even if a group assignment failed afterwards
+	Href  string `json:"href,omitempty"`
+	Error *Error `json:"error,omitempty"`
+}
+
+//Succeeded returns true if the account was created and added to all its groups
+func (r BulkResult) Succeeded() bool {
+	return r.Error == nil
+}
+
+//BulkReport holds the per item results of a bulk provisioning job sorted by index,
+//it can be stored as JSON and given back as BulkOptions.Resume to resume a job
+type BulkReport struct {
+	Results []BulkResult `json:"results"`
+}
+
+//Failed returns the results of the items that failed
+func (r *BulkReport) Failed() []BulkResult {
+	failed := []BulkResult{}
+	for _, result := range r.Results {
+		if !result.Succeeded() {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+//Succeeded returns the number of items provisioned successfully
+func (r *BulkReport) Succeeded() int {
+	return len(r.Results) - len(r.Failed())
+}
+
+//result returns the result with the given index if any
+func (r *BulkReport) result(index int) (BulkResult, bool) {
+	if r != nil {
+		i := sort.Search(len(r.Results), func(i int) bool { return r.Results[i].Index >= index })
+		if i < len(r.Results) && r.Results[i].Index == index {
+			return r.Results[i], true
+		}
+	}
+	return BulkResult{}, false
+}
+
+type bulkResultsByIndex []BulkResult
+
+func (r bulkResultsByIndex) Len() int           { return len(r) }
+func (r bulkResultsByIndex) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+func (r bulkResultsByIndex) Less(i, j int) bool { return r[i].Index < r[j].Index }
+
+type bulkItem struct {
+	index int
+	BulkAccount
+}
+
+//bulkJob holds what the workers of a bulk provisioning job share
+type bulkJob struct {
+	//accountsHref is the accounts collection of the account store the accounts are provisioned into
+	accountsHref string
+	register     func(context.Context, *Account) error
+	throttle     <-chan time.Time
+	resume       bool
+}
+
+//wait blocks until the job rate limit allows the next API call
+func (job bulkJob) wait(ctx context.Context) error {
+	if job.throttle == nil {
+		return ctx.Err()
+	}
+
+	select {
+	case <-job.throttle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+//bulkRegisterAccounts provisions the given accounts into the account store with the given accounts collection href
+//with a pool of workers calling register for each one,
+//it stops reading items when ctx is done and returns the report of the items processed so far with ctx error
+func bulkRegisterAccounts(ctx context.Context, accountsHref string, register func(context.Context, *Account) error, accounts <-chan BulkAccount, options BulkOptions) (*BulkReport, error) {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = DefaultBulkWorkers
+	}
+
+	job := bulkJob{accountsHref: accountsHref, register: register, resume: options.Resume != nil}
+	if options.RatePerSecond > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(options.RatePerSecond))
+		defer ticker.Stop()
+		job.throttle = ticker.C
+	}
+
+	report := &BulkReport{}
+	if options.Resume != nil {
+		previous := append([]BulkResult{}, options.Resume.Results...)
+		sort.Sort(bulkResultsByIndex(previous))
+		options.Resume = &BulkReport{Results: previous}
+	}
+
+	items := make(chan bulkItem)
+	mutex := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range items {
+				previous, _ := options.Resume.result(item.index)
+				result := job.provision(ctx, item, previous)
+
+				mutex.Lock()
+				report.Results = append(report.Results, result)
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	var err error
+	index := 0
+read:
+	for {
+		select {
+		case account, ok := <-accounts:
+			if !ok {
+				break read
+			}
+			if previous, ok := options.Resume.result(index); ok && previous.Succeeded() {
+				mutex.Lock()
+				report.Results = append(report.Results, previous)
+				mutex.Unlock()
+			} else {
+				select {
+				case items <- bulkItem{index, account}:
+				case <-ctx.Done():
+					err = ctx.Err()
+					break read
+				}
+			}
+			index++
+		case <-ctx.Done():
+			err = ctx.Err()
+			break read
+		}
+	}
+	close(items)
+	wg.Wait()
+
+	sort.Sort(bulkResultsByIndex(report.Results))
+	return report, err
+}
+
+//provision registers a single account and adds it to its groups, if the previous result has an href
+//the account was already created and only the group assignments are done
+func (job bulkJob) provision(ctx context.Context, item bulkItem, previous BulkResult) BulkResult {
+	account := item.Account
+	if account == nil {
+		return BulkResult{Index: item.index, Href: previous.Href}.failed(ErrMissingBulkAccount)
+	}
+	result := BulkResult{Index: item.index, Email: account.Email, Href: previous.Href}
+
+	if result.Href == "" && job.resume && account.Email != "" {
+		href, err := job.lookup(ctx, account.Email)
+		if err != nil {
+			return result.failed(err)
+		}
+		result.Href = href
+	}
+
+	if result.Href == "" {
+		if err := job.wait(ctx); err != nil {
+			return result.failed(err)
+		}
+
+		if item.CustomData != nil {
+			customData := item.CustomData
+			account.CustomData = &customData
+		}
+		if err := job.register(ctx, account); err != nil {
+			return result.failed(err)
+		}
+		result.Href = account.Href
+	} else {
+		account.Href = result.Href
+	}
+
+	for _, group := range item.Groups {
+		if err := job.wait(ctx); err != nil {
+			return result.failed(err)
+		}
+
+		_, err := account.AddToGroupWithContext(ctx, group)
+		//A conflict means the account is already a member of the group, e.g. when resuming a job
+		if err != nil && !errors.Is(err, ErrConflict) {
+			return result.failed(err)
+		}
+	}
+
+	return result
+}
+
+//lookup returns the href of the account with the given email in the job account store, or "" if there is none
+func (job bulkJob) lookup(ctx context.Context, email string) (string, error) {
+	if err := job.wait(ctx); err != nil {
+		return "", err
+	}
+
+	accounts := &Accounts{}
+	criteria := MakeAccountsCriteria().EmailEq(email).Limit(1)
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(job.accountsHref, criteria.toQueryString()), accounts)
+	if err != nil || len(accounts.Items) == 0 {
+		return "", err
+	}
+
+	return accounts.Items[0].Href, nil
+}
+
+//failed sets the result error, errors other than a Stormpath Error are wrapped into one keeping their message
+func (r BulkResult) failed(err error) BulkResult {
+	spError := Error{}
+	if !errors.As(err, &spError) {
+		spError = Error{Message: err.Error()}
+	}
+	r.Error = &spError
+	return r
+}
+
+//bulkAccountsChannel streams the given accounts over a channel
+func bulkAccountsChannel(accounts []BulkAccount) <-chan BulkAccount {
+	c := make(chan BulkAccount, len(accounts))
+	for _, account := range accounts {
+		c <- account
+	}
+	close(c)
+	return c
+}
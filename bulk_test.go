@@ -0,0 +1,264 @@
+package stormpath
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bulkTestServer struct {
+	*testServer
+	mutex       sync.Mutex
+	registered  []string
+	customData  map[string]interface{}
+	memberships []string
+	lookups     []string
+	failGroups  bool
+	//failCreated registers the accounts but answers with an error, as if the response was lost
+	failCreated bool
+}
+
+func newBulkTestServer() *bulkTestServer {
+	s := &bulkTestServer{customData: map[string]interface{}{}}
+	s.testServer = newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		payload := map[string]interface{}{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		time.Sleep(2 * time.Millisecond)
+
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+		case path == "/v1/directories/dir/accounts" && r.Method == http.MethodGet:
+			email := r.URL.Query().Get("email")
+			s.lookups = append(s.lookups, email)
+			items := []map[string]interface{}{}
+			for _, registered := range s.registered {
+				if registered == email {
+					items = append(items, map[string]interface{}{"href": s.URL + "/v1/accounts/" + email, "email": email})
+				}
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": s.URL + path, "size": len(items), "items": items})
+		case path == "/v1/directories/dir/accounts":
+			email := payload["email"].(string)
+			if strings.HasPrefix(email, "invalid") {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"status":400,"code":2002,"message":"Account email address is in an invalid format."}`))
+				return
+			}
+			s.registered = append(s.registered, email)
+			s.customData[email] = payload["customData"]
+			if s.failCreated {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"status":503,"message":"unavailable"}`))
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": s.URL + "/v1/accounts/" + email, "email": email})
+		case path == "/v1/groupMemberships":
+			if s.failGroups {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				w.Write([]byte(`{"status":503,"message":"unavailable"}`))
+				return
+			}
+			account := payload["account"].(map[string]interface{})["href"].(string)
+			group := payload["group"].(map[string]interface{})["href"].(string)
+			for _, membership := range s.memberships {
+				if membership == account+" "+group {
+					w.WriteHeader(http.StatusConflict)
+					w.Write([]byte(`{"status":409,"code":2001,"message":"Membership already exists."}`))
+					return
+				}
+			}
+			s.memberships = append(s.memberships, account+" "+group)
+			json.NewEncoder(w).Encode(map[string]interface{}{"href": s.URL + "/v1/groupMemberships/1"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return s
+}
+
+func newBulkTestDirectory(server *bulkTestServer) (context.Context, *Directory) {
+	directory := &Directory{}
+	directory.Href = server.URL + "/v1/directories/dir"
+	directory.Accounts = &Accounts{}
+	directory.Accounts.Href = directory.Href + "/accounts"
+
+	ctx, _ := newTestContext(server.configuration(), nil)
+	return ctx, directory
+}
+
+func newBulkTestAccounts(emails ...string) []BulkAccount {
+	group := &Group{}
+	group.Href = "https://api.stormpath.com/v1/groups/admins"
+
+	accounts := []BulkAccount{}
+	for _, email := range emails {
+		accounts = append(accounts, BulkAccount{
+			Account:    NewAccount(email, "Passw0rd!", email, "John", "Doe"),
+			CustomData: CustomData{"source": "import"},
+			Groups:     []*Group{group},
+		})
+	}
+	return accounts
+}
+
+func TestBulkRegisterAccounts(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+
+	report, err := directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "invalid", "c@test.org", "d@test.org", "e@test.org", "f@test.org"), BulkOptions{Workers: 3})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 6)
+	assert.Equal(t, 5, report.Succeeded())
+	for i, result := range report.Results {
+		assert.Equal(t, i, result.Index)
+	}
+
+	failed := report.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, 1, failed[0].Index)
+	assert.Equal(t, 2002, failed[0].Error.Code)
+	assert.Empty(t, failed[0].Href)
+
+	assert.Equal(t, server.URL+"/v1/accounts/a@test.org", report.Results[0].Href)
+	assert.Equal(t, map[string]interface{}{"source": "import"}, server.customData["a@test.org"])
+	assert.Len(t, server.memberships, 5)
+	assert.True(t, server.maxInFlight() <= 3)
+}
+
+func TestBulkRegisterAccountsWithoutAccount(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+	valid := newBulkTestAccounts("a@test.org", "b@test.org")
+	accounts := []BulkAccount{valid[0], {CustomData: CustomData{"source": "import"}}, valid[1]}
+
+	report, err := directory.BulkRegisterAccountsWithContext(ctx, accounts, BulkOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Equal(t, 2, report.Succeeded())
+
+	failed := report.Failed()
+	assert.Len(t, failed, 1)
+	assert.Equal(t, 1, failed[0].Index)
+	assert.Equal(t, ErrMissingBulkAccount.Error(), failed[0].Error.Message)
+}
+
+func TestBulkRegisterAccountsRateLimit(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+
+	start := time.Now()
+	report, err := directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org", "c@test.org", "d@test.org"), BulkOptions{Workers: 4, RatePerSecond: 50})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Succeeded())
+	//Every account is registered then added to its group, both calls are rate limited
+	assert.True(t, time.Since(start) >= 8*20*time.Millisecond)
+}
+
+func TestBulkRegisterAccountsResume(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+
+	server.failGroups = true
+	report, err := directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org"), BulkOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Failed(), 2)
+	assert.Equal(t, 503, report.Results[0].Error.Status)
+	assert.NotEmpty(t, report.Results[0].Href)
+
+	data, _ := json.Marshal(report)
+	resume := &BulkReport{}
+	assert.NoError(t, json.Unmarshal(data, resume))
+
+	server.mutex.Lock()
+	server.failGroups = false
+	server.mutex.Unlock()
+
+	report, err = directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org", "c@test.org"), BulkOptions{Resume: resume})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Results, 3)
+	assert.Empty(t, report.Failed())
+	assert.ElementsMatch(t, []string{"a@test.org", "b@test.org", "c@test.org"}, server.registered)
+	assert.Len(t, server.memberships, 3)
+
+	report, err = directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org", "c@test.org"), BulkOptions{Resume: report})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, report.Succeeded())
+	assert.Len(t, server.registered, 3)
+}
+
+func TestBulkRegisterAccountsResumeLooksUpCreatedAccounts(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+
+	server.failCreated = true
+	report, err := directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org"), BulkOptions{})
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Failed(), 2)
+	assert.Empty(t, report.Results[0].Href)
+	assert.Empty(t, server.lookups)
+
+	server.mutex.Lock()
+	server.failCreated = false
+	server.mutex.Unlock()
+
+	report, err = directory.BulkRegisterAccountsWithContext(ctx, newBulkTestAccounts("a@test.org", "b@test.org", "c@test.org"), BulkOptions{Resume: report})
+
+	assert.NoError(t, err)
+	assert.Empty(t, report.Failed())
+	assert.Equal(t, server.URL+"/v1/accounts/a@test.org", report.Results[0].Href)
+	assert.ElementsMatch(t, []string{"a@test.org", "b@test.org", "c@test.org"}, server.lookups)
+	assert.ElementsMatch(t, []string{"a@test.org", "b@test.org", "c@test.org"}, server.registered)
+	assert.Len(t, server.memberships, 3)
+}
+
+func TestBulkRegisterAccountStreamCancellation(t *testing.T) {
+	t.Parallel()
+
+	server := newBulkTestServer()
+	defer server.Close()
+	ctx, directory := newBulkTestDirectory(server)
+	ctx, cancel := context.WithCancel(ctx)
+
+	accounts := make(chan BulkAccount)
+	go func() {
+		for _, account := range newBulkTestAccounts("a@test.org", "b@test.org") {
+			accounts <- account
+		}
+		cancel()
+	}()
+
+	report, err := directory.BulkRegisterAccountStreamWithContext(ctx, accounts, BulkOptions{Workers: 1})
+
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, len(report.Results) <= 2)
+}
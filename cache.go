@@ -1,5 +1,7 @@
 package stormpath
 
+import "time"
+
 //Cacheable determines if the implementor should be cached or not
 type Cacheable interface {
 	IsCacheable() bool
@@ -12,3 +14,35 @@ type Cache interface {
 	Get(key string) []byte
 	Del(key string)
 }
+
+//AtomicGetter is implemented by the caches able to get an entry only if it is present in a single operation,
+//avoiding the race between Exists and Get when an entry expires or is deleted in between
+type AtomicGetter interface {
+	GetIfPresent(key string) ([]byte, bool)
+}
+
+//TTLSetter is implemented by the caches able to store an entry with its own time to live, the client stores every
+//response with the TTL of its cache region plus the stale window so a shared cache expires it like the local cache
+type TTLSetter interface {
+	SetWithTTL(key string, data []byte, ttl time.Duration)
+}
+
+//setWithTTL stores the data with the given TTL when the cache implements TTLSetter, with the cache own TTL otherwise
+func setWithTTL(cache Cache, key string, data []byte, ttl time.Duration) {
+	if setter, ok := cache.(TTLSetter); ok && ttl > 0 {
+		setter.SetWithTTL(key, data, ttl)
+		return
+	}
+	cache.Set(key, data)
+}
+
+//getIfPresent gets the cached data of the key and whether it is cached, atomically when the cache implements AtomicGetter
+func getIfPresent(cache Cache, key string) ([]byte, bool) {
+	if getter, ok := cache.(AtomicGetter); ok {
+		return getter.GetIfPresent(key)
+	}
+	if cache.Exists(key) {
+		return cache.Get(key), true
+	}
+	return nil, false
+}
@@ -0,0 +1,168 @@
+package stormpath
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Defaults of the remote cache connection pools
+const (
+	DefaultCachePoolSize = 10
+	DefaultCacheTimeout  = time.Second
+)
+
+var (
+	errCachePoolClosed    = errors.New("cache connection pool closed")
+	errCachePoolExhausted = errors.New("no cache connection available")
+)
+
+//cacheConn is a pooled connection to a remote cache server with buffered I/O
+type cacheConn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+//connPool opens at most size connections to a remote cache server and keeps them open between requests,
+//a request waits up to timeout for a connection when all of them are in use and every connection taken
+//from the pool gets a deadline of timeout for the request it is used for
+type connPool struct {
+	mutex   sync.Mutex
+	idle    chan *cacheConn
+	open    chan struct{}
+	timeout time.Duration
+	setup   func(conn *cacheConn) error
+	address string
+	closed  bool
+}
+
+//newConnPool creates a connPool for the server address, setup is called with every new connection
+//before its first use, e.g. to authenticate it
+func newConnPool(address string, size int, timeout time.Duration, setup func(conn *cacheConn) error) *connPool {
+	if size <= 0 {
+		size = DefaultCachePoolSize
+	}
+	if timeout <= 0 {
+		timeout = DefaultCacheTimeout
+	}
+	return &connPool{
+		idle:    make(chan *cacheConn, size),
+		open:    make(chan struct{}, size),
+		timeout: timeout,
+		setup:   setup,
+		address: address,
+	}
+}
+
+//get returns an idle connection, or a new one while the pool has less than size connections open,
+//else it waits for a connection to be returned to the pool
+func (pool *connPool) get() (*cacheConn, error) {
+	var conn *cacheConn
+
+	select {
+	case c, ok := <-pool.idle:
+		if !ok {
+			return nil, errCachePoolClosed
+		}
+		conn = c
+	default:
+		timer := time.NewTimer(pool.timeout)
+		defer timer.Stop()
+
+		select {
+		case c, ok := <-pool.idle:
+			if !ok {
+				return nil, errCachePoolClosed
+			}
+			conn = c
+		case pool.open <- struct{}{}:
+			c, err := pool.dial()
+			if err != nil {
+				<-pool.open
+				return nil, err
+			}
+			conn = c
+		case <-timer.C:
+			return nil, errCachePoolExhausted
+		}
+	}
+
+	conn.SetDeadline(time.Now().Add(pool.timeout))
+	return conn, nil
+}
+
+//dial opens a new connection to the server and sets it up
+func (pool *connPool) dial() (*cacheConn, error) {
+	c, err := net.DialTimeout("tcp", pool.address, pool.timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &cacheConn{Conn: c, reader: bufio.NewReader(c), writer: bufio.NewWriter(c)}
+	if pool.setup != nil {
+		conn.SetDeadline(time.Now().Add(pool.timeout))
+		if err := pool.setup(conn); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+//put returns the connection to the pool, connections that failed with err are closed
+//since their protocol state is unknown, freeing their place in the pool
+func (pool *connPool) put(conn *cacheConn, err error) {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if err != nil || pool.closed {
+		pool.discard(conn)
+		return
+	}
+	pool.idle <- conn
+}
+
+//discard closes a connection taken from the pool and frees its place
+func (pool *connPool) discard(conn *cacheConn) {
+	conn.Close()
+	<-pool.open
+}
+
+//do runs fn with a pooled connection
+func (pool *connPool) do(fn func(conn *cacheConn) error) error {
+	conn, err := pool.get()
+	if err != nil {
+		return err
+	}
+
+	err = fn(conn)
+	pool.put(conn, err)
+	return err
+}
+
+//close closes the idle connections, the connections in use are closed when they are returned
+func (pool *connPool) close() error {
+	pool.mutex.Lock()
+	defer pool.mutex.Unlock()
+
+	if !pool.closed {
+		pool.closed = true
+		close(pool.idle)
+		for conn := range pool.idle {
+			pool.discard(conn)
+		}
+	}
+	return nil
+}
+
+//readCacheLine reads a CRLF terminated protocol line without its terminator
+func readCacheLine(conn *cacheConn) (string, error) {
+	line, err := conn.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
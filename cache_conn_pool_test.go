@@ -0,0 +1,71 @@
+package stormpath
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newConnPoolTestListener() net.Listener {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				io.Copy(ioutil.Discard, conn)
+				conn.Close()
+			}()
+		}
+	}()
+	return listener
+}
+
+func TestConnPoolLimitsOpenConnections(t *testing.T) {
+	t.Parallel()
+
+	listener := newConnPoolTestListener()
+	defer listener.Close()
+	pool := newConnPool(listener.Addr().String(), 1, 50*time.Millisecond, nil)
+	defer pool.close()
+
+	conn, err := pool.get()
+	assert.NoError(t, err)
+
+	_, err = pool.get()
+	assert.Equal(t, errCachePoolExhausted, err)
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		pool.put(conn, nil)
+	}()
+
+	reused, err := pool.get()
+	assert.NoError(t, err)
+	assert.Equal(t, conn, reused)
+	pool.put(reused, nil)
+}
+
+func TestConnPoolFreesFailedConnections(t *testing.T) {
+	t.Parallel()
+
+	listener := newConnPoolTestListener()
+	defer listener.Close()
+	pool := newConnPool(listener.Addr().String(), 1, 50*time.Millisecond, nil)
+	defer pool.close()
+
+	conn, err := pool.get()
+	assert.NoError(t, err)
+	pool.put(conn, errCachePoolClosed)
+
+	other, err := pool.get()
+	assert.NoError(t, err)
+	assert.NotEqual(t, conn, other)
+	pool.put(other, nil)
+}
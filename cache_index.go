@@ -0,0 +1,384 @@
+package stormpath
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Resources linking two other resources by kind, creating or deleting one of them changes the linked resources
+//so their cached entries are evicted with it. Collections of these resources are named by the same kinds.
+var relationshipLinks = map[string][]string{
+	"groupMemberships":                 {"account", "group"},
+	"accountMemberships":               {"account", "group"},
+	"accountStoreMappings":             {"application", "accountStore"},
+	"organizationAccountStoreMappings": {"organization", "accountStore"},
+}
+
+//cacheKey returns the canonical href of the resource the URL points to and the cache key of the URL,
+//the key is the href followed by the sorted query string so every expansion of a resource is a variant of its href
+func cacheKey(u *url.URL) (href string, key string) {
+	href = u.Scheme + "://" + u.Host + strings.TrimSuffix(u.Path, "/")
+
+	query := u.Query().Encode()
+	if query == "" {
+		return href, href
+	}
+	return href, href + "?" + query
+}
+
+//hrefSegments returns the path segments of the href, e.g. [v1 accounts id groups]
+func hrefSegments(href string) []string {
+	u, err := url.Parse(href)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.Trim(u.Path, "/"), "/")
+}
+
+//hrefParent returns the href of the resource owning the given sub resource or collection,
+//e.g. the account of its customData or groups collection, it returns an empty string for top level resources
+func hrefParent(href string) string {
+	if len(hrefSegments(href)) < 4 {
+		return ""
+	}
+	return href[:strings.LastIndex(href, "/")]
+}
+
+//hrefKind returns the kind of resource the href points to, the collection name for collections
+//and the kind of resource for resources, e.g. groupMemberships for both /groupMemberships/id and /accounts/id/groupMemberships
+func hrefKind(href string) string {
+	segments := hrefSegments(href)
+	switch {
+	case len(segments) == 2 || len(segments) >= 4:
+		return segments[len(segments)-1]
+	case len(segments) == 3:
+		return segments[1]
+	}
+	return ""
+}
+
+//Cache index limits, the index is swept for expired variants every time it registered as many new variants
+//as it had after the previous sweep, and it remembers the links of the maxCacheIndexLinks latest relationship resources
+const (
+	minCacheIndexSweep = 1024
+	maxCacheIndexLinks = 10000
+)
+
+//cacheIndex tracks the cached variants of every href, the sub resources cached under each href
+//and the resources linked by known relationship resources so writes can evict everything they change.
+//
+//The index only knows about the entries cached through this client. Caches don't report their evictions,
+//so a variant is forgotten once the deadline of its entry passed, the entry is expired by then.
+type cacheIndex struct {
+	mutex     sync.Mutex
+	variants  map[string]map[string]time.Time
+	children  map[string]map[string]bool
+	links     map[string][]string
+	linkOrder []string
+	added     int
+	sweepAt   int
+}
+
+func newCacheIndex() *cacheIndex {
+	return &cacheIndex{
+		variants: map[string]map[string]time.Time{},
+		children: map[string]map[string]bool{},
+		links:    map[string][]string{},
+		sweepAt:  minCacheIndexSweep,
+	}
+}
+
+//add registers the cache key as a variant of href, expires is the deadline of the cached entry, zero if it never expires
+func (index *cacheIndex) add(href string, key string, expires time.Time) {
+	if index == nil {
+		return
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	keys, ok := index.variants[href]
+	if !ok {
+		keys = map[string]time.Time{}
+		index.variants[href] = keys
+	}
+	if _, exists := keys[key]; !exists {
+		index.added++
+	}
+	keys[key] = expires
+	for child, parent := href, hrefParent(href); parent != ""; child, parent = parent, hrefParent(parent) {
+		addToSet(index.children, parent, child)
+	}
+
+	if index.added >= index.sweepAt {
+		index.sweepLocked(time.Now())
+	}
+}
+
+//sweepLocked forgets the variants whose entries expired before now and the hrefs left without cached variants
+//nor cached sub resources
+func (index *cacheIndex) sweepLocked(now time.Time) {
+	size := 0
+	for href, keys := range index.variants {
+		for key, expires := range keys {
+			if !expires.IsZero() && expires.Before(now) {
+				delete(keys, key)
+			}
+		}
+		if len(keys) == 0 {
+			delete(index.variants, href)
+		}
+		size += len(keys)
+	}
+
+	live := map[string]bool{}
+	var isLive func(href string) bool
+	isLive = func(href string) bool {
+		if l, ok := live[href]; ok {
+			return l
+		}
+		live[href] = len(index.variants[href]) > 0
+		for child := range index.children[href] {
+			if isLive(child) {
+				live[href] = true
+			}
+		}
+		return live[href]
+	}
+	for href, children := range index.children {
+		for child := range children {
+			if !isLive(child) {
+				delete(children, child)
+			}
+		}
+		if len(children) == 0 {
+			delete(index.children, href)
+		}
+	}
+
+	index.added = 0
+	index.sweepAt = size
+	if index.sweepAt < minCacheIndexSweep {
+		index.sweepAt = minCacheIndexSweep
+	}
+}
+
+//link registers the resources linked by the relationship resource href, the links of the oldest relationship
+//resources are forgotten beyond maxCacheIndexLinks
+func (index *cacheIndex) link(href string, linked []string) {
+	if index == nil || len(linked) == 0 {
+		return
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	if _, exists := index.links[href]; !exists {
+		index.linkOrder = append(index.linkOrder, href)
+	}
+	index.links[href] = linked
+
+	for len(index.links) > maxCacheIndexLinks {
+		oldest := index.linkOrder[0]
+		index.linkOrder = index.linkOrder[1:]
+		delete(index.links, oldest)
+	}
+	//Drop the hrefs of the relationship resources unlinked meanwhile
+	if len(index.linkOrder) > 2*maxCacheIndexLinks {
+		order := make([]string, 0, len(index.links))
+		for _, l := range index.linkOrder {
+			if _, exists := index.links[l]; exists {
+				order = append(order, l)
+			}
+		}
+		index.linkOrder = order
+	}
+}
+
+//unlink forgets and returns the resources linked by the relationship resource href
+func (index *cacheIndex) unlink(href string) []string {
+	if index == nil {
+		return nil
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	linked := index.links[href]
+	delete(index.links, href)
+	return linked
+}
+
+//evict forgets and returns the cache keys of every variant of href, if tree is true the keys of the sub resources
+//cached under href are returned too
+func (index *cacheIndex) evict(href string, tree bool) []string {
+	keys := []string{href}
+	if index == nil {
+		return keys
+	}
+	index.mutex.Lock()
+	defer index.mutex.Unlock()
+
+	return index.evictLocked(href, tree, keys)
+}
+
+func (index *cacheIndex) evictLocked(href string, tree bool, keys []string) []string {
+	for key := range index.variants[href] {
+		if key != href {
+			keys = append(keys, key)
+		}
+	}
+	delete(index.variants, href)
+
+	if tree {
+		for child := range index.children[href] {
+			keys = append(keys, child)
+			keys = index.evictLocked(child, true, keys)
+		}
+		delete(index.children, href)
+	}
+	return keys
+}
+
+func addToSet(sets map[string]map[string]bool, key string, value string) {
+	set, ok := sets[key]
+	if !ok {
+		set = map[string]bool{}
+		sets[key] = set
+	}
+	set[value] = true
+}
+
+//relationshipLinkHrefs returns the hrefs linked by the relationship resources in the JSON document,
+//either a single resource or a collection of them, by relationship resource href
+func relationshipLinkHrefs(kind string, data []byte) map[string][]string {
+	attributes, ok := relationshipLinks[kind]
+	if !ok || len(data) == 0 {
+		return nil
+	}
+
+	type link struct {
+		Href string `json:"href"`
+	}
+	document := struct {
+		Items []map[string]json.RawMessage `json:"items"`
+	}{}
+	if json.Unmarshal(data, &document) != nil {
+		return nil
+	}
+	resources := document.Items
+	if resources == nil {
+		resource := map[string]json.RawMessage{}
+		json.Unmarshal(data, &resource)
+		resources = append(resources, resource)
+	}
+
+	result := map[string][]string{}
+	for _, resource := range resources {
+		href := ""
+		json.Unmarshal(resource["href"], &href)
+		href = strings.TrimSuffix(href, "/")
+
+		for _, attribute := range attributes {
+			linked := link{}
+			if json.Unmarshal(resource[attribute], &linked) == nil && linked.Href != "" {
+				result[href] = append(result[href], strings.TrimSuffix(linked.Href, "/"))
+			}
+		}
+	}
+	return result
+}
+
+//cachedResponse returns the cached JSON for the GET request if any and its freshness deadline,
+//the deadline is zero unless stale serving is enabled
+func (client *Client) cachedResponse(request *http.Request) ([]byte, time.Time) {
+	if client.Cache == nil || request.Method != http.MethodGet {
+		return nil, time.Time{}
+	}
+
+	_, key := cacheKey(request.URL)
+	data, _ := getIfPresent(client.Cache, key)
+	return decodeCacheEntry(data)
+}
+
+//cacheResponse caches the JSON of a successful GET request for a cacheable result under the request href variant,
+//it also indexes the links of the relationship resources found in the response.
+//
+//Responses served from the cache are only indexed, storing them again would extend their TTL.
+func (client *Client) cacheResponse(request *http.Request, result interface{}, data []byte, cached bool) {
+	if client.Cache == nil || request.Method != http.MethodGet {
+		return
+	}
+
+	href, key := cacheKey(request.URL)
+	for relationship, linked := range relationshipLinkHrefs(hrefKind(href), data) {
+		client.cacheIndex.link(relationship, linked)
+	}
+
+	c, ok := result.(Cacheable)
+	if ok &&
+		c.IsCacheable() &&
+		!strings.Contains(key, "passwordResetTokens") &&
+		!strings.Contains(key, "authTokens") {
+		//Stale entries must outlive their TTL in the cache to be served
+		config := client.ClientConfiguration
+		ttl, expires := config.cacheTTL(key), time.Time{}
+		if ttl > 0 {
+			ttl += config.staleWindow()
+			expires = time.Now().Add(ttl)
+		}
+		client.cacheIndex.add(href, key, expires)
+		if !cached {
+			entry := data
+			if config.staleWindow() > 0 {
+				entry = encodeCacheEntry(data, time.Now().Add(config.cacheTTL(key)))
+			}
+			setWithTTL(client.Cache, key, entry, ttl)
+			client.cacheMetrics.set(key)
+		}
+	}
+}
+
+//invalidateCache evicts the cached entries changed by a successful POST or DELETE request:
+//every variant of the written href and its cached sub resources, the variants of the resources owning it,
+//and for relationship resources, such as group memberships, the linked resources and their sub resources.
+//
+//The links of the relationship resources created or updated by a POST are indexed so deleting them later
+//evicts the linked resources.
+func (client *Client) invalidateCache(request *http.Request, response []byte) {
+	if client.Cache == nil || (request.Method != http.MethodPost && request.Method != http.MethodDelete) {
+		return
+	}
+	payload, _ := requestPayload(request)
+
+	href, _ := cacheKey(request.URL)
+	keys := client.cacheIndex.evict(href, true)
+	for parent := hrefParent(href); parent != ""; parent = hrefParent(parent) {
+		keys = append(keys, client.cacheIndex.evict(parent, false)...)
+	}
+
+	linked := client.cacheIndex.unlink(href)
+	kind := hrefKind(href)
+	for _, data := range [][]byte{payload, response} {
+		for relationship, hrefs := range relationshipLinkHrefs(kind, data) {
+			linked = append(linked, hrefs...)
+			linked = append(linked, client.cacheIndex.unlink(relationship)...)
+		}
+	}
+	if request.Method == http.MethodPost {
+		for relationship, hrefs := range relationshipLinkHrefs(kind, response) {
+			if relationship != "" {
+				client.cacheIndex.link(relationship, hrefs)
+			}
+		}
+	}
+	for _, l := range linked {
+		keys = append(keys, client.cacheIndex.evict(l, true)...)
+	}
+
+	for _, key := range keys {
+		client.Cache.Del(key)
+		client.cacheMetrics.invalidate(key)
+	}
+}
@@ -0,0 +1,40 @@
+package stormpath_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarias/stormpath-sdk-go"
+	"github.com/jarias/stormpath-sdk-go/stormpathtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeletedGroupMembershipEvictsAccount(t *testing.T) {
+	t.Parallel()
+
+	server := stormpathtest.NewServer()
+	defer server.Close()
+	config := server.ClientConfiguration()
+	config.CacheManagerEnabled = true
+	ctx := stormpath.NewContext(context.Background(), stormpath.NewClient(config, nil))
+
+	directory := stormpath.NewDirectory("memberships")
+	assert.NoError(t, stormpath.CreateDirectoryWithContext(ctx, directory))
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, directory.RegisterAccountWithContext(ctx, account))
+	group := stormpath.NewGroup("admins")
+	assert.NoError(t, directory.CreateGroupWithContext(ctx, group))
+
+	membership, err := account.AddToGroupWithContext(ctx, group)
+	assert.NoError(t, err)
+
+	cached, err := stormpath.GetAccountWithContext(ctx, account.Href, stormpath.MakeAccountCriteria().WithGroups(stormpath.DefaultPageRequest))
+	assert.NoError(t, err)
+	assert.Len(t, cached.Groups.Items, 1)
+
+	assert.NoError(t, membership.DeleteWithContext(ctx))
+
+	cached, err = stormpath.GetAccountWithContext(ctx, account.Href, stormpath.MakeAccountCriteria().WithGroups(stormpath.DefaultPageRequest))
+	assert.NoError(t, err)
+	assert.Empty(t, cached.Groups.Items)
+}
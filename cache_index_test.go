@@ -0,0 +1,210 @@
+package stormpath
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCacheTestServer() *testServer {
+	var s *testServer
+	s = newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1/accounts/1", "/v1/accounts/1/":
+			w.Write([]byte(`{"href":"` + s.URL + `/v1/accounts/1","username":"john","customData":{"href":"` + s.URL + `/v1/accounts/1/customData"},"groupMemberships":{"href":"` + s.URL + `/v1/accounts/1/groupMemberships"}}`))
+		case "/v1/accounts/1/customData":
+			w.Write([]byte(`{"href":"` + s.URL + `/v1/accounts/1/customData","plan":"gold"}`))
+		case "/v1/groups/1", "/v1/groups/1/":
+			w.Write([]byte(`{"href":"` + s.URL + `/v1/groups/1","name":"admins"}`))
+		case "/v1/groupMemberships":
+			w.Write([]byte(`{"href":"` + s.URL + `/v1/groupMemberships/1","account":{"href":"` + s.URL + `/v1/accounts/1"},"group":{"href":"` + s.URL + `/v1/groups/1"}}`))
+		case "/v1/accounts/1/groupMemberships/":
+			w.Write([]byte(`{"href":"memberships","offset":0,"limit":25,"size":1,"items":[{"href":"` + s.URL + `/v1/groupMemberships/1","account":{"href":"` + s.URL + `/v1/accounts/1"},"group":{"href":"` + s.URL + `/v1/groups/1"}}]}`))
+		case "/v1/groupMemberships/1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	return s
+}
+
+func newCacheTestContext(server *testServer) (context.Context, *LocalCache) {
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+
+	cache := NewLocalCache(time.Minute, time.Minute)
+	ctx, _ := newTestContext(config, cache)
+	return ctx, cache
+}
+
+func TestCacheKey(t *testing.T) {
+	t.Parallel()
+
+	u, _ := url.Parse("https://api.stormpath.com/v1/accounts/1/?limit=25&expand=directory&offset=0")
+	href, key := cacheKey(u)
+
+	assert.Equal(t, "https://api.stormpath.com/v1/accounts/1", href)
+	assert.Equal(t, "https://api.stormpath.com/v1/accounts/1?expand=directory&limit=25&offset=0", key)
+
+	u, _ = url.Parse("https://api.stormpath.com/v1/accounts/1/")
+	href, key = cacheKey(u)
+
+	assert.Equal(t, "https://api.stormpath.com/v1/accounts/1", href)
+	assert.Equal(t, href, key)
+
+	assert.Equal(t, "https://api.stormpath.com/v1/accounts/1", hrefParent("https://api.stormpath.com/v1/accounts/1/customData"))
+	assert.Equal(t, "", hrefParent("https://api.stormpath.com/v1/accounts/1"))
+	assert.Equal(t, "groupMemberships", hrefKind("https://api.stormpath.com/v1/groupMemberships/1"))
+	assert.Equal(t, "groupMemberships", hrefKind("https://api.stormpath.com/v1/accounts/1/groupMemberships"))
+	assert.Equal(t, "groupMemberships", hrefKind("https://api.stormpath.com/v1/groupMemberships"))
+}
+
+func TestUpdateEvictsEveryVariant(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	ctx, cache := newCacheTestContext(server)
+	href := server.URL + "/v1/accounts/1"
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	_, err = GetAccountWithContext(ctx, href, MakeAccountCriteria().WithCustomData())
+	assert.NoError(t, err)
+	_, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, server.count(http.MethodGet, "/v1/accounts/1"))
+	assert.True(t, cache.Exists(href))
+	assert.True(t, cache.Exists(href+"?expand=customData"))
+
+	assert.NoError(t, account.UpdateWithContext(ctx))
+
+	assert.False(t, cache.Exists(href))
+	assert.False(t, cache.Exists(href+"?expand=customData"))
+}
+
+func TestCustomDataUpdateEvictsOwner(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	ctx, cache := newCacheTestContext(server)
+	href := server.URL + "/v1/accounts/1"
+
+	account, _ := GetAccountWithContext(ctx, href, MakeAccountCriteria().WithCustomData())
+	_, err := account.GetCustomDataWithContext(ctx)
+	assert.NoError(t, err)
+	assert.True(t, cache.Exists(href+"/customData"))
+
+	_, err = account.UpdateCustomDataWithContext(ctx, CustomData{"plan": "silver"})
+	assert.NoError(t, err)
+
+	assert.False(t, cache.Exists(href+"/customData"))
+	assert.False(t, cache.Exists(href+"?expand=customData"))
+}
+
+func TestDeleteEvictsSubResources(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	ctx, cache := newCacheTestContext(server)
+	href := server.URL + "/v1/accounts/1"
+
+	account, _ := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	account.GetCustomDataWithContext(ctx)
+	assert.True(t, cache.Exists(href))
+	assert.True(t, cache.Exists(href+"/customData"))
+
+	account.DeleteWithContext(ctx)
+
+	assert.False(t, cache.Exists(href))
+	assert.False(t, cache.Exists(href+"/customData"))
+}
+
+func TestGroupMembershipChangesEvictAccountAndGroup(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	ctx, cache := newCacheTestContext(server)
+	accountHref := server.URL + "/v1/accounts/1"
+	groupHref := server.URL + "/v1/groups/1"
+
+	account, _ := GetAccountWithContext(ctx, accountHref, MakeAccountCriteria().WithGroups(DefaultPageRequest))
+	group, _ := GetGroupWithContext(ctx, groupHref, MakeGroupCriteria().WithAccounts(DefaultPageRequest))
+	accountKey := accountHref + "?expand=groups%28offset%3A0%2Climit%3A25%29"
+	groupKey := groupHref + "?expand=accounts%28offset%3A0%2Climit%3A25%29"
+	assert.True(t, cache.Exists(accountKey))
+	assert.True(t, cache.Exists(groupKey))
+
+	_, err := account.AddToGroupWithContext(ctx, group)
+	assert.NoError(t, err)
+
+	assert.False(t, cache.Exists(accountKey))
+	assert.False(t, cache.Exists(groupKey))
+
+	GetAccountWithContext(ctx, accountHref, MakeAccountCriteria().WithGroups(DefaultPageRequest))
+	GetGroupWithContext(ctx, groupHref, MakeGroupCriteria().WithAccounts(DefaultPageRequest))
+	assert.True(t, cache.Exists(accountKey))
+	assert.True(t, cache.Exists(groupKey))
+
+	assert.NoError(t, account.RemoveFromGroupWithContext(ctx, group))
+
+	assert.False(t, cache.Exists(accountKey))
+	assert.False(t, cache.Exists(groupKey))
+}
+
+func TestCacheIndexSweepsExpiredVariants(t *testing.T) {
+	t.Parallel()
+
+	index := newCacheIndex()
+	expired := time.Now().Add(-time.Second)
+	index.add("https://api.stormpath.com/v1/accounts/1/customData", "https://api.stormpath.com/v1/accounts/1/customData", expired)
+	index.add("https://api.stormpath.com/v1/accounts/2", "https://api.stormpath.com/v1/accounts/2", time.Time{})
+	index.add("https://api.stormpath.com/v1/accounts/2", "https://api.stormpath.com/v1/accounts/2?expand=groups", expired)
+
+	index.sweepLocked(time.Now())
+
+	assert.Len(t, index.variants, 1)
+	assert.Len(t, index.variants["https://api.stormpath.com/v1/accounts/2"], 1)
+	assert.Empty(t, index.children)
+
+	for i := 0; i < 2*minCacheIndexSweep; i++ {
+		href := fmt.Sprintf("https://api.stormpath.com/v1/groups/%d", i)
+		index.add(href, href, expired)
+	}
+
+	assert.True(t, len(index.variants) < minCacheIndexSweep+1)
+}
+
+func TestCacheIndexBoundsLinks(t *testing.T) {
+	t.Parallel()
+
+	index := newCacheIndex()
+	membership := func(i int) string {
+		return fmt.Sprintf("https://api.stormpath.com/v1/groupMemberships/%d", i)
+	}
+	for i := 0; i < maxCacheIndexLinks+10; i++ {
+		index.link(membership(i), []string{"https://api.stormpath.com/v1/accounts/1"})
+	}
+
+	assert.Len(t, index.links, maxCacheIndexLinks)
+	assert.Nil(t, index.unlink(membership(9)))
+	assert.NotNil(t, index.unlink(membership(10)))
+
+	for i := 0; i < 3*maxCacheIndexLinks; i++ {
+		index.link(membership(i), []string{"https://api.stormpath.com/v1/accounts/1"})
+		index.unlink(membership(i))
+	}
+
+	assert.Empty(t, index.links)
+	assert.True(t, len(index.linkOrder) <= 2*maxCacheIndexLinks)
+}
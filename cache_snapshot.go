@@ -0,0 +1,95 @@
+package stormpath
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//cacheSnapshotVersion is the version of the cache snapshot file format
+const cacheSnapshotVersion = 1
+
+var errCacheSnapshotUnsupported = errors.New("the client cache doesn't support snapshots")
+
+//CacheEntry is a cache entry with its expiration time as saved in a cache snapshot
+type CacheEntry struct {
+	Key     string    `json:"key"`
+	Data    []byte    `json:"data"`
+	Expires time.Time `json:"expires"`
+}
+
+//CacheSnapshotter is implemented by the caches whose content can be saved to a snapshot and restored from it,
+//such as LocalCache. Shared caches such as Redis outlive the clients and don't need snapshots.
+type CacheSnapshotter interface {
+	//Entries returns the entries that aren't expired from the least to the most recently used
+	Entries() []CacheEntry
+	//Restore adds the entries that aren't expired in order and returns how many were added
+	Restore(entries []CacheEntry) int
+}
+
+//cacheSnapshot is the content of a cache snapshot file
+type cacheSnapshot struct {
+	Version int          `json:"version"`
+	Entries []CacheEntry `json:"entries"`
+}
+
+//SaveCacheSnapshot saves the entries of the client cache to the given file so a new process can restore them with
+//LoadCacheSnapshot. The file is replaced atomically and only readable by its owner, the values of an EncryptedCache
+//stay encrypted in it.
+func (client *Client) SaveCacheSnapshot(path string) error {
+	snapshotter, ok := client.Cache.(CacheSnapshotter)
+	if !ok {
+		return errCacheSnapshotUnsupported
+	}
+
+	file, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	err = json.NewEncoder(file).Encode(cacheSnapshot{Version: cacheSnapshotVersion, Entries: snapshotter.Entries()})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+//LoadCacheSnapshot restores the entries saved by SaveCacheSnapshot in the client cache and returns how many were
+//restored, the entries expired since the snapshot was saved are skipped
+func (client *Client) LoadCacheSnapshot(path string) (int, error) {
+	snapshotter, ok := client.Cache.(CacheSnapshotter)
+	if !ok {
+		return 0, errCacheSnapshotUnsupported
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	snapshot := cacheSnapshot{}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, err
+	}
+	if snapshot.Version != cacheSnapshotVersion {
+		return 0, fmt.Errorf("unsupported cache snapshot version %d", snapshot.Version)
+	}
+
+	restored := snapshotter.Restore(snapshot.Entries)
+	//Restored entries are indexed so writes evict them like the entries cached by this client
+	for _, entry := range snapshot.Entries {
+		if u, err := url.Parse(entry.Key); err == nil {
+			href, _ := cacheKey(u)
+			client.cacheIndex.add(href, entry.Key, entry.Expires)
+		}
+	}
+	return restored, nil
+}
@@ -0,0 +1,159 @@
+package stormpath
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func entryKeys(entries []CacheEntry) []string {
+	keys := []string{}
+	for _, entry := range entries {
+		keys = append(keys, entry.Key)
+	}
+	return keys
+}
+
+func TestLocalCacheEntriesRestore(t *testing.T) {
+	t.Parallel()
+
+	cache := NewLocalCache(time.Minute, time.Minute)
+	defer cache.Close()
+	cache.Set("a", []byte("a"))
+	cache.Set("b", []byte("b"))
+	cache.Set("c", []byte("c"))
+	cache.Get("a")
+
+	entries := cache.Entries()
+
+	assert.Equal(t, []string{"b", "c", "a"}, entryKeys(entries))
+
+	restored := NewBoundedLocalCache(time.Minute, time.Minute, 2, 0)
+	defer restored.Close()
+	restored.Set("c", []byte("cached"))
+	entries = append(entries, CacheEntry{Key: "expired", Data: []byte("expired"), Expires: time.Now().Add(-time.Second)})
+
+	//The cached entries are more recent than the restored ones, b is the least recently used and doesn't fit
+	assert.Equal(t, 1, restored.Restore(entries))
+	assert.Equal(t, []byte("cached"), restored.Get("c"))
+	assert.Equal(t, []byte("a"), restored.Get("a"))
+	assert.False(t, restored.Exists("b"))
+	assert.False(t, restored.Exists("expired"))
+	assert.Equal(t, []string{"c", "a"}, entryKeys(restored.Entries()))
+
+	//Restored entries never outlive the cache TTL
+	short := NewLocalCache(20*time.Millisecond, 20*time.Millisecond)
+	defer short.Close()
+	short.Restore(entries)
+	time.Sleep(30 * time.Millisecond)
+
+	assert.False(t, short.Exists("a"))
+}
+
+func TestRegionCacheEntriesRestore(t *testing.T) {
+	t.Parallel()
+
+	regions := map[string]CacheRegionConfiguration{CacheRegionAccounts: {TTL: time.Minute, TTI: time.Minute}}
+	account := "https://api.stormpath.com/v1/accounts/1"
+	group := "https://api.stormpath.com/v1/groups/1"
+
+	cache := NewRegionCache(time.Minute, time.Minute, regions, 0, 0)
+	defer cache.Close()
+	cache.Set(account, []byte("account"))
+	cache.Set(group, []byte("group"))
+
+	restored := NewRegionCache(time.Minute, time.Minute, regions, 0, 0)
+	defer restored.Close()
+
+	assert.Equal(t, 2, restored.Restore(cache.Entries()))
+	assert.True(t, restored.Regions[CacheRegionAccounts].Exists(account))
+	assert.True(t, restored.Default.Exists(group))
+}
+
+func TestEncryptedCacheEntriesRestore(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+	cache, _ := NewEncryptedCache(backend, "tenant", testEncryptionKey("k1", 32))
+	other, _ := NewEncryptedCache(backend, "other", testEncryptionKey("k1", 32))
+	cache.Set(key, []byte("data"))
+	other.Set(key, []byte("other"))
+
+	entries := cache.Entries()
+
+	assert.Equal(t, []string{key}, entryKeys(entries))
+	assert.NotEqual(t, []byte("data"), entries[0].Data)
+
+	restoredBackend := NewLocalCache(time.Minute, time.Minute)
+	defer restoredBackend.Close()
+	restored, _ := NewEncryptedCache(restoredBackend, "tenant", testEncryptionKey("k1", 32))
+
+	assert.Equal(t, 1, restored.Restore(entries))
+	assert.Equal(t, []byte("data"), restored.Get(key))
+}
+
+func TestClientCacheSnapshot(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"href":"http://%s%s","username":"john"}`, r.Host, r.URL.Path)
+	})
+	defer server.Close()
+
+	dir, _ := ioutil.TempDir("", "stormpath-snapshot")
+	defer os.RemoveAll(dir)
+	snapshotFile := filepath.Join(dir, "cache.json")
+
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	config.CacheSnapshotFile = snapshotFile
+	href := server.URL + "/v1/accounts/1"
+
+	client := NewClient(config, nil)
+	_, err := GetAccountWithContext(NewContext(context.Background(), client), href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	assert.NoError(t, client.Close())
+
+	info, err := os.Stat(snapshotFile)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	restored := NewClient(config, nil)
+	defer restored.Cache.(*LocalCache).Close()
+
+	account, err := GetAccountWithContext(NewContext(context.Background(), restored), href, MakeAccountCriteria())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "john", account.Username)
+	assert.Len(t, server.received(), 1)
+	assert.NotEmpty(t, restored.cacheIndex.evict(href, false))
+
+	_, err = restored.LoadCacheSnapshot(filepath.Join(dir, "missing.json"))
+
+	assert.True(t, os.IsNotExist(err))
+
+	//A given cache is never restored nor saved by the client
+	given := NewLocalCache(time.Minute, time.Minute)
+	defer given.Close()
+	shared := NewClient(config, given)
+
+	assert.Empty(t, given.Entries())
+
+	given.Set(server.URL+"/v1/accounts/2", []byte(`{"username":"jane"}`))
+	assert.NoError(t, shared.Close())
+	data, _ := ioutil.ReadFile(snapshotFile)
+
+	assert.NotContains(t, string(data), "accounts/2")
+
+	config.CacheManagerEnabled = false
+
+	assert.Error(t, NewClient(config, nil).SaveCacheSnapshot(snapshotFile))
+}
@@ -0,0 +1,266 @@
+package stormpath
+
+import (
+	"expvar"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+//CacheEvictionCounter is implemented by the caches that count the entries they evict to keep within their size limits
+//and the entries they expire, its counters are reported in the CacheStats of the clients using the cache
+type CacheEvictionCounter interface {
+	Evictions() uint64
+	Expirations() uint64
+}
+
+//CacheStatsProvider is implemented by anything exposing CacheStats, such as a Client
+type CacheStatsProvider interface {
+	CacheStats() CacheStats
+}
+
+//LatencyStats summarizes the latencies of a request path, Max is the slowest request
+type LatencyStats struct {
+	Count uint64
+	Total time.Duration
+	Max   time.Duration
+}
+
+//Mean returns the mean latency or 0 if there were no requests
+func (stats LatencyStats) Mean() time.Duration {
+	if stats.Count == 0 {
+		return 0
+	}
+	return stats.Total / time.Duration(stats.Count)
+}
+
+//CacheStats is a snapshot of the cache counters of a Client.
+//
+//Hits and Misses count the GET requests served from the cache or not, Sets the responses stored in the cache
+//and Invalidations the entries deleted because of a write. Coalesced counts the missed GET requests that shared
+//the response of an identical request in flight instead of sending their own. Stale counts the responses served
+//from expired entries, while they are revalidated or because Stormpath couldn't be reached. Evictions and Expirations are reported by the cache
+//when it implements CacheEvictionCounter.
+//
+//CacheLatency is the latency of the GET requests served from the cache and NetworkLatency the latency of the
+//GET requests sent to Stormpath, both include decoding the response.
+type CacheStats struct {
+	Hits           uint64
+	Misses         uint64
+	Sets           uint64
+	Invalidations  uint64
+	Coalesced      uint64
+	Stale          uint64
+	Evictions      uint64
+	Expirations    uint64
+	CacheLatency   LatencyStats
+	NetworkLatency LatencyStats
+}
+
+//HitRatio returns the ratio of GET requests served from the cache
+func (stats CacheStats) HitRatio() float64 {
+	if stats.Hits+stats.Misses == 0 {
+		return 0
+	}
+	return float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+}
+
+//Metrics returns the stats as metric values by Prometheus style metric name, counters end with _total
+//and latencies are in seconds with a _sum and a _count metric
+func (stats CacheStats) Metrics() map[string]float64 {
+	return map[string]float64{
+		"stormpath_cache_hits_total":                    float64(stats.Hits),
+		"stormpath_cache_misses_total":                  float64(stats.Misses),
+		"stormpath_cache_sets_total":                    float64(stats.Sets),
+		"stormpath_cache_invalidations_total":           float64(stats.Invalidations),
+		"stormpath_cache_coalesced_total":               float64(stats.Coalesced),
+		"stormpath_cache_stale_total":                   float64(stats.Stale),
+		"stormpath_cache_evictions_total":               float64(stats.Evictions),
+		"stormpath_cache_expirations_total":             float64(stats.Expirations),
+		"stormpath_cache_latency_seconds_count":         float64(stats.CacheLatency.Count),
+		"stormpath_cache_latency_seconds_sum":           stats.CacheLatency.Total.Seconds(),
+		"stormpath_cache_network_latency_seconds_count": float64(stats.NetworkLatency.Count),
+		"stormpath_cache_network_latency_seconds_sum":   stats.NetworkLatency.Total.Seconds(),
+	}
+}
+
+//cacheMetricFamily describes a metric of the CacheStats Metrics in the Prometheus text format,
+//the samples of a summary are its _sum and _count metrics
+type cacheMetricFamily struct {
+	name       string
+	metricType string
+	help       string
+}
+
+var cacheMetricFamilies = []cacheMetricFamily{
+	{"stormpath_cache_hits_total", "counter", "GET requests served from the cache."},
+	{"stormpath_cache_misses_total", "counter", "GET requests not served from the cache."},
+	{"stormpath_cache_sets_total", "counter", "Responses stored in the cache."},
+	{"stormpath_cache_invalidations_total", "counter", "Cache entries deleted because of a write."},
+	{"stormpath_cache_coalesced_total", "counter", "Missed GET requests that shared the response of an identical request in flight."},
+	{"stormpath_cache_stale_total", "counter", "Responses served from expired cache entries."},
+	{"stormpath_cache_evictions_total", "counter", "Cache entries evicted to keep within the cache size limits."},
+	{"stormpath_cache_expirations_total", "counter", "Cache entries expired."},
+	{"stormpath_cache_latency_seconds", "summary", "Latency of the GET requests served from the cache."},
+	{"stormpath_cache_network_latency_seconds", "summary", "Latency of the GET requests sent to Stormpath."},
+}
+
+//PublishCacheStats publishes the cache stats of the provider as an expvar map with the given name,
+//the stats are read every time the variable is. Like expvar.Publish it panics if the name is already in use
+func PublishCacheStats(name string, provider CacheStatsProvider) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return provider.CacheStats().Metrics()
+	}))
+}
+
+//CacheStatsHandler returns an http.Handler writing the cache stats of the provider in the Prometheus text format,
+//the counters are exposed as counters and the latencies as summaries without quantiles
+func CacheStatsHandler(provider CacheStatsProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		metrics := provider.CacheStats().Metrics()
+
+		w.Header().Set(ContentTypeHeader, "text/plain; version=0.0.4")
+		for _, family := range cacheMetricFamilies {
+			fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", family.name, family.help, family.name, family.metricType)
+			if family.metricType == "summary" {
+				fmt.Fprintf(w, "%s_sum %g\n", family.name, metrics[family.name+"_sum"])
+				fmt.Fprintf(w, "%s_count %g\n", family.name, metrics[family.name+"_count"])
+				continue
+			}
+			fmt.Fprintf(w, "%s %g\n", family.name, metrics[family.name])
+		}
+	})
+}
+
+//CacheEventType is the type of a CacheEvent
+type CacheEventType int
+
+//Cache event types
+const (
+	CacheHit CacheEventType = iota
+	CacheMiss
+	CacheSet
+	CacheInvalidation
+	CacheCoalesced
+	CacheStale
+)
+
+//CacheEvent is sent to the cache observers of a Client for every cache access, Duration is the latency
+//of the request for hits and misses and 0 for the other events
+type CacheEvent struct {
+	Type     CacheEventType
+	Key      string
+	Duration time.Duration
+}
+
+//CacheObserver receives the cache events of a Client, it is called synchronously so it must be fast
+type CacheObserver func(event CacheEvent)
+
+//ObserveCache appends the given observers to the client cache observers.
+//
+//Observers should be configured before the client is used, ObserveCache is not safe for concurrent use with
+//requests in flight.
+func (client *Client) ObserveCache(observers ...CacheObserver) {
+	client.cacheMetrics.observers = append(client.cacheMetrics.observers, observers...)
+}
+
+//CacheStats returns a snapshot of the client cache stats
+func (client *Client) CacheStats() CacheStats {
+	stats := client.cacheMetrics.stats()
+
+	if counter, ok := client.Cache.(CacheEvictionCounter); ok {
+		stats.Evictions = counter.Evictions()
+		stats.Expirations = counter.Expirations()
+	}
+	return stats
+}
+
+//cacheMetrics holds the cache counters of a Client
+type cacheMetrics struct {
+	hits          uint64
+	misses        uint64
+	sets          uint64
+	invalidations uint64
+	coalesced     uint64
+	staleServed   uint64
+
+	mutex     sync.Mutex
+	cache     LatencyStats
+	network   LatencyStats
+	observers []CacheObserver
+}
+
+func newCacheMetrics() *cacheMetrics {
+	return &cacheMetrics{}
+}
+
+//request records a GET request served from the cache or the network, hits and misses are only counted
+//when the client has a cache
+func (metrics *cacheMetrics) request(key string, cached bool, hasCache bool, duration time.Duration) {
+	metrics.mutex.Lock()
+	latency := &metrics.network
+	if cached {
+		latency = &metrics.cache
+	}
+	latency.Count++
+	latency.Total += duration
+	if duration > latency.Max {
+		latency.Max = duration
+	}
+	metrics.mutex.Unlock()
+
+	if !hasCache {
+		return
+	}
+	if cached {
+		atomic.AddUint64(&metrics.hits, 1)
+		metrics.notify(CacheEvent{Type: CacheHit, Key: key, Duration: duration})
+	} else {
+		atomic.AddUint64(&metrics.misses, 1)
+		metrics.notify(CacheEvent{Type: CacheMiss, Key: key, Duration: duration})
+	}
+}
+
+func (metrics *cacheMetrics) set(key string) {
+	atomic.AddUint64(&metrics.sets, 1)
+	metrics.notify(CacheEvent{Type: CacheSet, Key: key})
+}
+
+func (metrics *cacheMetrics) invalidate(key string) {
+	atomic.AddUint64(&metrics.invalidations, 1)
+	metrics.notify(CacheEvent{Type: CacheInvalidation, Key: key})
+}
+
+func (metrics *cacheMetrics) coalesce(key string) {
+	atomic.AddUint64(&metrics.coalesced, 1)
+	metrics.notify(CacheEvent{Type: CacheCoalesced, Key: key})
+}
+
+func (metrics *cacheMetrics) stale(key string) {
+	atomic.AddUint64(&metrics.staleServed, 1)
+	metrics.notify(CacheEvent{Type: CacheStale, Key: key})
+}
+
+func (metrics *cacheMetrics) notify(event CacheEvent) {
+	for _, observer := range metrics.observers {
+		observer(event)
+	}
+}
+
+func (metrics *cacheMetrics) stats() CacheStats {
+	metrics.mutex.Lock()
+	defer metrics.mutex.Unlock()
+
+	return CacheStats{
+		Hits:           atomic.LoadUint64(&metrics.hits),
+		Misses:         atomic.LoadUint64(&metrics.misses),
+		Sets:           atomic.LoadUint64(&metrics.sets),
+		Invalidations:  atomic.LoadUint64(&metrics.invalidations),
+		Coalesced:      atomic.LoadUint64(&metrics.coalesced),
+		Stale:          atomic.LoadUint64(&metrics.staleServed),
+		CacheLatency:   metrics.cache,
+		NetworkLatency: metrics.network,
+	}
+}
@@ -0,0 +1,125 @@
+package stormpath
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStats(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	ctx, _ := newCacheTestContext(server)
+	client := ClientFromContext(ctx)
+	href := server.URL + "/v1/accounts/1"
+
+	events := []CacheEvent{}
+	client.ObserveCache(func(event CacheEvent) {
+		events = append(events, event)
+	})
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	_, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	assert.NoError(t, account.UpdateWithContext(ctx))
+
+	stats := client.CacheStats()
+
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(1), stats.Misses)
+	assert.Equal(t, uint64(1), stats.Sets)
+	assert.Equal(t, uint64(1), stats.Invalidations)
+	assert.Equal(t, 0.5, stats.HitRatio())
+	assert.Equal(t, uint64(1), stats.CacheLatency.Count)
+	assert.Equal(t, uint64(1), stats.NetworkLatency.Count)
+	assert.True(t, stats.NetworkLatency.Mean() > 0)
+
+	assert.Equal(t, []CacheEventType{CacheMiss, CacheSet, CacheHit, CacheInvalidation}, []CacheEventType{events[0].Type, events[1].Type, events[2].Type, events[3].Type})
+	assert.Equal(t, href, events[0].Key)
+}
+
+func TestCacheStatsWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	server := newCacheTestServer()
+	defer server.Close()
+	config := LoadConfigurationWithCreds("cacheKeyID", "cacheKeySecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+	client := NewClient(config, nil)
+	ctx := NewContext(context.Background(), client)
+
+	GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+	stats := client.CacheStats()
+
+	assert.Equal(t, uint64(0), stats.Misses)
+	assert.Equal(t, uint64(1), stats.NetworkLatency.Count)
+}
+
+func TestCacheStatsEvictions(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("cacheKeyID", "cacheKeySecret")
+	config.CacheManagerEnabled = true
+	cache := NewBoundedLocalCache(time.Minute, time.Minute, 1, 0)
+	client := NewClient(config, cache)
+
+	cache.Set("a", []byte("a"))
+	cache.Set("b", []byte("b"))
+
+	assert.Equal(t, uint64(1), client.CacheStats().Evictions)
+}
+
+func TestCacheStatsHandlerDescribesEveryMetric(t *testing.T) {
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	CacheStatsHandler(cacheStatsFunc(func() CacheStats { return CacheStats{} })).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+	body := recorder.Body.String()
+
+	for name := range (CacheStats{}).Metrics() {
+		family := strings.TrimSuffix(strings.TrimSuffix(name, "_sum"), "_count")
+		assert.Contains(t, body, "\n"+name+" 0\n")
+		assert.Contains(t, body, "# HELP "+family+" ")
+		assert.Contains(t, body, "# TYPE "+family+" ")
+	}
+}
+
+func TestCacheStatsAdapters(t *testing.T) {
+	t.Parallel()
+
+	stats := CacheStats{Hits: 3, Misses: 1, NetworkLatency: LatencyStats{Count: 1, Total: 250 * time.Millisecond}}
+	provider := cacheStatsFunc(func() CacheStats { return stats })
+
+	recorder := httptest.NewRecorder()
+	CacheStatsHandler(provider).ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Contains(t, recorder.Body.String(), "# TYPE stormpath_cache_hits_total counter\nstormpath_cache_hits_total 3\n")
+	assert.Contains(t, recorder.Body.String(), "# TYPE stormpath_cache_network_latency_seconds summary\n")
+	assert.Contains(t, recorder.Body.String(), "stormpath_cache_network_latency_seconds_sum 0.25\nstormpath_cache_network_latency_seconds_count 1\n")
+	assert.NotContains(t, recorder.Body.String(), "_max")
+	assert.True(t, strings.HasPrefix(recorder.Header().Get(ContentTypeHeader), "text/plain"))
+
+	if expvar.Get("stormpath_test_cache") == nil {
+		PublishCacheStats("stormpath_test_cache", provider)
+	}
+	metrics := map[string]float64{}
+	json.Unmarshal([]byte(expvar.Get("stormpath_test_cache").String()), &metrics)
+
+	assert.Equal(t, float64(1), metrics["stormpath_cache_misses_total"])
+}
+
+type cacheStatsFunc func() CacheStats
+
+func (f cacheStatsFunc) CacheStats() CacheStats {
+	return f()
+}
@@ -1,6 +1,7 @@
 package stormpath
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -114,6 +115,145 @@ func TestLocalCacheDeleteObject(t *testing.T) {
 	assert.False(t, cache.Exists(key))
 }
 
+func TestLocalCacheMaxEntriesEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+	cache := NewBoundedLocalCache(5*time.Second, 2*time.Second, 2, 0)
+
+	cache.Set("a", []byte("a"))
+	cache.Set("b", []byte("b"))
+	cache.Get("a")
+	cache.Set("c", []byte("c"))
+
+	assert.Equal(t, 2, cache.Count())
+	assert.Equal(t, []byte("a"), cache.Get("a"))
+	assert.Empty(t, cache.Get("b"))
+	assert.Equal(t, []byte("c"), cache.Get("c"))
+	assert.Equal(t, uint64(1), cache.Evictions())
+}
+
+func TestLocalCacheMaxBytes(t *testing.T) {
+	t.Parallel()
+	cache := NewBoundedLocalCache(5*time.Second, 2*time.Second, 0, 10)
+
+	cache.Set("a", []byte("12345"))
+	cache.Set("b", []byte("12345"))
+	assert.Equal(t, int64(10), cache.Bytes())
+
+	cache.Set("a", []byte("123"))
+	assert.Equal(t, int64(8), cache.Bytes())
+	assert.Equal(t, uint64(0), cache.Evictions())
+
+	cache.Set("c", []byte("1234"))
+	assert.Equal(t, int64(7), cache.Bytes())
+	assert.Empty(t, cache.Get("b"))
+	assert.Equal(t, uint64(1), cache.Evictions())
+
+	cache.Set("d", []byte("12345678901"))
+	assert.Equal(t, int64(7), cache.Bytes())
+	assert.Empty(t, cache.Get("d"))
+	assert.Equal(t, uint64(2), cache.Evictions())
+
+	cache.Del("a")
+	assert.Equal(t, int64(4), cache.Bytes())
+}
+
+func TestLocalCacheExpirations(t *testing.T) {
+	t.Parallel()
+	cache := NewLocalCache(50*time.Millisecond, 50*time.Millisecond)
+
+	cache.Set("a", []byte("a"))
+	cache.Set("b", []byte("b"))
+	time.Sleep(60 * time.Millisecond)
+
+	assert.Empty(t, cache.Get("a"))
+	cache.cleanup()
+
+	assert.Equal(t, 0, cache.Count())
+	assert.Equal(t, int64(0), cache.Bytes())
+	assert.Equal(t, uint64(2), cache.Expirations())
+	assert.Equal(t, uint64(0), cache.Evictions())
+}
+
+func TestLocalCacheGetIfPresent(t *testing.T) {
+	t.Parallel()
+	cache := NewLocalCache(50*time.Millisecond, 50*time.Millisecond)
+	defer cache.Close()
+
+	cache.Set(key, []byte("hello"))
+
+	data, ok := cache.GetIfPresent(key)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("hello"), data)
+
+	time.Sleep(60 * time.Millisecond)
+
+	assert.False(t, cache.Exists(key))
+	_, ok = cache.GetIfPresent(key)
+	assert.False(t, ok)
+}
+
+func TestLocalCacheClose(t *testing.T) {
+	t.Parallel()
+	cache := NewLocalCache(10*time.Millisecond, 10*time.Millisecond)
+
+	assert.NoError(t, cache.Close())
+	assert.NoError(t, cache.Close())
+	<-cache.stopped
+
+	cache.Set(key, []byte("hello"))
+	assert.Equal(t, []byte("hello"), cache.Get(key))
+}
+
+func TestLocalCacheZeroValueClose(t *testing.T) {
+	t.Parallel()
+
+	assert.NoError(t, (&LocalCache{}).Close())
+	assert.NoError(t, (*LocalCache)(nil).Close())
+}
+
+func TestLocalCacheContextShutdown(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cache := NewLocalCacheWithContext(ctx, 10*time.Millisecond, 10*time.Millisecond)
+
+	cancel()
+
+	select {
+	case <-cache.stopped:
+	case <-time.After(time.Second):
+		assert.Fail(t, "janitor still running")
+	}
+	assert.NoError(t, cache.Close())
+}
+
+func TestLocalCacheWithoutExpirationHasNoJanitor(t *testing.T) {
+	t.Parallel()
+	cache := NewLocalCache(0, 0)
+
+	<-cache.stopped
+	assert.NoError(t, cache.Close())
+}
+
+func TestClientClosesOwnedCache(t *testing.T) {
+	t.Parallel()
+	config := LoadConfigurationWithCreds("cacheKeyID", "cacheKeySecret")
+	config.CacheManagerEnabled = true
+
+	client := NewClient(config, nil)
+	assert.NoError(t, client.Close())
+	<-client.Cache.(*LocalCache).stopped
+
+	shared := NewLocalCache(time.Minute, time.Minute)
+	defer shared.Close()
+	assert.NoError(t, NewClient(config, shared).Close())
+
+	select {
+	case <-shared.stopped:
+		assert.Fail(t, "shared cache closed by the client")
+	default:
+	}
+}
+
 func TestNonCacheableResources(t *testing.T) {
 	var resources = []interface{}{
 		&Applications{},
@@ -0,0 +1,114 @@
+package stormpath
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+//DefaultWarmUpConcurrency is the number of warm-up requests in flight
+const DefaultWarmUpConcurrency = 4
+
+//DefaultWarmUpTimeout bounds the background warm-up of the default client started by Init
+const DefaultWarmUpTimeout = time.Minute
+
+//warmUpResource is the result of a warm-up request, resources are cached as is but collections are never cached
+type warmUpResource map[string]interface{}
+
+func (r warmUpResource) IsCacheable() bool {
+	_, collection := r["items"]
+	return !collection
+}
+
+//WarmUpCache preloads the resources of the given hrefs in the cache of the default client
+func WarmUpCache(hrefs ...string) error {
+	return WarmUpCacheWithContext(context.Background(), hrefs...)
+}
+
+//WarmUpCacheWithContext preloads the resources of the given hrefs in the cache of the client bound to ctx.
+//
+//Hrefs are absolute or relative to the client base URL and can have an expand query, e.g.
+//applications/id?expand=accountStoreMappings(offset:0,limit:25), an entry is only hit by the calls with the same
+//query. Every href is loaded even if some fail, the error tells how many failed and wraps the first failure.
+//
+//It returns ErrNoClient when ctx isn't bound to a Client and Init wasn't called.
+func WarmUpCacheWithContext(ctx context.Context, hrefs ...string) error {
+	return warmUpCache(ctx, ClientFromContext(ctx), hrefs)
+}
+
+//warmUpCache preloads the resources of the given hrefs in the cache of the given client
+func warmUpCache(ctx context.Context, client *Client, hrefs []string) error {
+	if client == nil {
+		return ErrNoClient
+	}
+	if client.Cache == nil {
+		return nil
+	}
+
+	errs := make([]error, len(hrefs))
+	slots := make(chan struct{}, DefaultWarmUpConcurrency)
+	var wg sync.WaitGroup
+	for i, href := range hrefs {
+		if !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "https://") {
+			href = client.buildRelativeURL(strings.TrimPrefix(href, "/"))
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(i int, href string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			errs[i] = client.get(ctx, href, &warmUpResource{})
+		}(i, href)
+	}
+	wg.Wait()
+
+	failed := 0
+	var first error
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		Logger.Printf("[WARN] Couldn't warm up the cache [%s] [%s]", err, hrefs[i])
+		if first == nil {
+			first = err
+		}
+		failed++
+	}
+	if first != nil {
+		return fmt.Errorf("cache warm-up failed for %d of %d hrefs: %w", failed, len(hrefs), first)
+	}
+	return nil
+}
+
+//WarmUp preloads the hrefs configured in stormpath.client.cacheManager.warmUp in the client cache, the warm-up
+//stops when ctx is done. Init runs it in the background bounded by DefaultWarmUpTimeout, clients created with
+//NewClient are only warmed up when WarmUp is called.
+//
+//Failures are logged and returned as by WarmUpCacheWithContext, the client works with a cold cache anyway.
+func (client *Client) WarmUp(ctx context.Context) error {
+	if len(client.ClientConfiguration.CacheWarmUp) == 0 {
+		return nil
+	}
+	return WarmUpCacheWithContext(NewContext(ctx, client), client.ClientConfiguration.CacheWarmUp...)
+}
+
+//restoreCacheSnapshot restores the configured cache snapshot, failures are only logged since the client works
+//with a cold cache
+func (client *Client) restoreCacheSnapshot() {
+	path := client.ClientConfiguration.CacheSnapshotFile
+	if client.Cache == nil || path == "" {
+		return
+	}
+
+	restored, err := client.LoadCacheSnapshot(path)
+	if err != nil && !os.IsNotExist(err) {
+		Logger.Printf("[WARN] Couldn't restore the cache snapshot [%s] [%s]", err, path)
+	} else if err == nil {
+		Logger.Printf("[DEBUG] Restored %d cache entries [%s]", restored, path)
+	}
+}
@@ -0,0 +1,105 @@
+package stormpath
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarmUpCache(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		href := "http://" + r.Host + r.URL.Path
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/missing"):
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"status":404,"code":404,"message":"not found"}`)
+		case strings.HasSuffix(r.URL.Path, "/groups"):
+			fmt.Fprintf(w, `{"href":"%s","offset":0,"limit":25,"size":0,"items":[]}`, href)
+		default:
+			fmt.Fprintf(w, `{"href":"%s","name":"warm"}`, href)
+		}
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	ctx, client := newTestContext(config, nil)
+	defer client.Close()
+
+	application := server.URL + "/v1/applications/1?expand=accountStoreMappings(offset:0,limit:25)"
+	err := WarmUpCacheWithContext(ctx, "/accounts/1", application, "accounts/1/groups", "accounts/missing")
+
+	assert.Error(t, err)
+	assert.True(t, IsNotFound(err))
+	assert.Contains(t, err.Error(), "1 of 4")
+
+	u, _ := url.Parse(application)
+	_, applicationKey := cacheKey(u)
+
+	assert.True(t, client.Cache.Exists(server.URL+"/v1/accounts/1"))
+	assert.True(t, client.Cache.Exists(applicationKey))
+	assert.False(t, client.Cache.Exists(server.URL+"/v1/accounts/1/groups"))
+
+	account, err := GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+
+	assert.NoError(t, err)
+	assert.Equal(t, server.URL+"/v1/accounts/1", account.Href)
+	assert.Len(t, server.received(), 4)
+}
+
+func TestClientWarmUp(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/hanging") {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprintf(w, `{"href":"http://%s%s","name":"warm"}`, r.Host, r.URL.Path)
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	config.CacheWarmUp = []string{"accounts/1", "accounts/hanging"}
+	client := NewClient(config, nil)
+	defer client.Close()
+
+	//NewClient doesn't warm up the cache by itself
+	assert.Empty(t, server.received())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := client.WarmUp(ctx)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "1 of 2")
+	assert.True(t, time.Since(start) < time.Second)
+	assert.True(t, client.Cache.Exists(server.URL+"/v1/accounts/1"))
+}
+
+func TestWarmUpCacheWithoutCache(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("warmUpKeyID", "warmUpKeySecret")
+	config.CacheManagerEnabled = false
+	config.BaseURL = "http://127.0.0.1:1/v1/"
+	client := NewClient(config, nil)
+
+	assert.NoError(t, WarmUpCacheWithContext(NewContext(context.Background(), client), "accounts/1"))
+}
+
+func TestWarmUpCacheWithoutClient(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, ErrNoClient, warmUpCache(context.Background(), nil, []string{"accounts/1"}))
+}
@@ -0,0 +1,275 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//Circuit breaker endpoint classes, every class has its own circuit so for example failing
+//login attempts don't stop the reads served by Stormpath
+const (
+	BreakerClassAuthentication = "authentication"
+	BreakerClassRead           = "read"
+	BreakerClassWrite          = "write"
+)
+
+//ErrCircuitOpen is matched by the CircuitOpenError returned without calling Stormpath while a circuit is open,
+//use errors.Is(err, ErrCircuitOpen) or IsCircuitOpen
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+//CircuitOpenError is returned by the calls rejected by an open circuit, Class is the endpoint class of the circuit
+//and RetryAfter how long until the circuit lets a probe request through
+type CircuitOpenError struct {
+	Class      string
+	RetryAfter time.Duration
+}
+
+func (e CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker open for %s requests, retry after %s", e.Class, e.RetryAfter)
+}
+
+//Is matches ErrCircuitOpen
+func (e CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+//IsCircuitOpen returns true if the call was rejected by an open circuit without calling Stormpath
+func IsCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
+
+//BreakerState is the state of a circuit
+type BreakerState int
+
+//Circuit states, a closed circuit lets every request through, an open one rejects them and a half open one
+//lets a single probe request through whose outcome closes or opens the circuit again
+const (
+	BreakerClosed BreakerState = iota
+	BreakerOpen
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+//BreakerStateChange is sent to the breaker observers of a Client every time a circuit changes its state
+type BreakerStateChange struct {
+	Class string
+	From  BreakerState
+	To    BreakerState
+}
+
+//BreakerObserver receives the circuit state changes of a Client, it is called synchronously so it must be fast
+type BreakerObserver func(change BreakerStateChange)
+
+//ObserveBreaker appends the given observers to the client circuit breaker observers, it does nothing if the
+//circuit breaker is disabled.
+//
+//Observers should be configured before the client is used, ObserveBreaker is not safe for concurrent use with
+//requests in flight.
+func (client *Client) ObserveBreaker(observers ...BreakerObserver) {
+	if client.breaker != nil {
+		client.breaker.observers = append(client.breaker.observers, observers...)
+	}
+}
+
+//BreakerState returns the state of the circuit of the given endpoint class, always BreakerClosed if the
+//circuit breaker is disabled
+func (client *Client) BreakerState(class string) BreakerState {
+	if client.breaker == nil {
+		return BreakerClosed
+	}
+
+	client.breaker.mutex.Lock()
+	defer client.breaker.mutex.Unlock()
+
+	if c, ok := client.breaker.circuits[class]; ok {
+		return c.state
+	}
+	return BreakerClosed
+}
+
+//breakerClass returns the endpoint class of the request
+func breakerClass(req *http.Request) string {
+	if strings.HasSuffix(req.URL.Path, "/loginAttempts") || strings.HasSuffix(req.URL.Path, "/oauth/token") {
+		return BreakerClassAuthentication
+	}
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return BreakerClassRead
+	}
+	return BreakerClassWrite
+}
+
+//circuit is the state of the circuit of an endpoint class
+type circuit struct {
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+
+	windowStart    time.Time
+	windowRequests int
+	windowFailures int
+}
+
+//circuitBreaker rejects the requests of an endpoint class when its consecutive failures or its error rate
+//within the window reach the configured thresholds, failures are the network errors and timeouts, throttled
+//requests and 5xx responses
+type circuitBreaker struct {
+	failures    int
+	errorRate   float64
+	minRequests int
+	window      time.Duration
+	openTimeout time.Duration
+
+	mutex     sync.Mutex
+	circuits  map[string]*circuit
+	observers []BreakerObserver
+}
+
+//newCircuitBreaker creates the circuit breaker of the configuration, nil if it is disabled
+func newCircuitBreaker(config ClientConfiguration) *circuitBreaker {
+	if !config.BreakerEnabled {
+		return nil
+	}
+
+	return &circuitBreaker{
+		failures:    config.BreakerFailures,
+		errorRate:   config.BreakerErrorRate,
+		minRequests: config.BreakerMinRequests,
+		window:      config.BreakerWindow,
+		openTimeout: config.BreakerOpenTimeout,
+		circuits:    map[string]*circuit{},
+	}
+}
+
+//allow returns a CircuitOpenError if the circuit of the class rejects the request, otherwise the outcome
+//of the request executed with ctx must be reported with done
+func (breaker *circuitBreaker) allow(ctx context.Context, class string) (done func(err error), err error) {
+	if breaker == nil {
+		return func(error) {}, nil
+	}
+
+	breaker.mutex.Lock()
+	c, ok := breaker.circuits[class]
+	if !ok {
+		c = &circuit{}
+		breaker.circuits[class] = c
+	}
+
+	var changes []BreakerStateChange
+	now := time.Now()
+	if c.state == BreakerOpen {
+		if retryAfter := c.openedAt.Add(breaker.openTimeout).Sub(now); retryAfter > 0 {
+			breaker.mutex.Unlock()
+			return nil, CircuitOpenError{Class: class, RetryAfter: retryAfter}
+		}
+		changes = append(changes, breaker.transition(class, c, BreakerHalfOpen, now))
+	}
+
+	probe := false
+	if c.state == BreakerHalfOpen {
+		if c.probing {
+			breaker.mutex.Unlock()
+			return nil, CircuitOpenError{Class: class}
+		}
+		c.probing, probe = true, true
+	}
+	breaker.mutex.Unlock()
+	breaker.notify(changes)
+
+	return func(err error) {
+		breaker.record(class, c, probe, ctx.Err() != nil, err)
+	}, nil
+}
+
+//record updates the circuit with the outcome of a request it let through, abandoned is true if the ctx of the
+//request is done. Timeouts of requests whose caller didn't give up, such as the transport timeouts, are failures.
+func (breaker *circuitBreaker) record(class string, c *circuit, probe bool, abandoned bool, err error) {
+	breaker.mutex.Lock()
+
+	var changes []BreakerStateChange
+	now := time.Now()
+	failed := err != nil && isUnavailable(err)
+	switch {
+	case abandoned:
+		//The caller gave up, the request tells nothing about Stormpath
+		if probe {
+			c.probing = false
+		}
+	case probe && failed:
+		changes = append(changes, breaker.transition(class, c, BreakerOpen, now))
+	case probe:
+		changes = append(changes, breaker.transition(class, c, BreakerClosed, now))
+	case c.state == BreakerClosed:
+		if now.Sub(c.windowStart) > breaker.window {
+			c.windowStart, c.windowRequests, c.windowFailures = now, 0, 0
+		}
+		c.windowRequests++
+		if failed {
+			c.failures++
+			c.windowFailures++
+		} else {
+			c.failures = 0
+		}
+
+		if breaker.tripped(c) {
+			changes = append(changes, breaker.transition(class, c, BreakerOpen, now))
+		}
+	}
+	breaker.mutex.Unlock()
+	breaker.notify(changes)
+}
+
+//tripped returns true if the closed circuit reached one of the thresholds
+func (breaker *circuitBreaker) tripped(c *circuit) bool {
+	if breaker.failures > 0 && c.failures >= breaker.failures {
+		return true
+	}
+	return breaker.errorRate > 0 &&
+		c.windowRequests >= breaker.minRequests &&
+		float64(c.windowFailures)/float64(c.windowRequests) >= breaker.errorRate
+}
+
+//transition changes the state of the circuit, the mutex must be held
+func (breaker *circuitBreaker) transition(class string, c *circuit, state BreakerState, now time.Time) BreakerStateChange {
+	change := BreakerStateChange{Class: class, From: c.state, To: state}
+
+	c.state = state
+	c.probing = false
+	switch state {
+	case BreakerOpen:
+		c.openedAt = now
+	case BreakerClosed:
+		c.failures = 0
+		c.windowStart, c.windowRequests, c.windowFailures = now, 0, 0
+	}
+
+	return change
+}
+
+//notify sends the state changes to the observers, the mutex must not be held
+func (breaker *circuitBreaker) notify(changes []BreakerStateChange) {
+	for _, change := range changes {
+		if change.To == BreakerOpen {
+			Logger.Printf("[WARN] Circuit breaker open for %s requests", change.Class)
+		}
+		for _, observer := range breaker.observers {
+			observer(change)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = Error{Status: http.StatusServiceUnavailable}
+
+func newTestCircuitBreaker(failures int, errorRate float64, minRequests int, openTimeout time.Duration) *circuitBreaker {
+	config := newDefaultClientConfiguration()
+	config.BreakerEnabled = true
+	config.BreakerFailures = failures
+	config.BreakerErrorRate = errorRate
+	config.BreakerMinRequests = minRequests
+	config.BreakerOpenTimeout = openTimeout
+
+	return newCircuitBreaker(config)
+}
+
+func TestBreakerClass(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		method string
+		url    string
+		class  string
+	}{
+		{http.MethodPost, "https://api.stormpath.com/v1/applications/1/loginAttempts", BreakerClassAuthentication},
+		{http.MethodPost, "https://api.stormpath.com/v1/applications/1/oauth/token", BreakerClassAuthentication},
+		{http.MethodGet, "https://api.stormpath.com/v1/accounts/1", BreakerClassRead},
+		{http.MethodPost, "https://api.stormpath.com/v1/accounts/1", BreakerClassWrite},
+		{http.MethodDelete, "https://api.stormpath.com/v1/accounts/1", BreakerClassWrite},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.url, nil)
+		assert.Equal(t, c.class, breakerClass(req), c.url)
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	t.Parallel()
+
+	var breaker *circuitBreaker
+	assert.Nil(t, newCircuitBreaker(newDefaultClientConfiguration()))
+
+	for i := 0; i < 10; i++ {
+		done, err := breaker.allow(context.Background(), BreakerClassRead)
+		assert.NoError(t, err)
+		done(errUnavailable)
+	}
+}
+
+func TestCircuitBreakerConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+
+	breaker := newTestCircuitBreaker(3, 0, 0, time.Minute)
+
+	//Error responses for the request itself mean Stormpath is available
+	outcomes := []error{errUnavailable, errUnavailable, nil, errUnavailable, errUnavailable, ErrNotFound}
+	outcomes = append(outcomes, errUnavailable, errUnavailable, errUnavailable)
+	for _, err := range outcomes {
+		done, allowErr := breaker.allow(context.Background(), BreakerClassRead)
+		assert.NoError(t, allowErr)
+		done(err)
+	}
+
+	_, err := breaker.allow(context.Background(), BreakerClassRead)
+
+	assert.True(t, IsCircuitOpen(err))
+	assert.Equal(t, BreakerClassRead, err.(CircuitOpenError).Class)
+	assert.True(t, err.(CircuitOpenError).RetryAfter > 50*time.Second)
+
+	_, err = breaker.allow(context.Background(), BreakerClassAuthentication)
+
+	assert.NoError(t, err)
+}
+
+func TestCircuitBreakerErrorRate(t *testing.T) {
+	t.Parallel()
+
+	breaker := newTestCircuitBreaker(0, 0.5, 4, time.Minute)
+
+	for _, err := range []error{errUnavailable, nil, errUnavailable} {
+		done, allowErr := breaker.allow(context.Background(), BreakerClassWrite)
+		assert.NoError(t, allowErr)
+		done(err)
+	}
+	done, err := breaker.allow(context.Background(), BreakerClassWrite)
+	assert.NoError(t, err)
+	done(nil)
+
+	_, err = breaker.allow(context.Background(), BreakerClassWrite)
+
+	assert.True(t, IsCircuitOpen(err))
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	t.Parallel()
+
+	breaker := newTestCircuitBreaker(1, 0, 0, 20*time.Millisecond)
+
+	var mutex sync.Mutex
+	var changes []BreakerStateChange
+	breaker.observers = append(breaker.observers, func(change BreakerStateChange) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		changes = append(changes, change)
+	})
+
+	done, _ := breaker.allow(context.Background(), BreakerClassRead)
+	done(errUnavailable)
+	time.Sleep(30 * time.Millisecond)
+
+	//A single probe is let through at a time
+	canceled, cancel := context.WithCancel(context.Background())
+	probe, err := breaker.allow(canceled, BreakerClassRead)
+	assert.NoError(t, err)
+	_, err = breaker.allow(context.Background(), BreakerClassRead)
+	assert.True(t, IsCircuitOpen(err))
+
+	//A probe abandoned by its caller tells nothing about Stormpath
+	cancel()
+	probe(context.Canceled)
+	probe, err = breaker.allow(context.Background(), BreakerClassRead)
+	assert.NoError(t, err)
+
+	probe(errUnavailable)
+	_, err = breaker.allow(context.Background(), BreakerClassRead)
+	assert.True(t, IsCircuitOpen(err))
+
+	time.Sleep(30 * time.Millisecond)
+	probe, _ = breaker.allow(context.Background(), BreakerClassRead)
+	probe(nil)
+	done, err = breaker.allow(context.Background(), BreakerClassRead)
+	assert.NoError(t, err)
+	done(nil)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []BreakerStateChange{
+		{BreakerClassRead, BreakerClosed, BreakerOpen},
+		{BreakerClassRead, BreakerOpen, BreakerHalfOpen},
+		{BreakerClassRead, BreakerHalfOpen, BreakerOpen},
+		{BreakerClassRead, BreakerOpen, BreakerHalfOpen},
+		{BreakerClassRead, BreakerHalfOpen, BreakerClosed},
+	}, changes)
+}
+
+func TestCircuitBreakerTransportTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.ConnectionTimeout = 1
+	config.BreakerEnabled = true
+	config.BreakerFailures = 2
+	ctx, client := newTestContext(config, nil)
+	href := server.URL + "/v1/accounts/1"
+
+	for i := 0; i < 2; i++ {
+		_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+		assert.Error(t, err)
+	}
+
+	assert.Equal(t, BreakerOpen, client.BreakerState(BreakerClassRead))
+
+	start := time.Now()
+	_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.True(t, IsCircuitOpen(err))
+	assert.True(t, time.Since(start) < 100*time.Millisecond)
+	assert.Len(t, server.received(), 2)
+
+	//Requests abandoned by their caller aren't failures
+	timeout, cancel := context.WithTimeout(NewContext(context.Background(), client), 50*time.Millisecond)
+	defer cancel()
+	client.breaker.circuits[BreakerClassRead] = &circuit{}
+	for i := 0; i < 2; i++ {
+		GetAccountWithContext(timeout, href, MakeAccountCriteria())
+	}
+
+	assert.Equal(t, BreakerClosed, client.BreakerState(BreakerClassRead))
+}
+
+func TestClientCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	var status int32 = http.StatusServiceUnavailable
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(atomic.LoadInt32(&status)))
+		w.Write([]byte(`{"href":"` + r.URL.Path + `","username":"john"}`))
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.BreakerEnabled = true
+	config.BreakerFailures = 2
+	config.BreakerOpenTimeout = 50 * time.Millisecond
+	ctx, client := newTestContext(config, nil)
+	href := server.URL + "/v1/accounts/1"
+
+	var opened int32
+	client.ObserveBreaker(func(change BreakerStateChange) {
+		if change.To == BreakerOpen {
+			atomic.AddInt32(&opened, 1)
+		}
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+		assert.Error(t, err)
+	}
+	_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.True(t, errors.Is(err, ErrCircuitOpen))
+	assert.Len(t, server.received(), 2)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&opened))
+	assert.Equal(t, BreakerOpen, client.BreakerState(BreakerClassRead))
+	assert.Equal(t, BreakerClosed, client.BreakerState(BreakerClassWrite))
+
+	time.Sleep(60 * time.Millisecond)
+	atomic.StoreInt32(&status, http.StatusOK)
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "john", account.Username)
+	assert.Equal(t, BreakerClosed, client.BreakerState(BreakerClassRead))
+}
@@ -21,15 +21,42 @@ stormpath:
       enabled: true
       defaultTtl: 300 # seconds
       defaultTti: 300
+      maxEntries: 0 # 0 means unbounded, split evenly between the cache regions
+      maxBytes: 0 # 0 means unbounded
+      staleWhileRevalidate: 0 # seconds an expired entry is served while it is refreshed in the background, 0 disables it
+      staleIfError: 0 # seconds an expired entry is served when Stormpath is unreachable, 0 disables it
+      encryption:
+        keys: [] # id:base64key AES keys, the first one encrypts the cached values, empty disables encryption
+        namespace: null # defaults to the API key ID
+      warmUp: [] # hrefs, absolute or relative to the baseUrl with an optional expand query, preloaded by Init and client.WarmUp
+      snapshotFile: null # file the cache created by NewClient is restored from and saved to by client.Close
       caches: #Per resource cacehe config
+        accounts: # accounts, groups, directories, applications, organizations, customData or apiKeys
+          ttl: 30 # seconds
+          tti: 30
     baseUrl: "https://api.stormpath.com/v1"
     connectionTimeout: 30 # seconds
-    authenticationScheme: "SAUTHC1"
+    authenticationScheme: "SAUTHC1" # SAUTHC1 or BASIC
+    retry:
+      maxAttempts: 1 # 1 disables retries
+      baseDelay: 100 # milliseconds
+      maxDelay: 5000 # milliseconds, a longer Retry-After isn't retried
+    circuitBreaker: # one circuit per endpoint class, authentication (loginAttempts, oauth/token), read and write
+      enabled: false
+      failureThreshold: 5 # consecutive failures opening a circuit, 0 disables it
+      errorRate: 0.5 # failure ratio within the window opening a circuit, 0 disables it
+      minRequests: 20 # requests within the window before the error rate is considered
+      window: 60 # seconds
+      openTimeout: 30 # seconds before an open circuit lets a probe request through
     proxy:
       port: null
       host: null
       username: null
       password: null
+    tls:
+      caFile: null # PEM CA bundle use to verify the server certificate
+      certFile: null # PEM client certificate for mutual TLS
+      keyFile: null
 */
 
 //ClientConfiguration representd the overall SDK configuration options
@@ -40,6 +67,15 @@ type ClientConfiguration struct {
 	CacheManagerEnabled  bool
 	CacheTTL             time.Duration
 	CacheTTI             time.Duration
+	CacheMaxEntries      int
+	CacheMaxBytes        int64
+	CacheRegions         map[string]CacheRegionConfiguration
+	CacheStaleRevalidate time.Duration
+	CacheStaleIfError    time.Duration
+	CacheEncryptionKeys  []CacheEncryptionKey
+	CacheNamespace       string
+	CacheWarmUp          []string
+	CacheSnapshotFile    string
 	BaseURL              string
 	ConnectionTimeout    int
 	AuthenticationScheme string
@@ -47,6 +83,18 @@ type ClientConfiguration struct {
 	ProxyHost            string
 	ProxyUsername        string
 	ProxyPassword        string
+	RetryMaxAttempts     int
+	RetryBaseDelay       time.Duration
+	RetryMaxDelay        time.Duration
+	BreakerEnabled       bool
+	BreakerFailures      int
+	BreakerErrorRate     float64
+	BreakerMinRequests   int
+	BreakerWindow        time.Duration
+	BreakerOpenTimeout   time.Duration
+	TLSCAFile            string
+	TLSCertFile          string
+	TLSKeyFile           string
 }
 
 //LoadConfiguration loads the configuration from the default locations
@@ -83,6 +131,43 @@ func LoadConfiguration() (ClientConfiguration, error) {
 	if v.Get("stormpath.client.cacheManager.defaultTti") != nil {
 		c.CacheTTI = time.Duration(v.GetInt("stormpath.client.cacheManager.defaultTti")) * time.Second
 	}
+	for _, name := range CacheRegions {
+		region := "stormpath.client.cacheManager.caches." + name
+		if v.Get(region+".ttl") == nil && v.Get(region+".tti") == nil {
+			continue
+		}
+		//A region only overriding one of the values keeps the default of the other one
+		config := CacheRegionConfiguration{TTL: c.CacheTTL, TTI: c.CacheTTI}
+		if v.Get(region+".ttl") != nil {
+			config.TTL = time.Duration(v.GetInt(region+".ttl")) * time.Second
+		}
+		if v.Get(region+".tti") != nil {
+			config.TTI = time.Duration(v.GetInt(region+".tti")) * time.Second
+		}
+		c.CacheRegions[name] = config
+	}
+	if v.Get("stormpath.client.cacheManager.staleWhileRevalidate") != nil {
+		c.CacheStaleRevalidate = time.Duration(v.GetInt("stormpath.client.cacheManager.staleWhileRevalidate")) * time.Second
+	}
+	if v.Get("stormpath.client.cacheManager.staleIfError") != nil {
+		c.CacheStaleIfError = time.Duration(v.GetInt("stormpath.client.cacheManager.staleIfError")) * time.Second
+	}
+	for _, value := range v.GetStringSlice("stormpath.client.cacheManager.encryption.keys") {
+		key, err := ParseCacheEncryptionKey(value)
+		if err != nil {
+			return c, err
+		}
+		c.CacheEncryptionKeys = append(c.CacheEncryptionKeys, key)
+	}
+	c.CacheNamespace = v.GetString("stormpath.client.cacheManager.encryption.namespace")
+	c.CacheWarmUp = v.GetStringSlice("stormpath.client.cacheManager.warmUp")
+	c.CacheSnapshotFile = v.GetString("stormpath.client.cacheManager.snapshotFile")
+	if v.Get("stormpath.client.cacheManager.maxEntries") != nil {
+		c.CacheMaxEntries = v.GetInt("stormpath.client.cacheManager.maxEntries")
+	}
+	if v.Get("stormpath.client.cacheManager.maxBytes") != nil {
+		c.CacheMaxBytes = int64(v.GetInt("stormpath.client.cacheManager.maxBytes"))
+	}
 
 	if v.GetString("stormpath.client.baseUrl") != "" {
 		c.BaseURL = v.GetString("stormpath.client.baseUrl")
@@ -95,11 +180,44 @@ func LoadConfiguration() (ClientConfiguration, error) {
 		c.AuthenticationScheme = v.GetString("stormpath.client.authenticationScheme")
 	}
 
+	if v.Get("stormpath.client.retry.maxAttempts") != nil {
+		c.RetryMaxAttempts = v.GetInt("stormpath.client.retry.maxAttempts")
+	}
+	if v.Get("stormpath.client.retry.baseDelay") != nil {
+		c.RetryBaseDelay = time.Duration(v.GetInt("stormpath.client.retry.baseDelay")) * time.Millisecond
+	}
+	if v.Get("stormpath.client.retry.maxDelay") != nil {
+		c.RetryMaxDelay = time.Duration(v.GetInt("stormpath.client.retry.maxDelay")) * time.Millisecond
+	}
+
+	if v.Get("stormpath.client.circuitBreaker.enabled") != nil {
+		c.BreakerEnabled = v.GetBool("stormpath.client.circuitBreaker.enabled")
+	}
+	if v.Get("stormpath.client.circuitBreaker.failureThreshold") != nil {
+		c.BreakerFailures = v.GetInt("stormpath.client.circuitBreaker.failureThreshold")
+	}
+	if v.Get("stormpath.client.circuitBreaker.errorRate") != nil {
+		c.BreakerErrorRate = v.GetFloat64("stormpath.client.circuitBreaker.errorRate")
+	}
+	if v.Get("stormpath.client.circuitBreaker.minRequests") != nil {
+		c.BreakerMinRequests = v.GetInt("stormpath.client.circuitBreaker.minRequests")
+	}
+	if v.Get("stormpath.client.circuitBreaker.window") != nil {
+		c.BreakerWindow = time.Duration(v.GetInt("stormpath.client.circuitBreaker.window")) * time.Second
+	}
+	if v.Get("stormpath.client.circuitBreaker.openTimeout") != nil {
+		c.BreakerOpenTimeout = time.Duration(v.GetInt("stormpath.client.circuitBreaker.openTimeout")) * time.Second
+	}
+
 	c.ProxyHost = v.GetString("stormpath.client.proxy.host")
 	c.ProxyPort = v.GetInt("stormpath.client.proxy.port")
 	c.ProxyUsername = v.GetString("stormpath.client.proxy.username")
 	c.ProxyPassword = v.GetString("stormpath.client.proxy.password")
 
+	c.TLSCAFile = v.GetString("stormpath.client.tls.caFile")
+	c.TLSCertFile = v.GetString("stormpath.client.tls.certFile")
+	c.TLSKeyFile = v.GetString("stormpath.client.tls.keyFile")
+
 	return c, nil
 }
 
@@ -148,6 +266,15 @@ func newDefaultClientConfiguration() (c ClientConfiguration) {
 		CacheManagerEnabled:  true,
 		CacheTTI:             300 * time.Second,
 		CacheTTL:             300 * time.Second,
+		CacheMaxEntries:      0,
+		CacheMaxBytes:        0,
+		CacheRegions:         map[string]CacheRegionConfiguration{},
+		CacheStaleRevalidate: 0,
+		CacheStaleIfError:    0,
+		CacheEncryptionKeys:  nil,
+		CacheNamespace:       "",
+		CacheWarmUp:          nil,
+		CacheSnapshotFile:    "",
 		BaseURL:              "https://api.stormpath.com/v1/",
 		ConnectionTimeout:    30,
 		AuthenticationScheme: "SAUTHC1",
@@ -155,5 +282,17 @@ func newDefaultClientConfiguration() (c ClientConfiguration) {
 		ProxyPort:            0,
 		ProxyUsername:        "",
 		ProxyPassword:        "",
+		RetryMaxAttempts:     1,
+		RetryBaseDelay:       100 * time.Millisecond,
+		RetryMaxDelay:        5 * time.Second,
+		BreakerEnabled:       false,
+		BreakerFailures:      5,
+		BreakerErrorRate:     0.5,
+		BreakerMinRequests:   20,
+		BreakerWindow:        60 * time.Second,
+		BreakerOpenTimeout:   30 * time.Second,
+		TLSCAFile:            "",
+		TLSCertFile:          "",
+		TLSKeyFile:           "",
 	}
 }
@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 type customDataAwareResource struct {
 	resource
 	CustomData *CustomData `json:"customData,omitempty"`
@@ -16,24 +18,36 @@ func (customData CustomData) IsCacheable() bool {
 //
 //See: http://docs.stormpath.com/rest/product-guide/#custom-data
 func (r *customDataAwareResource) GetCustomData() (CustomData, error) {
+	return r.GetCustomDataWithContext(r.clientContext())
+}
+
+//GetCustomDataWithContext is the same as GetCustomData with the addition of a context.Context
+func (r *customDataAwareResource) GetCustomDataWithContext(ctx context.Context) (CustomData, error) {
+	ctx = r.withClient(ctx)
 	customData := make(CustomData)
 
-	err := client.get(buildAbsoluteURL(r.Href, "customData"), &customData)
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(r.Href, "customData"), &customData)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return customData, nil
+	return customData, err
 }
 
 //UpdateCustomData sets or updates the given resource custom data
 //
 //See: http://docs.stormpath.com/rest/product-guide/#custom-data
 func (r *customDataAwareResource) UpdateCustomData(customData CustomData) (CustomData, error) {
+	return r.UpdateCustomDataWithContext(r.clientContext(), customData)
+}
+
+//UpdateCustomDataWithContext is the same as UpdateCustomData with the addition of a context.Context
+func (r *customDataAwareResource) UpdateCustomDataWithContext(ctx context.Context, customData CustomData) (CustomData, error) {
+	ctx = r.withClient(ctx)
 	customData = cleanCustomData(customData)
 
-	err := client.post(buildAbsoluteURL(r.Href, "customData"), customData, &customData)
+	err := ClientFromContext(ctx).post(ctx, buildAbsoluteURL(r.Href, "customData"), customData, &customData)
 
 	if err != nil {
 		return nil, err
@@ -46,5 +60,11 @@ func (r *customDataAwareResource) UpdateCustomData(customData CustomData) (Custo
 //
 //See: http://docs.stormpath.com/rest/product-guide/#custom-data
 func (r *customDataAwareResource) DeleteCustomData() error {
-	return client.delete(buildAbsoluteURL(r.Href, "customData"))
+	return r.DeleteCustomDataWithContext(r.clientContext())
+}
+
+//DeleteCustomDataWithContext is the same as DeleteCustomData with the addition of a context.Context
+func (r *customDataAwareResource) DeleteCustomDataWithContext(ctx context.Context) error {
+	ctx = r.withClient(ctx)
+	return ClientFromContext(ctx).delete(ctx, buildAbsoluteURL(r.Href, "customData"))
 }
@@ -1,5 +1,10 @@
 package stormpath
 
+import (
+	"context"
+	"io"
+)
+
 const (
 	Facebook = "facebook"
 	Google   = "google"
@@ -85,79 +90,190 @@ func NewLinkedInDirectory(name string, clientID string, clientSecret string, red
 //
 //See: http://docs.stormpath.com/rest/product-guide/#tenant-directories
 func CreateDirectory(dir *Directory) error {
-	return client.post(buildRelativeURL("directories"), dir, dir)
+	return CreateDirectoryWithContext(context.Background(), dir)
+}
+
+//CreateDirectoryWithContext is the same as CreateDirectory with the addition of a context.Context
+func CreateDirectoryWithContext(ctx context.Context, dir *Directory) error {
+	c := ClientFromContext(ctx)
+	return c.post(ctx, c.buildRelativeURL("directories"), dir, dir)
 }
 
 //GetDirectory loads a directory by href and criteria
 func GetDirectory(href string, criteria DirectoryCriteria) (*Directory, error) {
+	return GetDirectoryWithContext(context.Background(), href, criteria)
+}
+
+//GetDirectoryWithContext is the same as GetDirectory with the addition of a context.Context
+func GetDirectoryWithContext(ctx context.Context, href string, criteria DirectoryCriteria) (*Directory, error) {
 	directory := &Directory{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		directory,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return directory, nil
+	return directory, err
 }
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (dir *Directory) Refresh() error {
-	return client.get(dir.Href, dir)
+	return dir.RefreshWithContext(dir.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (dir *Directory) RefreshWithContext(ctx context.Context) error {
+	ctx = dir.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, dir.Href, dir)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (dir *Directory) Update() error {
-	return client.post(dir.Href, dir, dir)
+	return dir.UpdateWithContext(dir.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (dir *Directory) UpdateWithContext(ctx context.Context) error {
+	ctx = dir.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, dir.Href, dir, dir)
 }
 
 //GetAccountCreationPolicy loads the directory account creation policy
 func (dir *Directory) GetAccountCreationPolicy() (*AccountCreationPolicy, error) {
-	err := client.get(buildAbsoluteURL(dir.AccountCreationPolicy.Href), dir.AccountCreationPolicy)
+	return dir.GetAccountCreationPolicyWithContext(dir.clientContext())
+}
 
-	if err != nil {
+//GetAccountCreationPolicyWithContext is the same as GetAccountCreationPolicy with the addition of a context.Context
+func (dir *Directory) GetAccountCreationPolicyWithContext(ctx context.Context) (*AccountCreationPolicy, error) {
+	ctx = dir.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(dir.AccountCreationPolicy.Href), dir.AccountCreationPolicy)
+
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return dir.AccountCreationPolicy, nil
+	return dir.AccountCreationPolicy, err
 }
 
 //GetGroups returns all the groups from a directory
 func (dir *Directory) GetGroups(criteria GroupCriteria) (*Groups, error) {
-	err := client.get(
+	return dir.GetGroupsWithContext(dir.clientContext(), criteria)
+}
+
+//GetGroupsWithContext is the same as GetGroups with the addition of a context.Context
+func (dir *Directory) GetGroupsWithContext(ctx context.Context, criteria GroupCriteria) (*Groups, error) {
+	ctx = dir.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(dir.Groups.Href, criteria.toQueryString()),
 		dir.Groups,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return dir.Groups, nil
+	return dir.Groups, err
+}
+
+//IterateGroups returns a GroupIterator over all the groups associated with the Directory, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (dir *Directory) IterateGroups(criteria GroupCriteria) *GroupIterator {
+	return dir.IterateGroupsWithContext(dir.clientContext(), criteria)
+}
+
+//IterateGroupsWithContext is the same as IterateGroups with the addition of a context.Context
+func (dir *Directory) IterateGroupsWithContext(ctx context.Context, criteria GroupCriteria) *GroupIterator {
+	ctx = dir.withClient(ctx)
+	return newGroupIterator(ctx, dir.Groups.Href, criteria.baseCriteria)
 }
 
 //CreateGroup creates a new group in the directory
 func (dir *Directory) CreateGroup(group *Group) error {
-	return client.post(dir.Groups.Href, group, group)
+	return dir.CreateGroupWithContext(dir.clientContext(), group)
+}
+
+//CreateGroupWithContext is the same as CreateGroup with the addition of a context.Context
+func (dir *Directory) CreateGroupWithContext(ctx context.Context, group *Group) error {
+	ctx = dir.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, dir.Groups.Href, group, group)
 }
 
 //RegisterAccount registers a new account into the directory
 //
 //See: http://docs.stormpath.com/rest/product-guide/#directory-accounts
 func (dir *Directory) RegisterAccount(account *Account) error {
-	return client.post(dir.Accounts.Href, account, account)
+	return dir.RegisterAccountWithContext(dir.clientContext(), account)
+}
+
+//RegisterAccountWithContext is the same as RegisterAccount with the addition of a context.Context
+func (dir *Directory) RegisterAccountWithContext(ctx context.Context, account *Account) error {
+	ctx = dir.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, dir.Accounts.Href, account, account)
+}
+
+//BulkRegisterAccounts registers the given accounts into the directory with a pool of workers,
+//setting their custom data and adding them to their groups.
+//
+//It returns a report with the result of every account, a failed account doesn't stop the job.
+func (dir *Directory) BulkRegisterAccounts(accounts []BulkAccount, options BulkOptions) (*BulkReport, error) {
+	return dir.BulkRegisterAccountsWithContext(dir.clientContext(), accounts, options)
+}
+
+//BulkRegisterAccountsWithContext is the same as BulkRegisterAccounts with the addition of a context.Context
+func (dir *Directory) BulkRegisterAccountsWithContext(ctx context.Context, accounts []BulkAccount, options BulkOptions) (*BulkReport, error) {
+	ctx = dir.withClient(ctx)
+	return dir.BulkRegisterAccountStreamWithContext(ctx, bulkAccountsChannel(accounts), options)
+}
+
+//BulkRegisterAccountStream is the same as BulkRegisterAccounts but reads the accounts from the given channel until it is closed
+func (dir *Directory) BulkRegisterAccountStream(accounts <-chan BulkAccount, options BulkOptions) (*BulkReport, error) {
+	return dir.BulkRegisterAccountStreamWithContext(dir.clientContext(), accounts, options)
+}
+
+//BulkRegisterAccountStreamWithContext is the same as BulkRegisterAccountStream with the addition of a context.Context,
+//when it is done the job stops and the report of the accounts processed so far is returned with the context error
+func (dir *Directory) BulkRegisterAccountStreamWithContext(ctx context.Context, accounts <-chan BulkAccount, options BulkOptions) (*BulkReport, error) {
+	ctx = dir.withClient(ctx)
+	return bulkRegisterAccounts(ctx, dir.Accounts.Href, dir.RegisterAccountWithContext, accounts, options)
+}
+
+//ImportAccounts creates the accounts read from r in the given format into the directory as a bulk job.
+//
+//Passwords must be hashes in Modular Crypt Format (bcrypt or Stormpath PBKDF2), the accounts are created
+//with passwordFormat=mcf so the users keep their current passwords. Groups are looked up by name in the directory
+//and created if missing. It returns the per account report, a failed account doesn't stop the import.
+//
+//See: https://docs.stormpath.com/rest/product-guide/latest/accnt_mgmt.html#importing-accounts-with-mcf-hash-passwords
+func (dir *Directory) ImportAccounts(r io.Reader, format AccountFormat, options BulkOptions) (*BulkReport, error) {
+	return dir.ImportAccountsWithContext(dir.clientContext(), r, format, options)
+}
+
+//ImportAccountsWithContext is the same as ImportAccounts with the addition of a context.Context
+func (dir *Directory) ImportAccountsWithContext(ctx context.Context, r io.Reader, format AccountFormat, options BulkOptions) (*BulkReport, error) {
+	ctx = dir.withClient(ctx)
+	return importAccounts(ctx, dir, r, format, options)
 }
 
 //RegisterSocialAccount registers a new account into the application using an external provider Google, Facebook
 //
 //See: http://docs.stormpath.com/rest/product-guide/#accessing-accounts-with-google-authorization-codes-or-an-access-tokens
 func (dir *Directory) RegisterSocialAccount(socialAccount *SocialAccount) (*Account, error) {
+	return dir.RegisterSocialAccountWithContext(dir.clientContext(), socialAccount)
+}
+
+//RegisterSocialAccountWithContext is the same as RegisterSocialAccount with the addition of a context.Context
+func (dir *Directory) RegisterSocialAccountWithContext(ctx context.Context, socialAccount *SocialAccount) (*Account, error) {
+	ctx = dir.withClient(ctx)
 	account := &Account{}
 
-	err := client.post(dir.Accounts.Href, socialAccount, account)
+	err := ClientFromContext(ctx).post(ctx, dir.Accounts.Href, socialAccount, account)
 
 	if err != nil {
 		return nil, err
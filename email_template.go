@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //EmailTemplate represents an account creation policy email template
 type EmailTemplate struct {
 	resource
@@ -20,26 +22,44 @@ type EmailTemplates struct {
 
 //GetEmailTemplate loads an email template by href
 func GetEmailTemplate(href string) (*EmailTemplate, error) {
+	return GetEmailTemplateWithContext(context.Background(), href)
+}
+
+//GetEmailTemplateWithContext is the same as GetEmailTemplate with the addition of a context.Context
+func GetEmailTemplateWithContext(ctx context.Context, href string) (*EmailTemplate, error) {
 	emailTemplate := &EmailTemplate{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		href,
 		emailTemplate,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return emailTemplate, nil
+	return emailTemplate, err
 }
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (template *EmailTemplate) Refresh() error {
-	return client.get(template.Href, template)
+	return template.RefreshWithContext(template.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (template *EmailTemplate) RefreshWithContext(ctx context.Context) error {
+	ctx = template.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, template.Href, template)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (template *EmailTemplate) Update() error {
-	return client.post(template.Href, template, template)
+	return template.UpdateWithContext(template.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (template *EmailTemplate) UpdateWithContext(ctx context.Context) error {
+	ctx = template.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, template.Href, template, template)
 }
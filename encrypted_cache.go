@@ -0,0 +1,241 @@
+package stormpath
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+//encryptedValueVersion is the first byte of the values stored by an EncryptedCache, it is followed by the
+//length of the key ID, the key ID, the nonce and the sealed data
+const encryptedValueVersion = 1
+
+//CacheEncryptionKey is an AES-128, AES-192 or AES-256 key used to encrypt cache values, ID is stored with every
+//value so the values encrypted with a previous key can still be read after a key rotation
+type CacheEncryptionKey struct {
+	ID  string
+	Key []byte
+}
+
+//ParseCacheEncryptionKey parses a key in the id:base64key format of stormpath.client.cacheManager.encryption.keys
+func ParseCacheEncryptionKey(value string) (CacheEncryptionKey, error) {
+	parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+	if len(parts) != 2 {
+		return CacheEncryptionKey{}, fmt.Errorf("cache encryption key must be in the id:base64key format")
+	}
+
+	key, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return CacheEncryptionKey{}, fmt.Errorf("cache encryption key %q: %s", parts[0], err)
+	}
+	return CacheEncryptionKey{ID: parts[0], Key: key}, nil
+}
+
+//EncryptedCache is a Cache wrapper encrypting the cached values with AES-GCM before storing them in the wrapped cache,
+//so secrets such as API key secrets, provider access tokens or custom data are never stored in clear in a shared backend.
+//
+//The first key encrypts the new values and every key decrypts, to rotate keys put the new key first and drop the old
+//one once the values encrypted with it expired. Values that can't be decrypted, because their key was dropped or they
+//were tampered with, are cache misses.
+//
+//Cache keys are namespaced, the namespace is appended to every key as a URL fragment so href based routing such as
+//cache regions keeps working. Values are bound to their namespaced key, so clients with different namespaces,
+//e.g. one per tenant or API key, can safely share one backend.
+type EncryptedCache struct {
+	cache     Cache
+	namespace string
+	primary   string
+	aeads     map[string]cipher.AEAD
+}
+
+//NewEncryptedCache creates an EncryptedCache storing the values in the given cache under the given namespace,
+//the first of the keys is used to encrypt the values
+func NewEncryptedCache(cache Cache, namespace string, keys ...CacheEncryptionKey) (*EncryptedCache, error) {
+	InitLog()
+
+	if len(keys) == 0 {
+		return nil, errors.New("at least one cache encryption key is required")
+	}
+
+	encrypted := &EncryptedCache{
+		cache:     cache,
+		namespace: namespace,
+		primary:   keys[0].ID,
+		aeads:     map[string]cipher.AEAD{},
+	}
+	for _, key := range keys {
+		if key.ID == "" || len(key.ID) > 255 {
+			return nil, fmt.Errorf("cache encryption key ID %q must have between 1 and 255 bytes", key.ID)
+		}
+		if _, exists := encrypted.aeads[key.ID]; exists {
+			return nil, fmt.Errorf("duplicated cache encryption key ID %q", key.ID)
+		}
+
+		block, err := aes.NewCipher(key.Key)
+		if err != nil {
+			return nil, fmt.Errorf("cache encryption key %q: %s", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		encrypted.aeads[key.ID] = aead
+	}
+
+	return encrypted, nil
+}
+
+func (cache *EncryptedCache) Exists(key string) bool {
+	_, ok := cache.GetIfPresent(key)
+	return ok
+}
+
+func (cache *EncryptedCache) Set(key string, data []byte) {
+	value, err := cache.encrypt(cache.key(key), data)
+	if err != nil {
+		Logger.Printf("[ERROR] Couldn't encrypt cache value [%s]", err)
+		return
+	}
+	cache.cache.Set(cache.key(key), value)
+}
+
+//SetWithTTL encrypts the data and stores it with the given TTL if the wrapped cache implements TTLSetter
+func (cache *EncryptedCache) SetWithTTL(key string, data []byte, ttl time.Duration) {
+	value, err := cache.encrypt(cache.key(key), data)
+	if err != nil {
+		Logger.Printf("[ERROR] Couldn't encrypt cache value [%s]", err)
+		return
+	}
+	setWithTTL(cache.cache, cache.key(key), value, ttl)
+}
+
+func (cache *EncryptedCache) Get(key string) []byte {
+	data, _ := cache.GetIfPresent(key)
+	return data
+}
+
+func (cache *EncryptedCache) GetIfPresent(key string) ([]byte, bool) {
+	value, ok := getIfPresent(cache.cache, cache.key(key))
+	if !ok {
+		return []byte{}, false
+	}
+
+	data, err := cache.decrypt(cache.key(key), value)
+	if err != nil {
+		Logger.Printf("[WARN] Couldn't decrypt cache value, ignoring it [%s]", err)
+		return []byte{}, false
+	}
+	return data, true
+}
+
+func (cache *EncryptedCache) Del(key string) {
+	cache.cache.Del(cache.key(key))
+}
+
+//Close closes the wrapped cache if it implements io.Closer
+func (cache *EncryptedCache) Close() error {
+	if closer, ok := cache.cache.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+//Evictions returns the evictions of the wrapped cache if it implements CacheEvictionCounter
+func (cache *EncryptedCache) Evictions() uint64 {
+	if counter, ok := cache.cache.(CacheEvictionCounter); ok {
+		return counter.Evictions()
+	}
+	return 0
+}
+
+//Expirations returns the expirations of the wrapped cache if it implements CacheEvictionCounter
+func (cache *EncryptedCache) Expirations() uint64 {
+	if counter, ok := cache.cache.(CacheEvictionCounter); ok {
+		return counter.Expirations()
+	}
+	return 0
+}
+
+//Entries returns the entries of the namespace if the wrapped cache implements CacheSnapshotter,
+//the values stay encrypted
+func (cache *EncryptedCache) Entries() []CacheEntry {
+	snapshotter, ok := cache.cache.(CacheSnapshotter)
+	if !ok {
+		return nil
+	}
+
+	var entries []CacheEntry
+	for _, entry := range snapshotter.Entries() {
+		if cache.namespace == "" || strings.HasSuffix(entry.Key, "#"+cache.namespace) {
+			entry.Key = strings.TrimSuffix(entry.Key, "#"+cache.namespace)
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+//Restore restores the encrypted entries in the namespace if the wrapped cache implements CacheSnapshotter
+func (cache *EncryptedCache) Restore(entries []CacheEntry) int {
+	snapshotter, ok := cache.cache.(CacheSnapshotter)
+	if !ok {
+		return 0
+	}
+
+	namespaced := make([]CacheEntry, len(entries))
+	for i, entry := range entries {
+		entry.Key = cache.key(entry.Key)
+		namespaced[i] = entry
+	}
+	return snapshotter.Restore(namespaced)
+}
+
+//key returns the namespaced key of the cache key
+func (cache *EncryptedCache) key(key string) string {
+	if cache.namespace == "" {
+		return key
+	}
+	return key + "#" + cache.namespace
+}
+
+//encrypt seals the data with the primary key, the namespaced key is authenticated with it so a value
+//can't be replayed under another key or namespace
+func (cache *EncryptedCache) encrypt(key string, data []byte) ([]byte, error) {
+	aead := cache.aeads[cache.primary]
+
+	value := make([]byte, 0, 2+len(cache.primary)+aead.NonceSize()+len(data)+aead.Overhead())
+	value = append(value, encryptedValueVersion, byte(len(cache.primary)))
+	value = append(value, cache.primary...)
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	value = append(value, nonce...)
+
+	return aead.Seal(value, nonce, data, []byte(key)), nil
+}
+
+//decrypt opens the value with the key it was encrypted with
+func (cache *EncryptedCache) decrypt(key string, value []byte) ([]byte, error) {
+	if len(value) < 2 || value[0] != encryptedValueVersion || len(value) < 2+int(value[1]) {
+		return nil, errors.New("invalid encrypted cache value")
+	}
+	id := string(value[2 : 2+int(value[1])])
+	value = value[2+int(value[1]):]
+
+	aead, ok := cache.aeads[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown cache encryption key %q", id)
+	}
+	if len(value) < aead.NonceSize() {
+		return nil, errors.New("invalid encrypted cache value")
+	}
+
+	return aead.Open(nil, value[:aead.NonceSize()], value[aead.NonceSize():], []byte(key))
+}
@@ -0,0 +1,154 @@
+package stormpath
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testEncryptionKey(id string, size int) CacheEncryptionKey {
+	return CacheEncryptionKey{ID: id, Key: bytes.Repeat([]byte(id[:1]), size)}
+}
+
+func TestEncryptedCacheRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+	cache, err := NewEncryptedCache(backend, "tenant", testEncryptionKey("k1", 32))
+	assert.NoError(t, err)
+
+	data := []byte(`{"apiKeySecret":"secret"}`)
+	cache.Set(key, data)
+
+	assert.True(t, cache.Exists(key))
+	assert.Equal(t, data, cache.Get(key))
+	assert.False(t, backend.Exists(key))
+	assert.True(t, backend.Exists(key+"#tenant"))
+	assert.False(t, bytes.Contains(backend.Get(key+"#tenant"), []byte("secret")))
+
+	cache.Del(key)
+
+	assert.False(t, cache.Exists(key))
+	assert.False(t, backend.Exists(key+"#tenant"))
+}
+
+func TestEncryptedCacheNamespaces(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+	tenant1, _ := NewEncryptedCache(backend, "tenant1", testEncryptionKey("k1", 16))
+	tenant2, _ := NewEncryptedCache(backend, "tenant2", testEncryptionKey("k1", 16))
+
+	tenant1.Set(key, []byte("tenant1"))
+
+	assert.False(t, tenant2.Exists(key))
+
+	//A value copied under another namespace doesn't authenticate
+	backend.Set(key+"#tenant2", backend.Get(key+"#tenant1"))
+
+	data, ok := tenant2.GetIfPresent(key)
+	assert.False(t, ok)
+	assert.Empty(t, data)
+}
+
+func TestEncryptedCacheKeyRotation(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+	old, _ := NewEncryptedCache(backend, "", testEncryptionKey("old", 24))
+	old.Set(key, []byte("data"))
+
+	rotated, err := NewEncryptedCache(backend, "", testEncryptionKey("new", 32), testEncryptionKey("old", 24))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []byte("data"), rotated.Get(key))
+
+	rotated.Set(key, []byte("rotated"))
+
+	assert.False(t, old.Exists(key))
+
+	dropped, _ := NewEncryptedCache(backend, "", testEncryptionKey("new", 32))
+	assert.Equal(t, []byte("rotated"), dropped.Get(key))
+}
+
+func TestEncryptedCacheTamperedValue(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+	cache, _ := NewEncryptedCache(backend, "", testEncryptionKey("k1", 16))
+	cache.Set(key, []byte("data"))
+
+	value := backend.Get(key)
+	value[len(value)-1] ^= 1
+	backend.Set(key, value)
+
+	assert.False(t, cache.Exists(key))
+
+	backend.Set(key, []byte("clear"))
+
+	assert.False(t, cache.Exists(key))
+}
+
+func TestNewEncryptedCacheInvalidKeys(t *testing.T) {
+	t.Parallel()
+
+	backend := NewLocalCache(0, 0)
+
+	_, err := NewEncryptedCache(backend, "")
+	assert.Error(t, err)
+
+	_, err = NewEncryptedCache(backend, "", testEncryptionKey("k1", 10))
+	assert.Error(t, err)
+
+	_, err = NewEncryptedCache(backend, "", CacheEncryptionKey{Key: make([]byte, 16)})
+	assert.Error(t, err)
+
+	_, err = NewEncryptedCache(backend, "", testEncryptionKey("k1", 16), testEncryptionKey("k1", 32))
+	assert.Error(t, err)
+}
+
+func TestParseCacheEncryptionKey(t *testing.T) {
+	t.Parallel()
+
+	encoded := base64.StdEncoding.EncodeToString(make([]byte, 32))
+
+	k, err := ParseCacheEncryptionKey("2016-01:" + encoded)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "2016-01", k.ID)
+	assert.Equal(t, make([]byte, 32), k.Key)
+
+	_, err = ParseCacheEncryptionKey(encoded)
+	assert.Error(t, err)
+
+	_, err = ParseCacheEncryptionKey("k1:not base64")
+	assert.Error(t, err)
+}
+
+func TestNewClientEncryptsCache(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("encryptedKeyID", "encryptedKeySecret")
+	config.CacheEncryptionKeys = []CacheEncryptionKey{testEncryptionKey("k1", 32)}
+	backend := NewLocalCache(time.Minute, time.Minute)
+	defer backend.Close()
+
+	client := NewClient(config, backend)
+
+	assert.IsType(t, &EncryptedCache{}, client.Cache)
+
+	client.Cache.Set(key, []byte("data"))
+
+	assert.True(t, backend.Exists(key+"#encryptedKeyID"))
+
+	config.CacheEncryptionKeys = []CacheEncryptionKey{testEncryptionKey("k1", 5)}
+
+	assert.Nil(t, NewClient(config, backend).Cache)
+}
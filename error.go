@@ -1,10 +1,48 @@
 package stormpath
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 )
 
+//Stormpath API error codes
+//
+//See: https://docs.stormpath.com/rest/product-guide/latest/errors.html
+const (
+	ErrorCodeResourceNotFound            = 404
+	ErrorCodeRequiredProperty            = 2000
+	ErrorCodeDuplicateProperty           = 2001
+	ErrorCodeInvalidProperty             = 2002
+	ErrorCodeAccountStoreNotFound        = 2014
+	ErrorCodeInvalidLogin                = 7100
+	ErrorCodeAccountDisabled             = 7101
+	ErrorCodeAccountUnverified           = 7102
+	ErrorCodeInvalidPasswordResetToken   = 10011
+	ErrorCodeInvalidEmailVerifyToken     = 10012
+	ErrorCodeTokenNotFound               = 10014
+	ErrorCodeInvalidAuthenticationTarget = 10017
+)
+
+//Sentinel errors for the common Stormpath API errors, an Error matches them with errors.Is
+//when it has the same code, or the same status for the sentinels without code.
+//
+//	if errors.Is(err, stormpath.ErrInvalidLogin) { ... }
+var (
+	ErrNotFound          = Error{Status: http.StatusNotFound, Message: "resource not found"}
+	ErrUnauthorized      = Error{Status: http.StatusUnauthorized, Message: "authentication required"}
+	ErrForbidden         = Error{Status: http.StatusForbidden, Message: "forbidden"}
+	ErrConflict          = Error{Status: http.StatusConflict, Message: "conflict"}
+	ErrTooManyRequests   = Error{Status: http.StatusTooManyRequests, Message: "too many requests"}
+	ErrRequiredProperty  = Error{Code: ErrorCodeRequiredProperty, Message: "required property"}
+	ErrDuplicateProperty = Error{Code: ErrorCodeDuplicateProperty, Message: "duplicate property"}
+	ErrInvalidLogin      = Error{Code: ErrorCodeInvalidLogin, Message: "invalid username or password"}
+	ErrAccountDisabled   = Error{Code: ErrorCodeAccountDisabled, Message: "account disabled"}
+	ErrAccountUnverified = Error{Code: ErrorCodeAccountUnverified, Message: "account not verified"}
+)
+
 //Error maps a Stormpath API JSON error object which implements Go error interface
 type Error struct {
 	RequestID        string
@@ -30,6 +68,39 @@ func (e Error) String() string {
 	return e.Message
 }
 
+//Is reports whether the error matches the target Error code and status, zero values in the target match any value.
+//It makes the sentinel errors work with errors.Is.
+func (e Error) Is(target error) bool {
+	t, ok := target.(Error)
+	if !ok {
+		return false
+	}
+	if t.Code == 0 && t.Status == 0 {
+		return false
+	}
+
+	return (t.Code == 0 || t.Code == e.Code) && (t.Status == 0 || t.Status == e.Status)
+}
+
+//IsNotFound reports whether the error, or any error it wraps, is a Stormpath resource not found error
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+//IsInvalidLogin reports whether the error, or any error it wraps, is a Stormpath invalid username or password error
+func IsInvalidLogin(err error) bool {
+	return errors.Is(err, ErrInvalidLogin)
+}
+
+//ErrorStatus returns the HTTP status of the Stormpath Error in err's chain, ok is false if there is none
+func ErrorStatus(err error) (status int, ok bool) {
+	var spError Error
+	if errors.As(err, &spError) {
+		return spError.Status, true
+	}
+	return 0, false
+}
+
 func handleResponseError(req *http.Request, resp *http.Response, err error) error {
 	//Error from the request execution
 	if err != nil {
@@ -45,16 +116,29 @@ func handleResponseError(req *http.Request, resp *http.Response, err error) erro
 		resp.StatusCode != http.StatusNoContent &&
 		resp.StatusCode != http.StatusCreated &&
 		resp.StatusCode != http.StatusFound {
-		spError := &Error{}
-
-		err := json.NewDecoder(resp.Body).Decode(spError)
-		if err != nil {
-			return err
-		}
-		spError.RequestID = resp.Header.Get("Stormpath-Request-Id")
-
-		return *spError
+		return newResponseError(resp)
 	}
 	//No errors from the request execution
 	return nil
 }
+
+//newResponseError creates the Error for a failed Stormpath response, if the body is empty or not a JSON
+//error object the Error keeps the response status and the body as its developer message
+func newResponseError(resp *http.Response) Error {
+	spError := Error{}
+	body, _ := ioutil.ReadAll(resp.Body)
+
+	if err := json.Unmarshal(body, &spError); err != nil || (spError.Status == 0 && spError.Code == 0 && spError.Message == "") {
+		spError = Error{
+			Code:             resp.StatusCode,
+			Message:          http.StatusText(resp.StatusCode),
+			DeveloperMessage: string(bytes.TrimSpace(body)),
+		}
+	}
+	if spError.Status == 0 {
+		spError.Status = resp.StatusCode
+	}
+	spError.RequestID = resp.Header.Get("Stormpath-Request-Id")
+
+	return spError
+}
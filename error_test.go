@@ -0,0 +1,114 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newErrorTestClient(status int, contentType string, body string) (*httptest.Server, context.Context) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Stormpath-Request-Id", "request-id")
+		if contentType != "" {
+			w.Header().Set(ContentTypeHeader, contentType)
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+
+	config := LoadConfigurationWithCreds("errorKeyID", "errorKeySecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	return server, NewContext(context.Background(), NewClient(config, nil))
+}
+
+func TestErrorFromJSONResponse(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newErrorTestClient(http.StatusBadRequest, ApplicationJSON, `{"status":400,"code":7100,"message":"Invalid username or password."}`)
+	defer server.Close()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidLogin))
+	assert.True(t, IsInvalidLogin(fmt.Errorf("login: %w", err)))
+	assert.False(t, errors.Is(err, ErrAccountDisabled))
+	assert.False(t, IsNotFound(err))
+
+	var spError Error
+	assert.True(t, errors.As(err, &spError))
+	assert.Equal(t, ErrorCodeInvalidLogin, spError.Code)
+	assert.Equal(t, "request-id", spError.RequestID)
+}
+
+func TestIsNotFound(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newErrorTestClient(http.StatusNotFound, ApplicationJSON, `{"status":404,"code":404,"message":"The requested resource does not exist."}`)
+	defer server.Close()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	assert.True(t, IsNotFound(err))
+	assert.True(t, IsNotFound(fmt.Errorf("tenant: %w", err)))
+	assert.False(t, IsNotFound(errors.New("not found")))
+	assert.False(t, IsNotFound(nil))
+}
+
+func TestErrorFromNonJSONResponse(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newErrorTestClient(http.StatusBadGateway, "text/html", "<html>Bad Gateway</html>\n")
+	defer server.Close()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	spError, ok := err.(Error)
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, spError.Status)
+	assert.Equal(t, http.StatusBadGateway, spError.Code)
+	assert.Equal(t, "Bad Gateway", spError.Message)
+	assert.Equal(t, "<html>Bad Gateway</html>", spError.DeveloperMessage)
+	assert.Equal(t, "request-id", spError.RequestID)
+
+	status, ok := ErrorStatus(fmt.Errorf("tenant: %w", err))
+	assert.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, status)
+}
+
+func TestErrorFromEmptyResponse(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newErrorTestClient(http.StatusUnauthorized, "", "")
+	defer server.Close()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+
+	spError := err.(Error)
+	assert.Equal(t, http.StatusUnauthorized, spError.Status)
+	assert.Equal(t, "Unauthorized", spError.Message)
+	assert.Equal(t, "request-id", spError.RequestID)
+}
+
+func TestErrorIs(t *testing.T) {
+	t.Parallel()
+
+	err := Error{Status: http.StatusConflict, Code: ErrorCodeDuplicateProperty}
+
+	assert.True(t, errors.Is(err, ErrDuplicateProperty))
+	assert.True(t, errors.Is(err, ErrConflict))
+	assert.False(t, errors.Is(err, ErrRequiredProperty))
+	assert.False(t, errors.Is(err, Error{}))
+
+	_, ok := ErrorStatus(errors.New("other"))
+	assert.False(t, ok)
+}
@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //Group represents a Stormpath Group
 //
 //See: http://docs.stormpath.com/rest/product-guide/#groups
@@ -26,40 +28,78 @@ func NewGroup(name string) *Group {
 
 //GetGroup loads a group by href and criteria
 func GetGroup(href string, criteria GroupCriteria) (*Group, error) {
+	return GetGroupWithContext(context.Background(), href, criteria)
+}
+
+//GetGroupWithContext is the same as GetGroup with the addition of a context.Context
+func GetGroupWithContext(ctx context.Context, href string, criteria GroupCriteria) (*Group, error) {
 	group := &Group{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		group,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return group, nil
+	return group, err
 }
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (group *Group) Refresh() error {
-	return client.get(group.Href, group)
+	return group.RefreshWithContext(group.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (group *Group) RefreshWithContext(ctx context.Context) error {
+	ctx = group.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, group.Href, group)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (group *Group) Update() error {
-	return client.post(group.Href, group, group)
+	return group.UpdateWithContext(group.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (group *Group) UpdateWithContext(ctx context.Context) error {
+	ctx = group.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, group.Href, group, group)
 }
 
 //GetGroupAccountMemberships loads the given group memeberships
 func (group *Group) GetGroupAccountMemberships(criteria GroupMembershipCriteria) (*GroupMemberships, error) {
-	err := client.get(
+	return group.GetGroupAccountMembershipsWithContext(group.clientContext(), criteria)
+}
+
+//GetGroupAccountMembershipsWithContext is the same as GetGroupAccountMemberships with the addition of a context.Context
+func (group *Group) GetGroupAccountMembershipsWithContext(ctx context.Context, criteria GroupMembershipCriteria) (*GroupMemberships, error) {
+	ctx = group.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(group.AccountMemberships.Href, criteria.toQueryString()),
 		group.AccountMemberships,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return group.AccountMemberships, nil
+	return group.AccountMemberships, err
+}
+
+//IterateGroupAccountMemberships returns a GroupMembershipIterator over all the account memberships of the Group, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (group *Group) IterateGroupAccountMemberships(criteria GroupMembershipCriteria) *GroupMembershipIterator {
+	return group.IterateGroupAccountMembershipsWithContext(group.clientContext(), criteria)
+}
+
+//IterateGroupAccountMembershipsWithContext is the same as IterateGroupAccountMemberships with the addition of a context.Context
+func (group *Group) IterateGroupAccountMembershipsWithContext(ctx context.Context, criteria GroupMembershipCriteria) *GroupMembershipIterator {
+	ctx = group.withClient(ctx)
+	return newGroupMembershipIterator(ctx, group.AccountMemberships.Href, criteria.baseCriteria)
 }
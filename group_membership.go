@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 type GroupMembership struct {
 	resource
 	Account *Account `json:"account"`
@@ -23,27 +25,41 @@ func NewGroupMembership(accountHref string, groupHref string) *GroupMembership {
 }
 
 func (groupmembership *GroupMembership) GetAccount(criteria AccountCriteria) (*Account, error) {
-	err := client.get(
+	return groupmembership.GetAccountWithContext(groupmembership.clientContext(), criteria)
+}
+
+//GetAccountWithContext is the same as GetAccount with the addition of a context.Context
+func (groupmembership *GroupMembership) GetAccountWithContext(ctx context.Context, criteria AccountCriteria) (*Account, error) {
+	ctx = groupmembership.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(groupmembership.Account.Href, criteria.toQueryString()),
 		groupmembership.Account,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return groupmembership.Account, nil
+	return groupmembership.Account, err
 }
 
 func (groupmembership *GroupMembership) GetGroup(criteria GroupCriteria) (*Group, error) {
-	err := client.get(
+	return groupmembership.GetGroupWithContext(groupmembership.clientContext(), criteria)
+}
+
+//GetGroupWithContext is the same as GetGroup with the addition of a context.Context
+func (groupmembership *GroupMembership) GetGroupWithContext(ctx context.Context, criteria GroupCriteria) (*Group, error) {
+	ctx = groupmembership.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(groupmembership.Group.Href, criteria.toQueryString()),
 		groupmembership.Group,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return groupmembership.Group, nil
+	return groupmembership.Group, err
 }
@@ -0,0 +1,43 @@
+package stormpath
+
+import "net/http"
+
+//Invoker executes a Stormpath request and decodes the response into result,
+//result is nil when no response body is expected (for example for a DELETE).
+type Invoker func(req *http.Request, result interface{}) error
+
+//Interceptor wraps the execution of every request done by a Client, it receives the request before it is
+//signed and cached, and must call next to continue the execution, after next returns result holds the
+//decoded response.
+//
+//Interceptors can be used for tracing, metrics, auditing, header injection or fault injection, for example:
+//
+//	client.Use(func(req *http.Request, result interface{}, next stormpath.Invoker) error {
+//		start := time.Now()
+//		err := next(req, result)
+//		log.Printf("%s %s took %s", req.Method, req.URL, time.Since(start))
+//		return err
+//	})
+//
+//Since the request is signed right before being sent, headers, query params or the body can be safely
+//modified. If the body is replaced the request GetBody func must be updated too.
+type Interceptor func(req *http.Request, result interface{}, next Invoker) error
+
+//Use appends the given interceptors to the client interceptor chain, the first interceptor is the outermost one.
+//
+//Interceptors should be configured before the client is used, Use is not safe for concurrent use with
+//requests in flight.
+func (client *Client) Use(interceptors ...Interceptor) {
+	client.Interceptors = append(client.Interceptors, interceptors...)
+}
+
+//chain wraps the given Invoker with the client interceptors
+func (client *Client) chain(invoker Invoker) Invoker {
+	for i := len(client.Interceptors) - 1; i >= 0; i-- {
+		interceptor, next := client.Interceptors[i], invoker
+		invoker = func(req *http.Request, result interface{}) error {
+			return interceptor(req, result, next)
+		}
+	}
+	return invoker
+}
@@ -0,0 +1,133 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newInterceptorTestClient() (*Client, *httptest.Server, *[]*http.Request) {
+	requests := []*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"tenant","name":"intercepted"}`))
+	}))
+
+	config := LoadConfigurationWithCreds("interceptorKeyID", "interceptorKeySecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	return NewClient(config, nil), server, &requests
+}
+
+func TestInterceptorsOrder(t *testing.T) {
+	t.Parallel()
+
+	c, server, _ := newInterceptorTestClient()
+	defer server.Close()
+
+	calls := []string{}
+	c.Use(
+		func(req *http.Request, result interface{}, next Invoker) error {
+			calls = append(calls, "first before")
+			err := next(req, result)
+			calls = append(calls, "first after")
+			return err
+		},
+		func(req *http.Request, result interface{}, next Invoker) error {
+			calls = append(calls, "second before")
+			err := next(req, result)
+			calls = append(calls, "second after")
+			return err
+		},
+	)
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first before", "second before", "second after", "first after"}, calls)
+}
+
+func TestInterceptorSeesTypedResult(t *testing.T) {
+	t.Parallel()
+
+	c, server, _ := newInterceptorTestClient()
+	defer server.Close()
+
+	var name string
+	c.Use(func(req *http.Request, result interface{}, next Invoker) error {
+		err := next(req, result)
+		if tenant, ok := result.(*Tenant); ok {
+			name = tenant.Name
+		}
+		return err
+	})
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "intercepted", name)
+}
+
+func TestInterceptorHeaderInjectionIsSigned(t *testing.T) {
+	t.Parallel()
+
+	c, server, requests := newInterceptorTestClient()
+	defer server.Close()
+
+	c.Use(func(req *http.Request, result interface{}, next Invoker) error {
+		req.Header.Set("X-Request-Id", "123")
+		return next(req, result)
+	})
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Len(t, *requests, 1)
+	assert.Equal(t, "123", (*requests)[0].Header.Get("X-Request-Id"))
+	assert.Contains(t, (*requests)[0].Header.Get(AuthorizationHeader), "x-request-id")
+}
+
+func TestInterceptorFaultInjection(t *testing.T) {
+	t.Parallel()
+
+	c, server, requests := newInterceptorTestClient()
+	defer server.Close()
+
+	fault := errors.New("injected fault")
+	c.Use(func(req *http.Request, result interface{}, next Invoker) error {
+		return fault
+	})
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.Equal(t, fault, err)
+	assert.Empty(t, *requests)
+}
+
+func TestInterceptorWrapsDelete(t *testing.T) {
+	t.Parallel()
+
+	c, server, requests := newInterceptorTestClient()
+	defer server.Close()
+
+	var method string
+	c.Use(func(req *http.Request, result interface{}, next Invoker) error {
+		method = req.Method
+		return next(req, result)
+	})
+
+	account := &Account{}
+	account.Href = server.URL + "/v1/accounts/XXXX"
+
+	err := account.DeleteWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodDelete, method)
+	assert.Len(t, *requests, 1)
+}
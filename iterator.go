@@ -0,0 +1,291 @@
+package stormpath
+
+import "context"
+
+//DefaultIteratorPageSize is the page size used by the collection iterators when the criteria doesn't set a limit,
+//it is the maximum page size allowed by the Stormpath API
+const DefaultIteratorPageSize = 100
+
+//pageIterator walks a Stormpath collection resource page by page, the next page is only fetched
+//once every item of the current one has been visited.
+//
+//The typed iterators returned by the SDK, such as AccountIterator, embed it and keep the current page,
+//fetch loads the page for the given URL and returns its collection attributes and number of items.
+type pageIterator struct {
+	ctx      context.Context
+	href     string
+	criteria baseCriteria
+	fetch    func(ctx context.Context, url string) (collectionResource, int, error)
+
+	fetched bool
+	offset  int
+	size    int
+	length  int
+	index   int
+	err     error
+}
+
+func newPageIterator(ctx context.Context, href string, criteria baseCriteria, fetch func(ctx context.Context, url string) (collectionResource, int, error)) pageIterator {
+	if criteria.limit == 0 {
+		criteria.limit = DefaultIteratorPageSize
+	}
+
+	return pageIterator{ctx: ctx, href: href, criteria: criteria, fetch: fetch, offset: criteria.offset}
+}
+
+//Next advances the iterator to the next item fetching the next page if needed,
+//it returns false when there are no more items or an error occurred
+func (it *pageIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	if it.fetched {
+		if it.index+1 < it.length {
+			it.index++
+			return true
+		}
+
+		if it.length == 0 || it.offset+it.length >= it.size {
+			//Leave the iterator past the last item so Value returns nil
+			it.index = it.length
+			return false
+		}
+		it.offset += it.length
+	}
+
+	it.criteria.offset = it.offset
+	page, length, err := it.fetch(it.ctx, buildAbsoluteURL(it.href, it.criteria.toQueryString()))
+	if err != nil {
+		it.err = err
+		it.fetched = false
+		it.length = 0
+		return false
+	}
+
+	it.fetched = true
+	it.index = 0
+	it.length = length
+	if page.Offset != nil {
+		it.offset = *page.Offset
+	}
+	it.size = it.offset + length
+	if page.Size != nil {
+		it.size = *page.Size
+	}
+
+	return length > 0
+}
+
+//valid reports whether the iterator is on an item of the current page
+func (it *pageIterator) valid() bool {
+	return it.fetched && it.index < it.length
+}
+
+//Err returns the error that stopped the iteration if any
+func (it *pageIterator) Err() error {
+	return it.err
+}
+
+//Size returns the total number of items of the collection as reported by the last fetched page
+func (it *pageIterator) Size() int {
+	return it.size
+}
+
+//AccountIterator iterates over every Account of a collection fetching the pages lazily
+//
+//	it := tenant.IterateAccounts(stormpath.MakeAccountsCriteria().StatusEq(stormpath.Enabled))
+//	for it.Next() {
+//		account := it.Value()
+//	}
+//	if it.Err() != nil { ... }
+type AccountIterator struct {
+	pageIterator
+	page *Accounts
+}
+
+func newAccountIterator(ctx context.Context, href string, criteria baseCriteria) *AccountIterator {
+	it := &AccountIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &Accounts{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current Account, it is nil before the first call to Next and once the iteration stopped
+func (it *AccountIterator) Value() *Account {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//GroupIterator iterates over every Group of a collection fetching the pages lazily
+type GroupIterator struct {
+	pageIterator
+	page *Groups
+}
+
+func newGroupIterator(ctx context.Context, href string, criteria baseCriteria) *GroupIterator {
+	it := &GroupIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &Groups{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current Group, it is nil before the first call to Next and once the iteration stopped
+func (it *GroupIterator) Value() *Group {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//DirectoryIterator iterates over every Directory of a collection fetching the pages lazily
+type DirectoryIterator struct {
+	pageIterator
+	page *Directories
+}
+
+func newDirectoryIterator(ctx context.Context, href string, criteria baseCriteria) *DirectoryIterator {
+	it := &DirectoryIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &Directories{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current Directory, it is nil before the first call to Next and once the iteration stopped
+func (it *DirectoryIterator) Value() *Directory {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//ApplicationIterator iterates over every Application of a collection fetching the pages lazily
+type ApplicationIterator struct {
+	pageIterator
+	page *Applications
+}
+
+func newApplicationIterator(ctx context.Context, href string, criteria baseCriteria) *ApplicationIterator {
+	it := &ApplicationIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &Applications{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current Application, it is nil before the first call to Next and once the iteration stopped
+func (it *ApplicationIterator) Value() *Application {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//OrganizationIterator iterates over every Organization of a collection fetching the pages lazily
+type OrganizationIterator struct {
+	pageIterator
+	page *Organizations
+}
+
+func newOrganizationIterator(ctx context.Context, href string, criteria baseCriteria) *OrganizationIterator {
+	it := &OrganizationIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &Organizations{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current Organization, it is nil before the first call to Next and once the iteration stopped
+func (it *OrganizationIterator) Value() *Organization {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//GroupMembershipIterator iterates over every GroupMembership of a collection fetching the pages lazily
+type GroupMembershipIterator struct {
+	pageIterator
+	page *GroupMemberships
+}
+
+func newGroupMembershipIterator(ctx context.Context, href string, criteria baseCriteria) *GroupMembershipIterator {
+	it := &GroupMembershipIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &GroupMemberships{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current GroupMembership, it is nil before the first call to Next and once the iteration stopped
+func (it *GroupMembershipIterator) Value() *GroupMembership {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//OAuthTokenIterator iterates over every OAuthToken of a collection fetching the pages lazily
+type OAuthTokenIterator struct {
+	pageIterator
+	page *OAuthTokens
+}
+
+func newOAuthTokenIterator(ctx context.Context, href string, criteria baseCriteria) *OAuthTokenIterator {
+	it := &OAuthTokenIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &OAuthTokens{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current OAuthToken, it is nil before the first call to Next and once the iteration stopped
+func (it *OAuthTokenIterator) Value() *OAuthToken {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
+
+//APIKeyIterator iterates over every APIKey of a collection fetching the pages lazily
+type APIKeyIterator struct {
+	pageIterator
+	page *APIKeys
+}
+
+func newAPIKeyIterator(ctx context.Context, href string, criteria baseCriteria) *APIKeyIterator {
+	it := &APIKeyIterator{}
+	it.pageIterator = newPageIterator(ctx, href, criteria, func(ctx context.Context, url string) (collectionResource, int, error) {
+		it.page = &APIKeys{}
+		err := ClientFromContext(ctx).get(ctx, url, it.page)
+		return it.page.collectionResource, len(it.page.Items), err
+	})
+	return it
+}
+
+//Value returns the current APIKey, it is nil before the first call to Next and once the iteration stopped
+func (it *APIKeyIterator) Value() *APIKey {
+	if !it.valid() {
+		return nil
+	}
+	return &it.page.Items[it.index]
+}
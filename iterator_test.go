@@ -0,0 +1,158 @@
+package stormpath
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//newIteratorTestServer serves a collection of size accounts paged with the request offset and limit,
+//the request with the given failAt offset fails
+func newIteratorTestServer(size int, failAt int) *testServer {
+	return newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		if offset == failAt {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"status":500,"message":"failed"}`))
+			return
+		}
+
+		items := []map[string]interface{}{}
+		for i := offset; i < offset+limit && i < size; i++ {
+			items = append(items, map[string]interface{}{"href": fmt.Sprintf("accounts/%d", i), "username": strconv.Itoa(i)})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"href": "accounts", "offset": offset, "limit": limit, "size": size, "items": items})
+	})
+}
+
+func newIteratorTestTenant(server *testServer) (context.Context, *Tenant) {
+	tenant := &Tenant{Accounts: &Accounts{}}
+	tenant.Accounts.Href = server.URL + "/v1/tenants/current/accounts"
+
+	ctx, _ := newTestContext(server.configuration(), nil)
+	return ctx, tenant
+}
+
+func TestIterateAccountsFetchesEveryPage(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(7, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	it := tenant.IterateAccountsWithContext(ctx, MakeAccountsCriteria().StatusEq(Enabled).Limit(3))
+	assert.Nil(t, it.Value())
+
+	usernames := []string{}
+	for it.Next() {
+		usernames = append(usernames, it.Value().Username)
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6"}, usernames)
+	assert.Equal(t, 7, it.Size())
+	assert.Equal(t, []string{
+		"limit=3&offset=0&status=ENABLED",
+		"limit=3&offset=3&status=ENABLED",
+		"limit=3&offset=6&status=ENABLED",
+	}, server.queries())
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Value())
+}
+
+func TestIterateAccountsDefaultPageSizeAndOffset(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(150, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	it := tenant.IterateAccountsWithContext(ctx, AccountCriteria{}.Offset(20))
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 130, count)
+	assert.Equal(t, []string{"limit=100&offset=20", "limit=100&offset=120"}, server.queries())
+
+	it = tenant.IterateAccountsWithContext(ctx, MakeAccountsCriteria().Offset(100))
+
+	count = 0
+	for it.Next() {
+		count++
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, 50, count)
+	assert.Equal(t, []string{"limit=25&offset=100", "limit=25&offset=125"}, server.queries()[2:])
+}
+
+func TestIterateAccountsEmptyCollection(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(0, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	it := tenant.IterateAccountsWithContext(ctx, MakeAccountsCriteria())
+
+	assert.False(t, it.Next())
+	assert.Nil(t, it.Value())
+	assert.NoError(t, it.Err())
+	assert.Len(t, server.queries(), 1)
+}
+
+func TestIterateAccountsStopsOnError(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(10, 4)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	it := tenant.IterateAccountsWithContext(ctx, MakeAccountsCriteria().Limit(4))
+
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Equal(t, 4, count)
+	assert.Error(t, it.Err())
+	assert.Equal(t, 500, it.Err().(Error).Status)
+	assert.Nil(t, it.Value())
+	assert.False(t, it.Next())
+	assert.Len(t, server.queries(), 2)
+}
+
+func TestIterateGroupsValueBeforeNext(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(2, -1)
+	defer server.Close()
+	ctx, _ := newIteratorTestTenant(server)
+
+	directory := &Directory{Groups: &Groups{}}
+	directory.Groups.Href = server.URL + "/v1/directories/1/groups"
+
+	it := directory.IterateGroupsWithContext(ctx, MakeGroupsCriteria())
+	assert.Nil(t, it.Value())
+
+	hrefs := []string{}
+	for it.Next() {
+		hrefs = append(hrefs, it.Value().Href)
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Equal(t, []string{"accounts/0", "accounts/1"}, hrefs)
+	assert.Nil(t, it.Value())
+}
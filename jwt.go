@@ -64,8 +64,20 @@ type AccessTokenClaims struct {
 }
 
 //JWT helper function to create JWT token strings with the given claims, extra header values,
-//and sign with client API Key Secret using SigningMethodHS256 algorithm
+//and sign with the default client API Key Secret using SigningMethodHS256 algorithm
 func JWT(claims jwt.Claims, extraHeaders map[string]interface{}) string {
+	return client.JWT(claims, extraHeaders)
+}
+
+//ParseJWT parses the given JWT token string into the given claims, validating the signature
+//with the default client API Key Secret
+func ParseJWT(token string, claims jwt.Claims) *jwt.Token {
+	return client.ParseJWT(token, claims)
+}
+
+//JWT creates a JWT token string with the given claims, extra header values,
+//and sign with the client API Key Secret using SigningMethodHS256 algorithm
+func (client *Client) JWT(claims jwt.Claims, extraHeaders map[string]interface{}) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	for key, value := range extraHeaders {
@@ -76,7 +88,9 @@ func JWT(claims jwt.Claims, extraHeaders map[string]interface{}) string {
 	return encodedJWT
 }
 
-func ParseJWT(token string, claims jwt.Claims) *jwt.Token {
+//ParseJWT parses the given JWT token string into the given claims, validating the signature
+//with the client API Key Secret
+func (client *Client) ParseJWT(token string, claims jwt.Claims) *jwt.Token {
 	decodedJWT, _ := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
 		return client.ClientConfiguration.GetJWTSigningKey(), nil
 	})
@@ -1,14 +1,18 @@
 package stormpath
 
 import (
+	"container/list"
+	"context"
 	"sync"
 	"time"
 )
 
 type cacheItem struct {
 	sync.RWMutex
+	key     string
 	data    []byte
 	expires *time.Time
+	element *list.Element
 }
 
 func (item *cacheItem) touch(duration time.Duration) {
@@ -30,44 +34,109 @@ func (item *cacheItem) expired() bool {
 	return value
 }
 
+//LocalCache is an in-memory Cache with TTL and TTI expiration.
+//
+//It can optionally be bounded by a maximum number of entries and/or a maximum size in bytes of the cached data,
+//when a limit is exceeded the least recently used entries are evicted.
+//
+//Expired entries are removed by a single janitor goroutine that runs until the cache is closed
+//or the context.Context it was created with is done.
+//
+//A LocalCache must be created with NewLocalCache or one of its variants, the zero value can only be closed.
 type LocalCache struct {
-	mutex sync.RWMutex
-	ttl   time.Duration
-	tti   time.Duration
-	items map[string]*cacheItem
+	mutex       sync.RWMutex
+	ttl         time.Duration
+	tti         time.Duration
+	items       map[string]*cacheItem
+	lru         *list.List
+	maxEntries  int
+	maxBytes    int64
+	bytes       int64
+	evictions   uint64
+	expirations uint64
+	stop        chan struct{}
+	stopped     chan struct{}
+	closeOnce   sync.Once
 }
 
 func (cache *LocalCache) Set(key string, data []byte) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	item := &cacheItem{data: data}
+	if old, exists := cache.items[key]; exists {
+		cache.remove(old)
+	}
+	//An entry bigger than the whole cache is never kept, the other entries stay untouched
+	if cache.maxBytes > 0 && int64(len(data)) > cache.maxBytes {
+		cache.evictions++
+		return
+	}
+
+	item := &cacheItem{key: key, data: data}
 	item.touch(cache.ttl)
+	item.element = cache.lru.PushFront(item)
 	cache.items[key] = item
+	cache.bytes += int64(len(data))
+
+	for cache.overLimits() {
+		cache.remove(cache.lru.Back().Value.(*cacheItem))
+		cache.evictions++
+	}
 }
 
 func (cache *LocalCache) Get(key string) []byte {
+	data, _ := cache.GetIfPresent(key)
+	return data
+}
+
+//GetIfPresent returns the cached data of the key and true if the key is cached and not expired,
+//it is the atomic equivalent of Exists followed by Get
+func (cache *LocalCache) GetIfPresent(key string) ([]byte, bool) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 	item, exists := cache.items[key]
 	if exists && !item.expired() {
 		item.touch(cache.tti)
+		cache.lru.MoveToFront(item.element)
 
-		return item.data
+		return item.data, true
+	}
+	if exists {
+		cache.remove(item)
+		cache.expirations++
 	}
-	return []byte{}
+	return []byte{}, false
 }
 
 func (cache *LocalCache) Del(key string) {
 	cache.mutex.Lock()
 	defer cache.mutex.Unlock()
 
-	delete(cache.items, key)
+	if item, exists := cache.items[key]; exists {
+		cache.remove(item)
+	}
+}
+
+//overLimits returns true if the cache holds more entries or bytes than its limits allow
+func (cache *LocalCache) overLimits() bool {
+	return cache.lru.Len() > 0 &&
+		((cache.maxEntries > 0 && cache.lru.Len() > cache.maxEntries) ||
+			(cache.maxBytes > 0 && cache.bytes > cache.maxBytes))
 }
 
+//remove deletes the item from the cache, the cache lock must be held
+func (cache *LocalCache) remove(item *cacheItem) {
+	delete(cache.items, item.key)
+	cache.lru.Remove(item.element)
+	cache.bytes -= int64(len(item.data))
+}
+
+//Exists returns true if the key is cached and not expired
 func (cache *LocalCache) Exists(key string) bool {
-	_, exists := cache.items[key]
-	return exists
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	item, exists := cache.items[key]
+	return exists && !item.expired()
 }
 
 // Count returns the number of items in the cache
@@ -79,44 +148,162 @@ func (cache *LocalCache) Count() int {
 	return count
 }
 
+//Bytes returns the size in bytes of the cached data
+func (cache *LocalCache) Bytes() int64 {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.bytes
+}
+
+//Evictions returns the number of entries evicted to keep the cache within its size limits
+func (cache *LocalCache) Evictions() uint64 {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.evictions
+}
+
+//Expirations returns the number of entries removed because their TTL or TTI expired
+func (cache *LocalCache) Expirations() uint64 {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+	return cache.expirations
+}
+
+//Entries returns the entries that aren't expired from the least to the most recently used
+func (cache *LocalCache) Entries() []CacheEntry {
+	cache.mutex.RLock()
+	defer cache.mutex.RUnlock()
+
+	now := time.Now()
+	entries := make([]CacheEntry, 0, cache.lru.Len())
+	for element := cache.lru.Back(); element != nil; element = element.Prev() {
+		item := element.Value.(*cacheItem)
+		item.RLock()
+		if item.expires != nil && item.expires.After(now) {
+			entries = append(entries, CacheEntry{Key: item.key, Data: item.data, Expires: *item.expires})
+		}
+		item.RUnlock()
+	}
+	return entries
+}
+
+//Restore adds the entries that aren't expired nor cached yet and returns how many were added. The entries keep
+//their order but are less recently used than the cached ones, so when the size limits are reached the least
+//recently used entries are the ones skipped. The entries keep their expiration but never outlive the cache TTL.
+func (cache *LocalCache) Restore(entries []CacheEntry) int {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+
+	now := time.Now()
+	restored := 0
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if _, exists := cache.items[entry.Key]; exists || !entry.Expires.After(now) {
+			continue
+		}
+
+		expires := entry.Expires
+		if cache.ttl > 0 && expires.After(now.Add(cache.ttl)) {
+			expires = now.Add(cache.ttl)
+		}
+		item := &cacheItem{key: entry.Key, data: entry.Data, expires: &expires}
+		item.element = cache.lru.PushBack(item)
+		cache.items[entry.Key] = item
+		cache.bytes += int64(len(entry.Data))
+
+		if cache.overLimits() {
+			cache.remove(item)
+			continue
+		}
+		restored++
+	}
+	return restored
+}
+
 func (cache *LocalCache) cleanup() {
 	cache.mutex.Lock()
-	for key, item := range cache.items {
+	for _, item := range cache.items {
 		if item.expired() {
-			delete(cache.items, key)
+			cache.remove(item)
+			cache.expirations++
 		}
 	}
 	cache.mutex.Unlock()
 }
 
-func (cache *LocalCache) startCleanupTimer() {
-	ttlTicker := time.Tick(cache.ttl)
-	go (func() {
-		for {
-			select {
-			case <-ttlTicker:
-				cache.cleanup()
-			}
-		}
-	})()
-
-	ttiTicker := time.Tick(cache.tti)
-	go (func() {
-		for {
-			select {
-			case <-ttiTicker:
-				cache.cleanup()
-			}
+//janitor removes the expired entries every interval until the cache is closed or ctx is done
+func (cache *LocalCache) janitor(ctx context.Context, interval time.Duration) {
+	defer close(cache.stopped)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			cache.cleanup()
+		case <-cache.stop:
+			return
+		case <-ctx.Done():
+			return
 		}
-	})()
+	}
 }
 
+//Close stops the janitor goroutine and waits for it to exit, the cache stays usable but expired entries
+//are only removed when they are read. Close can be called more than once.
+func (cache *LocalCache) Close() error {
+	//A LocalCache that wasn't created by its constructors has no janitor
+	if cache == nil || cache.stop == nil {
+		return nil
+	}
+
+	cache.closeOnce.Do(func() {
+		close(cache.stop)
+	})
+	<-cache.stopped
+	return nil
+}
+
+//NewLocalCache creates an unbounded LocalCache with the given TTL and TTI, the cache should be closed when no longer used
 func NewLocalCache(ttl time.Duration, tti time.Duration) *LocalCache {
+	return NewBoundedLocalCacheWithContext(context.Background(), ttl, tti, 0, 0)
+}
+
+//NewLocalCacheWithContext creates an unbounded LocalCache with the given TTL and TTI, the cache janitor stops when ctx is done
+func NewLocalCacheWithContext(ctx context.Context, ttl time.Duration, tti time.Duration) *LocalCache {
+	return NewBoundedLocalCacheWithContext(ctx, ttl, tti, 0, 0)
+}
+
+//NewBoundedLocalCache creates a LocalCache with the given TTL and TTI holding at most maxEntries entries
+//and maxBytes bytes of cached data, the least recently used entries are evicted first.
+//A limit of 0 means no limit. The cache should be closed when no longer used.
+func NewBoundedLocalCache(ttl time.Duration, tti time.Duration, maxEntries int, maxBytes int64) *LocalCache {
+	return NewBoundedLocalCacheWithContext(context.Background(), ttl, tti, maxEntries, maxBytes)
+}
+
+//NewBoundedLocalCacheWithContext creates a bounded LocalCache like NewBoundedLocalCache, the cache janitor stops when ctx is done
+func NewBoundedLocalCacheWithContext(ctx context.Context, ttl time.Duration, tti time.Duration, maxEntries int, maxBytes int64) *LocalCache {
 	cache := &LocalCache{
-		ttl:   ttl,
-		tti:   tti,
-		items: map[string]*cacheItem{},
+		ttl:        ttl,
+		tti:        tti,
+		items:      map[string]*cacheItem{},
+		lru:        list.New(),
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+
+	//The janitor runs as often as the shortest of TTL and TTI, without expiration there is nothing to clean up
+	interval := ttl
+	if tti > 0 && (interval <= 0 || tti < interval) {
+		interval = tti
+	}
+	if interval > 0 {
+		go cache.janitor(ctx, interval)
+	} else {
+		close(cache.stopped)
 	}
-	cache.startCleanupTimer()
 	return cache
 }
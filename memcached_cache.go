@@ -0,0 +1,196 @@
+package stormpath
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//Memcached limits, keys are at most 250 bytes without spaces or control characters
+//and expirations over 30 days are read as unix timestamps
+const (
+	memcachedMaxKeyLength   = 250
+	memcachedMaxRelativeTTL = 30 * 24 * time.Hour
+)
+
+//MemcachedCacheOptions configures a MemcachedCache
+//
+//Prefix is prepended to every key, DefaultCacheKeyPrefix is used if empty.
+//TTL is passed to memcached as the expiration of the entries stored with Set, 0 means they don't expire. The client
+//stores its responses with SetWithTTL and the TTL of their cache region instead.
+//PoolSize is the maximum number of connections opened to the server, a command waits up to Timeout for a connection
+//when all of them are in use. Timeout is also the deadline of every command,
+//DefaultCachePoolSize and DefaultCacheTimeout are used if 0.
+type MemcachedCacheOptions struct {
+	Prefix   string
+	TTL      time.Duration
+	PoolSize int
+	Timeout  time.Duration
+}
+
+//MemcachedCache is a Cache backed by a memcached server so the cached resources are shared by every client using it.
+//
+//Keys that memcached doesn't accept with their prefix, too long or with spaces, are stored under the SHA1 of the prefixed key.
+//A write evicts the base href of the changed resources and the variants the writing client cached itself, the
+//variants only cached by other clients, e.g. with another expand query, stay cached until their TTL.
+//Cache errors are logged and handled as cache misses, a memcached outage never fails the SDK calls.
+type MemcachedCache struct {
+	prefix string
+	ttl    time.Duration
+	pool   *connPool
+}
+
+//NewMemcachedCache creates a MemcachedCache for the memcached server at address, e.g. localhost:11211
+func NewMemcachedCache(address string, options MemcachedCacheOptions) *MemcachedCache {
+	InitLog()
+
+	if options.Prefix == "" {
+		options.Prefix = DefaultCacheKeyPrefix
+	}
+
+	return &MemcachedCache{
+		prefix: options.Prefix,
+		ttl:    options.TTL,
+		pool:   newConnPool(address, options.PoolSize, options.Timeout, nil),
+	}
+}
+
+func (cache *MemcachedCache) Exists(key string) bool {
+	data, err := cache.get(key)
+	return err == nil && data != nil
+}
+
+func (cache *MemcachedCache) Set(key string, data []byte) {
+	cache.SetWithTTL(key, data, cache.ttl)
+}
+
+//SetWithTTL stores the data with the given expiration, 0 means it doesn't expire
+func (cache *MemcachedCache) SetWithTTL(key string, data []byte, ttl time.Duration) {
+	cache.do("set", func(conn *cacheConn) error {
+		fmt.Fprintf(conn.writer, "set %s 0 %d %d\r\n", cache.key(key), memcachedExpiration(ttl), len(data))
+		conn.writer.Write(data)
+		conn.writer.WriteString("\r\n")
+		if err := conn.writer.Flush(); err != nil {
+			return err
+		}
+
+		return expectMemcachedReply(conn, "STORED")
+	})
+}
+
+func (cache *MemcachedCache) Get(key string) []byte {
+	data, _ := cache.GetIfPresent(key)
+	return data
+}
+
+func (cache *MemcachedCache) GetIfPresent(key string) ([]byte, bool) {
+	data, err := cache.get(key)
+	if err != nil || data == nil {
+		return []byte{}, false
+	}
+	return data, true
+}
+
+func (cache *MemcachedCache) Del(key string) {
+	cache.do("delete", func(conn *cacheConn) error {
+		fmt.Fprintf(conn.writer, "delete %s\r\n", cache.key(key))
+		if err := conn.writer.Flush(); err != nil {
+			return err
+		}
+
+		return expectMemcachedReply(conn, "DELETED", "NOT_FOUND")
+	})
+}
+
+//Close closes the idle connections to the memcached server
+func (cache *MemcachedCache) Close() error {
+	return cache.pool.close()
+}
+
+//get returns the cached data of the key or nil if it isn't cached
+func (cache *MemcachedCache) get(key string) ([]byte, error) {
+	var data []byte
+
+	err := cache.do("get", func(conn *cacheConn) error {
+		fmt.Fprintf(conn.writer, "get %s\r\n", cache.key(key))
+		if err := conn.writer.Flush(); err != nil {
+			return err
+		}
+
+		for {
+			line, err := readCacheLine(conn)
+			if err != nil {
+				return err
+			}
+			if line == "END" {
+				return nil
+			}
+
+			//VALUE <key> <flags> <bytes>
+			fields := strings.Fields(line)
+			if len(fields) != 4 || fields[0] != "VALUE" {
+				return fmt.Errorf("unexpected memcached reply %q", line)
+			}
+			size, err := strconv.Atoi(fields[3])
+			if err != nil {
+				return err
+			}
+			data = make([]byte, size+2)
+			if _, err := io.ReadFull(conn.reader, data); err != nil {
+				return err
+			}
+			data = data[:size]
+		}
+	})
+	return data, err
+}
+
+func (cache *MemcachedCache) do(command string, fn func(conn *cacheConn) error) error {
+	err := cache.pool.do(fn)
+	if err != nil {
+		Logger.Printf("[ERROR] Memcached cache %s failed [%s]", command, err)
+	}
+	return err
+}
+
+//key returns the memcached key of the cache key
+func (cache *MemcachedCache) key(key string) string {
+	key = cache.prefix + key
+	if len(key) <= memcachedMaxKeyLength && !strings.ContainsAny(key, " \t\r\n\x00") {
+		return key
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+//memcachedExpiration returns the memcached expiration of the TTL, in seconds or as a unix timestamp for TTLs over 30 days
+func memcachedExpiration(ttl time.Duration) int64 {
+	switch {
+	case ttl <= 0:
+		return 0
+	case ttl > memcachedMaxRelativeTTL:
+		return time.Now().Add(ttl).Unix()
+	case ttl < time.Second:
+		return 1
+	}
+	return int64(ttl / time.Second)
+}
+
+//expectMemcachedReply reads a reply line and fails if it isn't one of the expected replies
+func expectMemcachedReply(conn *cacheConn, expected ...string) error {
+	line, err := readCacheLine(conn)
+	if err != nil {
+		return err
+	}
+	for _, e := range expected {
+		if line == e {
+			return nil
+		}
+	}
+	return fmt.Errorf("unexpected memcached reply %q", line)
+}
@@ -0,0 +1,251 @@
+package stormpath
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//memcachedTestServer is an in-process stand-in for a memcached server implementing the commands used by MemcachedCache
+type memcachedTestServer struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	values      map[string][]byte
+	commands    []string
+	connections int
+}
+
+func newMemcachedTestServer() *memcachedTestServer {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	s := &memcachedTestServer{listener: listener, values: map[string][]byte{}}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.connections++
+			s.mutex.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *memcachedTestServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *memcachedTestServer) Close() {
+	s.listener.Close()
+}
+
+func (s *memcachedTestServer) command(i int) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.commands[i]
+}
+
+func (s *memcachedTestServer) stored(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.values[key]
+	return ok
+}
+
+func (s *memcachedTestServer) connectionCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.connections
+}
+
+func (s *memcachedTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimSuffix(line, "\r\n")
+		fields := strings.Fields(line)
+
+		var data []byte
+		if fields[0] == "set" {
+			size, _ := strconv.Atoi(fields[4])
+			data = make([]byte, size+2)
+			io.ReadFull(reader, data)
+			data = data[:size]
+		}
+
+		conn.Write([]byte(s.execute(line, fields, data)))
+	}
+}
+
+func (s *memcachedTestServer) execute(line string, fields []string, data []byte) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.commands = append(s.commands, line)
+
+	switch fields[0] {
+	case "set":
+		s.values[fields[1]] = data
+		return "STORED\r\n"
+	case "get":
+		value, ok := s.values[fields[1]]
+		if !ok {
+			return "END\r\n"
+		}
+		return fmt.Sprintf("VALUE %s 0 %d\r\n%s\r\nEND\r\n", fields[1], len(value), value)
+	case "delete":
+		if _, ok := s.values[fields[1]]; !ok {
+			return "NOT_FOUND\r\n"
+		}
+		delete(s.values, fields[1])
+		return "DELETED\r\n"
+	}
+	return "ERROR\r\n"
+}
+
+func TestMemcachedCache(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	defer server.Close()
+	cache := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{})
+	defer cache.Close()
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, []byte{}, cache.Get(key))
+
+	cache.Set(key, []byte("hello\r\nEND\r\n"))
+
+	assert.True(t, cache.Exists(key))
+	assert.Equal(t, []byte("hello\r\nEND\r\n"), cache.Get(key))
+	assert.True(t, server.stored(DefaultCacheKeyPrefix+key))
+
+	cache.Del(key)
+	cache.Del(key)
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, 1, server.connectionCount())
+}
+
+func TestMemcachedCacheTTLAndPrefix(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	defer server.Close()
+
+	cache := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{Prefix: "app:", TTL: 90 * time.Second})
+	defer cache.Close()
+	cache.Set(key, []byte("hello"))
+
+	assert.Equal(t, "set app:"+key+" 0 90 5", server.command(0))
+
+	cache = NewMemcachedCache(server.Addr(), MemcachedCacheOptions{TTL: 60 * 24 * time.Hour})
+	defer cache.Close()
+	cache.Set(key, []byte("hello"))
+
+	fields := strings.Fields(server.command(1))
+	expires, _ := strconv.ParseInt(fields[3], 10, 64)
+	assert.InDelta(t, time.Now().Add(60*24*time.Hour).Unix(), expires, 5)
+
+	cache.SetWithTTL(key, []byte("hello"), 15*time.Second)
+
+	assert.Equal(t, "set stormpath:"+key+" 0 15 5", server.command(2))
+}
+
+func TestMemcachedCacheLongKeys(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	defer server.Close()
+	cache := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{})
+	defer cache.Close()
+
+	long := "https://api.stormpath.com/v1/accounts/1?expand=" + strings.Repeat("groups,", 50)
+	spaced := "https://api.stormpath.com/v1/accounts/1?search=john doe"
+
+	cache.Set(long, []byte("long"))
+	cache.Set(spaced, []byte("spaced"))
+
+	assert.Equal(t, []byte("long"), cache.Get(long))
+	assert.Equal(t, []byte("spaced"), cache.Get(spaced))
+	assert.Len(t, cache.key(long), 40)
+	assert.NotContains(t, cache.key(spaced), " ")
+}
+
+func TestMemcachedCacheInvalidPrefix(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	defer server.Close()
+	spaced := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{Prefix: "my app:"})
+	defer spaced.Close()
+	long := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{Prefix: strings.Repeat("app", 100)})
+	defer long.Close()
+
+	spaced.Set(key, []byte("spaced"))
+	long.Set(key, []byte("long"))
+
+	assert.Equal(t, []byte("spaced"), spaced.Get(key))
+	assert.Equal(t, []byte("long"), long.Get(key))
+	assert.Len(t, spaced.key(key), 40)
+	assert.Len(t, long.key(key), 40)
+	assert.NotEqual(t, spaced.key(key), long.key(key))
+}
+
+func TestMemcachedCachePool(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	defer server.Close()
+	cache := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{PoolSize: 2})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := fmt.Sprintf("key%d", i)
+			cache.Set(k, []byte(k))
+			assert.Equal(t, []byte(k), cache.Get(k))
+		}(i)
+	}
+	wg.Wait()
+
+	connections := server.connectionCount()
+	for i := 0; i < 20; i++ {
+		cache.Get(key)
+	}
+
+	assert.Equal(t, connections, server.connectionCount())
+	assert.True(t, connections <= 2)
+	assert.Len(t, cache.pool.idle, connections)
+}
+
+func TestMemcachedCacheServerDown(t *testing.T) {
+	t.Parallel()
+
+	server := newMemcachedTestServer()
+	server.Close()
+	cache := NewMemcachedCache(server.Addr(), MemcachedCacheOptions{Timeout: 100 * time.Millisecond})
+
+	cache.Set(key, []byte("hello"))
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, []byte{}, cache.Get(key))
+}
@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //OAuthPolicy holds the application related OAuth configuration
 type OAuthPolicy struct {
 	resource
@@ -9,14 +11,26 @@ type OAuthPolicy struct {
 
 //GetOAuthPolicy return the application OAuthPolicy
 func (app *Application) GetOAuthPolicy() (*OAuthPolicy, error) {
+	return app.GetOAuthPolicyWithContext(app.clientContext())
+}
+
+//GetOAuthPolicyWithContext is the same as GetOAuthPolicy with the addition of a context.Context
+func (app *Application) GetOAuthPolicyWithContext(ctx context.Context) (*OAuthPolicy, error) {
+	ctx = app.withClient(ctx)
 	oauthPolicy := &OAuthPolicy{}
 
-	err := client.get(app.OAuthPolicy.Href, oauthPolicy)
+	err := ClientFromContext(ctx).get(ctx, app.OAuthPolicy.Href, oauthPolicy)
 
 	return oauthPolicy, err
 }
 
 //Update OAuthPolicy
 func (policy *OAuthPolicy) Update() error {
-	return client.post(policy.Href, policy, policy)
+	return policy.UpdateWithContext(policy.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (policy *OAuthPolicy) UpdateWithContext(ctx context.Context) error {
+	ctx = policy.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, policy.Href, policy, policy)
 }
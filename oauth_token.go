@@ -1,6 +1,9 @@
 package stormpath
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
 
 //OAuthToken represents the Stormpath OAuthToken see: https://docs.stormpath.com/guides/token-management/
 type OAuthToken struct {
@@ -61,5 +64,11 @@ func MakeOAuthTokensCriteria() OAuthTokenCriteria {
 
 //Delete deletes the given OAuthToken
 func (t *OAuthToken) Delete() error {
-	return client.delete(t.Href)
+	return t.DeleteWithContext(t.clientContext())
+}
+
+//DeleteWithContext is the same as Delete with the addition of a context.Context
+func (t *OAuthToken) DeleteWithContext(ctx context.Context) error {
+	ctx = t.withClient(ctx)
+	return ClientFromContext(ctx).delete(ctx, t.Href)
 }
@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //Organization represnts the Stormpath organization resource, use for multitenancy
 type Organization struct {
 	accountStoreResource
@@ -27,14 +29,27 @@ func NewOrganization(name string, nameKey string) *Organization {
 
 //CreateOrganization creates new organization for the given tenant
 func (tenant *Tenant) CreateOrganization(org *Organization) error {
-	return client.post(buildRelativeURL("organizations"), org, org)
+	return tenant.CreateOrganizationWithContext(tenant.clientContext(), org)
+}
+
+//CreateOrganizationWithContext is the same as CreateOrganization with the addition of a context.Context
+func (tenant *Tenant) CreateOrganizationWithContext(ctx context.Context, org *Organization) error {
+	ctx = tenant.withClient(ctx)
+	c := ClientFromContext(ctx)
+	return c.post(ctx, c.buildRelativeURL("organizations"), org, org)
 }
 
 //GetOrganization loads an organization by href and criteria
 func GetOrganization(href string, criteria OrganizationCriteria) (*Organization, error) {
+	return GetOrganizationWithContext(context.Background(), href, criteria)
+}
+
+//GetOrganizationWithContext is the same as GetOrganization with the addition of a context.Context
+func GetOrganizationWithContext(ctx context.Context, href string, criteria OrganizationCriteria) (*Organization, error) {
 	organization := &Organization{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(href, criteria.toQueryString()),
 		organization,
 	)
@@ -44,46 +59,78 @@ func GetOrganization(href string, criteria OrganizationCriteria) (*Organization,
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (org *Organization) Refresh() error {
-	return client.get(org.Href, org)
+	return org.RefreshWithContext(org.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (org *Organization) RefreshWithContext(ctx context.Context) error {
+	ctx = org.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, org.Href, org)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (org *Organization) Update() error {
-	return client.post(org.Href, org, org)
+	return org.UpdateWithContext(org.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (org *Organization) UpdateWithContext(ctx context.Context) error {
+	ctx = org.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, org.Href, org, org)
 }
 
 //GetAccountStoreMappings returns all the applications account store mappings
 func (org *Organization) GetAccountStoreMappings(criteria OrganizationAccountStoreMappingCriteria) (*OrganizationAccountStoreMappings, error) {
+	return org.GetAccountStoreMappingsWithContext(org.clientContext(), criteria)
+}
+
+//GetAccountStoreMappingsWithContext is the same as GetAccountStoreMappings with the addition of a context.Context
+func (org *Organization) GetAccountStoreMappingsWithContext(ctx context.Context, criteria OrganizationAccountStoreMappingCriteria) (*OrganizationAccountStoreMappings, error) {
+	ctx = org.withClient(ctx)
 	accountStoreMappings := &OrganizationAccountStoreMappings{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(org.AccountStoreMappings.Href, criteria.toQueryString()),
 		accountStoreMappings,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return accountStoreMappings, nil
+	return accountStoreMappings, err
 }
 
 func (org *Organization) GetDefaultAccountStoreMapping(criteria OrganizationAccountStoreMappingCriteria) (*OrganizationAccountStoreMapping, error) {
-	err := client.get(
+	return org.GetDefaultAccountStoreMappingWithContext(org.clientContext(), criteria)
+}
+
+//GetDefaultAccountStoreMappingWithContext is the same as GetDefaultAccountStoreMapping with the addition of a context.Context
+func (org *Organization) GetDefaultAccountStoreMappingWithContext(ctx context.Context, criteria OrganizationAccountStoreMappingCriteria) (*OrganizationAccountStoreMapping, error) {
+	ctx = org.withClient(ctx)
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(org.DefaultAccountStoreMapping.Href, criteria.toQueryString()),
 		org.DefaultAccountStoreMapping,
 	)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return org.DefaultAccountStoreMapping, nil
+	return org.DefaultAccountStoreMapping, err
 }
 
 //RegisterAccount registers a new account into the organization
 func (org *Organization) RegisterAccount(account *Account) error {
-	err := client.post(org.Accounts.Href, account, account)
+	return org.RegisterAccountWithContext(org.clientContext(), account)
+}
+
+//RegisterAccountWithContext is the same as RegisterAccount with the addition of a context.Context
+func (org *Organization) RegisterAccountWithContext(ctx context.Context, account *Account) error {
+	ctx = org.withClient(ctx)
+	err := ClientFromContext(ctx).post(ctx, org.Accounts.Href, account, account)
 	if err == nil {
 		//Password should be cleanup so we don't keep an unhash password in memory
 		account.Password = ""
@@ -93,9 +140,15 @@ func (org *Organization) RegisterAccount(account *Account) error {
 
 //RegisterSocialAccount registers a new account into the organization using an external provider Google, Facebook
 func (org *Organization) RegisterSocialAccount(socialAccount *SocialAccount) (*Account, error) {
+	return org.RegisterSocialAccountWithContext(org.clientContext(), socialAccount)
+}
+
+//RegisterSocialAccountWithContext is the same as RegisterSocialAccount with the addition of a context.Context
+func (org *Organization) RegisterSocialAccountWithContext(ctx context.Context, socialAccount *SocialAccount) (*Account, error) {
+	ctx = org.withClient(ctx)
 	account := &Account{}
 
-	err := client.post(org.Accounts.Href, socialAccount, account)
+	err := ClientFromContext(ctx).post(ctx, org.Accounts.Href, socialAccount, account)
 
 	if err != nil {
 		return nil, err
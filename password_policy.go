@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 type PasswordPolicy struct {
 	resource
 	ResetTokenTTL              int             `json:"resetTokenTtl,omitempty"`
@@ -12,32 +14,56 @@ type PasswordPolicy struct {
 
 //Refresh refreshes the resource by doing a GET to the resource href endpoint
 func (policy *PasswordPolicy) Refresh() error {
-	return client.get(policy.Href, policy)
+	return policy.RefreshWithContext(policy.clientContext())
+}
+
+//RefreshWithContext is the same as Refresh with the addition of a context.Context
+func (policy *PasswordPolicy) RefreshWithContext(ctx context.Context) error {
+	ctx = policy.withClient(ctx)
+	return ClientFromContext(ctx).get(ctx, policy.Href, policy)
 }
 
 //Update updates the given resource, by doing a POST to the resource Href
 func (policy *PasswordPolicy) Update() error {
-	return client.post(policy.Href, policy, policy)
+	return policy.UpdateWithContext(policy.clientContext())
+}
+
+//UpdateWithContext is the same as Update with the addition of a context.Context
+func (policy *PasswordPolicy) UpdateWithContext(ctx context.Context) error {
+	ctx = policy.withClient(ctx)
+	return ClientFromContext(ctx).post(ctx, policy.Href, policy, policy)
 }
 
 //GetResetEmailTemplates loads the policy ResetEmailTemplates collection and returns it
 func (policy *PasswordPolicy) GetResetEmailTemplates() (*EmailTemplates, error) {
-	err := client.get(policy.ResetEmailTemplates.Href, policy.ResetEmailTemplates)
+	return policy.GetResetEmailTemplatesWithContext(policy.clientContext())
+}
+
+//GetResetEmailTemplatesWithContext is the same as GetResetEmailTemplates with the addition of a context.Context
+func (policy *PasswordPolicy) GetResetEmailTemplatesWithContext(ctx context.Context) (*EmailTemplates, error) {
+	ctx = policy.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, policy.ResetEmailTemplates.Href, policy.ResetEmailTemplates)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return policy.ResetEmailTemplates, nil
+	return policy.ResetEmailTemplates, err
 }
 
 //GetResetSuccessEmailTemplates loads the policy ResetSuccessEmailTemplates collection and returns it
 func (policy *PasswordPolicy) GetResetSuccessEmailTemplates() (*EmailTemplates, error) {
-	err := client.get(policy.ResetSuccessEmailTemplates.Href, policy.ResetSuccessEmailTemplates)
+	return policy.GetResetSuccessEmailTemplatesWithContext(policy.clientContext())
+}
+
+//GetResetSuccessEmailTemplatesWithContext is the same as GetResetSuccessEmailTemplates with the addition of a context.Context
+func (policy *PasswordPolicy) GetResetSuccessEmailTemplatesWithContext(ctx context.Context) (*EmailTemplates, error) {
+	ctx = policy.withClient(ctx)
+	err := ClientFromContext(ctx).get(ctx, policy.ResetSuccessEmailTemplates.Href, policy.ResetSuccessEmailTemplates)
 
-	if err != nil {
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return policy.ResetSuccessEmailTemplates, nil
+	return policy.ResetSuccessEmailTemplates, err
 }
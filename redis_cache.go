@@ -0,0 +1,180 @@
+package stormpath
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+//DefaultCacheKeyPrefix is the prefix of the keys stored by the remote caches if none is given
+const DefaultCacheKeyPrefix = "stormpath:"
+
+//RedisCacheOptions configures a RedisCache
+//
+//Prefix is prepended to every key, DefaultCacheKeyPrefix is used if empty.
+//TTL is passed to Redis as the expiration of the entries stored with Set, 0 means they don't expire. The client stores
+//its responses with SetWithTTL and the TTL of their cache region instead.
+//Password and DB are sent with AUTH and SELECT on every new connection when set.
+//PoolSize is the maximum number of connections opened to the server, a command waits up to Timeout for a connection
+//when all of them are in use. Timeout is also the deadline of every command,
+//DefaultCachePoolSize and DefaultCacheTimeout are used if 0.
+type RedisCacheOptions struct {
+	Prefix   string
+	TTL      time.Duration
+	Password string
+	DB       int
+	PoolSize int
+	Timeout  time.Duration
+}
+
+//RedisCache is a Cache backed by a Redis server so the cached resources are shared by every client using it.
+//
+//A write evicts the base href of the changed resources and the variants the writing client cached itself, the
+//variants only cached by other clients, e.g. with another expand query, stay cached until their TTL.
+//Cache errors are logged and handled as cache misses, a Redis outage never fails the SDK calls.
+type RedisCache struct {
+	prefix string
+	ttl    time.Duration
+	pool   *connPool
+}
+
+//NewRedisCache creates a RedisCache for the Redis server at address, e.g. localhost:6379
+func NewRedisCache(address string, options RedisCacheOptions) *RedisCache {
+	InitLog()
+
+	if options.Prefix == "" {
+		options.Prefix = DefaultCacheKeyPrefix
+	}
+
+	setup := func(conn *cacheConn) error {
+		if options.Password != "" {
+			if _, err := redisCommand(conn, "AUTH", options.Password); err != nil {
+				return err
+			}
+		}
+		if options.DB != 0 {
+			if _, err := redisCommand(conn, "SELECT", strconv.Itoa(options.DB)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	return &RedisCache{
+		prefix: options.Prefix,
+		ttl:    options.TTL,
+		pool:   newConnPool(address, options.PoolSize, options.Timeout, setup),
+	}
+}
+
+func (cache *RedisCache) Exists(key string) bool {
+	reply, err := cache.do("EXISTS", cache.prefix+key)
+	return err == nil && reply.integer > 0
+}
+
+func (cache *RedisCache) Set(key string, data []byte) {
+	cache.SetWithTTL(key, data, cache.ttl)
+}
+
+//SetWithTTL stores the data with the given expiration, 0 means it doesn't expire
+func (cache *RedisCache) SetWithTTL(key string, data []byte, ttl time.Duration) {
+	args := []string{cache.prefix + key, string(data)}
+	if ttl > 0 {
+		milliseconds := int64(ttl / time.Millisecond)
+		if milliseconds == 0 {
+			milliseconds = 1
+		}
+		args = append(args, "PX", strconv.FormatInt(milliseconds, 10))
+	}
+	cache.do("SET", args...)
+}
+
+func (cache *RedisCache) Get(key string) []byte {
+	data, _ := cache.GetIfPresent(key)
+	return data
+}
+
+func (cache *RedisCache) GetIfPresent(key string) ([]byte, bool) {
+	reply, err := cache.do("GET", cache.prefix+key)
+	if err != nil || reply.bulk == nil {
+		return []byte{}, false
+	}
+	return reply.bulk, true
+}
+
+func (cache *RedisCache) Del(key string) {
+	cache.do("DEL", cache.prefix+key)
+}
+
+//Close closes the idle connections to the Redis server
+func (cache *RedisCache) Close() error {
+	return cache.pool.close()
+}
+
+func (cache *RedisCache) do(command string, args ...string) (redisReply, error) {
+	var reply redisReply
+
+	err := cache.pool.do(func(conn *cacheConn) (err error) {
+		reply, err = redisCommand(conn, command, args...)
+		return err
+	})
+	if err != nil {
+		Logger.Printf("[ERROR] Redis cache %s failed [%s]", command, err)
+	}
+	return reply, err
+}
+
+//redisReply holds the integer or bulk string reply of a Redis command, bulk is nil for null replies
+type redisReply struct {
+	integer int64
+	bulk    []byte
+}
+
+//redisCommand sends the command as a RESP array of bulk strings and reads its reply
+func redisCommand(conn *cacheConn, command string, args ...string) (redisReply, error) {
+	fmt.Fprintf(conn.writer, "*%d\r\n$%d\r\n%s\r\n", len(args)+1, len(command), command)
+	for _, arg := range args {
+		fmt.Fprintf(conn.writer, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := conn.writer.Flush(); err != nil {
+		return redisReply{}, err
+	}
+
+	return readRedisReply(conn)
+}
+
+func readRedisReply(conn *cacheConn) (redisReply, error) {
+	reply := redisReply{}
+
+	line, err := readCacheLine(conn)
+	if err != nil {
+		return reply, err
+	}
+	if line == "" {
+		return reply, errors.New("empty Redis reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return reply, nil
+	case '-':
+		return reply, fmt.Errorf("Redis error: %s", line[1:])
+	case ':':
+		reply.integer, err = strconv.ParseInt(line[1:], 10, 64)
+		return reply, err
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return reply, err
+		}
+		reply.bulk = make([]byte, size+2)
+		if _, err := io.ReadFull(conn.reader, reply.bulk); err != nil {
+			return reply, err
+		}
+		reply.bulk = reply.bulk[:size]
+		return reply, nil
+	}
+	return reply, fmt.Errorf("unsupported Redis reply %q", line)
+}
@@ -0,0 +1,272 @@
+package stormpath
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//redisTestServer is an in-process stand-in for a Redis server implementing the commands used by RedisCache
+type redisTestServer struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	values      map[string]string
+	expires     map[string]time.Time
+	commands    [][]string
+	connections int
+}
+
+func newRedisTestServer() *redisTestServer {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	s := &redisTestServer{listener: listener, values: map[string]string{}, expires: map[string]time.Time{}}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.connections++
+			s.mutex.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *redisTestServer) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *redisTestServer) Close() {
+	s.listener.Close()
+}
+
+func (s *redisTestServer) command(i int) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.commands[i]
+}
+
+func (s *redisTestServer) stored(key string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	_, ok := s.values[key]
+	return ok
+}
+
+func (s *redisTestServer) connectionCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.connections
+}
+
+func (s *redisTestServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		count, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+		args := []string{}
+		for i := 0; i < count; i++ {
+			line, _ = reader.ReadString('\n')
+			size, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+			arg := make([]byte, size+2)
+			io.ReadFull(reader, arg)
+			args = append(args, string(arg[:size]))
+		}
+
+		conn.Write([]byte(s.execute(args)))
+	}
+}
+
+func (s *redisTestServer) execute(args []string) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.commands = append(s.commands, args)
+
+	if len(args) > 1 {
+		if expires, ok := s.expires[args[1]]; ok && expires.Before(time.Now()) {
+			delete(s.values, args[1])
+			delete(s.expires, args[1])
+		}
+	}
+
+	switch args[0] {
+	case "AUTH":
+		if args[1] != "secret" {
+			return "-WRONGPASS invalid password\r\n"
+		}
+		return "+OK\r\n"
+	case "SELECT":
+		return "+OK\r\n"
+	case "SET":
+		s.values[args[1]] = args[2]
+		if len(args) == 5 && args[3] == "PX" {
+			milliseconds, _ := strconv.Atoi(args[4])
+			s.expires[args[1]] = time.Now().Add(time.Duration(milliseconds) * time.Millisecond)
+		}
+		return "+OK\r\n"
+	case "GET":
+		value, ok := s.values[args[1]]
+		if !ok {
+			return "$-1\r\n"
+		}
+		return fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+	case "EXISTS":
+		if _, ok := s.values[args[1]]; ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "DEL":
+		delete(s.values, args[1])
+		return ":1\r\n"
+	}
+	return "-ERR unknown command\r\n"
+}
+
+func TestRedisCache(t *testing.T) {
+	t.Parallel()
+
+	server := newRedisTestServer()
+	defer server.Close()
+	cache := NewRedisCache(server.Addr(), RedisCacheOptions{})
+	defer cache.Close()
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, []byte{}, cache.Get(key))
+
+	cache.Set(key, []byte("hello\r\nworld"))
+
+	assert.True(t, cache.Exists(key))
+	assert.Equal(t, []byte("hello\r\nworld"), cache.Get(key))
+	assert.True(t, server.stored(DefaultCacheKeyPrefix+key))
+
+	cache.Del(key)
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, 1, server.connectionCount())
+}
+
+func TestRedisCacheTTLAndPrefix(t *testing.T) {
+	t.Parallel()
+
+	server := newRedisTestServer()
+	defer server.Close()
+	cache := NewRedisCache(server.Addr(), RedisCacheOptions{Prefix: "app:", TTL: 100 * time.Millisecond})
+	defer cache.Close()
+
+	cache.Set(key, []byte("hello"))
+
+	assert.Equal(t, []string{"SET", "app:" + key, "hello", "PX", "100"}, server.command(0))
+	assert.True(t, cache.Exists(key))
+
+	time.Sleep(200 * time.Millisecond)
+
+	assert.False(t, cache.Exists(key))
+}
+
+func TestClientPassesRegionTTLToRedis(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"href":"http://%s%s","name":"ttl"}`, r.Host, r.URL.Path)
+	})
+	defer server.Close()
+	redis := newRedisTestServer()
+	defer redis.Close()
+	cache := NewRedisCache(redis.Addr(), RedisCacheOptions{})
+	defer cache.Close()
+
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	config.CacheTTL = time.Minute
+	config.CacheStaleIfError = 5 * time.Second
+	config.CacheRegions = map[string]CacheRegionConfiguration{CacheRegionAccounts: {TTL: 10 * time.Second}}
+	ctx, _ := newTestContext(config, cache)
+
+	_, err := GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+	assert.NoError(t, err)
+	_, err = GetGroupWithContext(ctx, server.URL+"/v1/groups/1", MakeGroupCriteria())
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"PX", "15000"}, redis.command(1)[3:])
+	assert.Equal(t, []string{"PX", "65000"}, redis.command(3)[3:])
+}
+
+func TestRedisCacheAuthAndSelect(t *testing.T) {
+	t.Parallel()
+
+	server := newRedisTestServer()
+	defer server.Close()
+	cache := NewRedisCache(server.Addr(), RedisCacheOptions{Password: "secret", DB: 2})
+	defer cache.Close()
+
+	cache.Set(key, []byte("hello"))
+
+	assert.Equal(t, []string{"AUTH", "secret"}, server.command(0))
+	assert.Equal(t, []string{"SELECT", "2"}, server.command(1))
+	assert.Equal(t, []byte("hello"), cache.Get(key))
+
+	wrongPassword := NewRedisCache(server.Addr(), RedisCacheOptions{Password: "wrong"})
+	defer wrongPassword.Close()
+
+	assert.False(t, wrongPassword.Exists(key))
+}
+
+func TestRedisCachePool(t *testing.T) {
+	t.Parallel()
+
+	server := newRedisTestServer()
+	defer server.Close()
+	cache := NewRedisCache(server.Addr(), RedisCacheOptions{PoolSize: 2})
+	defer cache.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			k := fmt.Sprintf("key%d", i)
+			cache.Set(k, []byte(k))
+			assert.Equal(t, []byte(k), cache.Get(k))
+		}(i)
+	}
+	wg.Wait()
+
+	connections := server.connectionCount()
+	for i := 0; i < 20; i++ {
+		cache.Get(key)
+	}
+
+	assert.Equal(t, connections, server.connectionCount())
+	assert.True(t, connections <= 2)
+	assert.Len(t, cache.pool.idle, connections)
+}
+
+func TestRedisCacheServerDown(t *testing.T) {
+	t.Parallel()
+
+	server := newRedisTestServer()
+	server.Close()
+	cache := NewRedisCache(server.Addr(), RedisCacheOptions{Timeout: 100 * time.Millisecond})
+
+	cache.Set(key, []byte("hello"))
+
+	assert.False(t, cache.Exists(key))
+	assert.Equal(t, []byte{}, cache.Get(key))
+}
@@ -0,0 +1,234 @@
+package stormpath
+
+import (
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//Cache region names, a region groups the cache entries of a resource type
+const (
+	CacheRegionAccounts      = "accounts"
+	CacheRegionGroups        = "groups"
+	CacheRegionDirectories   = "directories"
+	CacheRegionApplications  = "applications"
+	CacheRegionOrganizations = "organizations"
+	CacheRegionCustomData    = "customData"
+	CacheRegionAPIKeys       = "apiKeys"
+)
+
+//CacheRegions lists the cache regions that can be configured under stormpath.client.cacheManager.caches
+var CacheRegions = []string{
+	CacheRegionAccounts,
+	CacheRegionGroups,
+	CacheRegionDirectories,
+	CacheRegionApplications,
+	CacheRegionOrganizations,
+	CacheRegionCustomData,
+	CacheRegionAPIKeys,
+}
+
+//CacheRegionConfiguration holds the TTL and TTI of a cache region
+type CacheRegionConfiguration struct {
+	TTL time.Duration
+	TTI time.Duration
+}
+
+//DefaultCacheRegionBasePath is the path of the Stormpath API base URL, CacheRegion finds the kind of a resource after it
+const DefaultCacheRegionBasePath = "/v1"
+
+//CacheRegion returns the cache region of the given cache key or resource href of the Stormpath API,
+//the custom data of any resource belongs to the customData region and any other resource to the region named
+//after its kind, e.g. accounts for https://api.stormpath.com/v1/accounts/id?expand=groups
+func CacheRegion(key string) string {
+	return cacheRegion(key, DefaultCacheRegionBasePath)
+}
+
+//cacheRegion returns the cache region of the given cache key of an API with the given base path,
+//the kind of a resource is the first segment of its path after the base path
+func cacheRegion(key string, basePath string) string {
+	u, err := url.Parse(key)
+	if err != nil {
+		return ""
+	}
+
+	path := strings.Trim(u.Path, "/")
+	if base := strings.Trim(basePath, "/"); base != "" {
+		if path != base && !strings.HasPrefix(path, base+"/") {
+			return ""
+		}
+		path = strings.TrimPrefix(strings.TrimPrefix(path, base), "/")
+	}
+	if path == "" {
+		return ""
+	}
+
+	segments := strings.Split(path, "/")
+	for _, segment := range segments {
+		if segment == CacheRegionCustomData {
+			return CacheRegionCustomData
+		}
+	}
+	return segments[0]
+}
+
+//cacheRegionBasePath returns the path of the given API base URL
+func cacheRegionBasePath(baseURL string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return DefaultCacheRegionBasePath
+	}
+	return u.Path
+}
+
+//RegionCache is a Cache that stores each entry in the cache of its region, the entries of a region
+//without cache are stored in the Default cache.
+//
+//BasePath is the path of the base URL of the cached hrefs, the region of an entry is the kind of resource that
+//follows it, NewRegionCache sets it to DefaultCacheRegionBasePath and NewClient to the path of its BaseURL.
+type RegionCache struct {
+	Default  Cache
+	Regions  map[string]Cache
+	BasePath string
+}
+
+//NewRegionCache creates a RegionCache with a LocalCache for the default region and for every configured region,
+//each LocalCache has the region TTL and TTI.
+//
+//The maxEntries and maxBytes limits bound the whole RegionCache, they are split evenly between the default region
+//and the configured regions so each LocalCache holds at most its share of the entries and bytes.
+func NewRegionCache(ttl time.Duration, tti time.Duration, regions map[string]CacheRegionConfiguration, maxEntries int, maxBytes int64) *RegionCache {
+	caches := int64(len(regions) + 1)
+	maxEntries = int(splitCacheLimit(int64(maxEntries), caches))
+	maxBytes = splitCacheLimit(maxBytes, caches)
+
+	cache := &RegionCache{
+		Default:  NewBoundedLocalCache(ttl, tti, maxEntries, maxBytes),
+		Regions:  map[string]Cache{},
+		BasePath: DefaultCacheRegionBasePath,
+	}
+	for name, region := range regions {
+		cache.Regions[name] = NewBoundedLocalCache(region.TTL, region.TTI, maxEntries, maxBytes)
+	}
+	return cache
+}
+
+//splitCacheLimit returns the share of the limit of each of the given number of caches, rounded up so a limit
+//never becomes 0 which means no limit
+func splitCacheLimit(limit int64, caches int64) int64 {
+	if limit <= 0 {
+		return limit
+	}
+	return (limit + caches - 1) / caches
+}
+
+func (cache *RegionCache) region(key string) Cache {
+	if c, ok := cache.Regions[cacheRegion(key, cache.BasePath)]; ok {
+		return c
+	}
+	return cache.Default
+}
+
+func (cache *RegionCache) Exists(key string) bool {
+	return cache.region(key).Exists(key)
+}
+
+func (cache *RegionCache) Set(key string, data []byte) {
+	cache.region(key).Set(key, data)
+}
+
+//SetWithTTL stores the data with the given TTL if the cache of its region implements TTLSetter
+func (cache *RegionCache) SetWithTTL(key string, data []byte, ttl time.Duration) {
+	setWithTTL(cache.region(key), key, data, ttl)
+}
+
+func (cache *RegionCache) Get(key string) []byte {
+	return cache.region(key).Get(key)
+}
+
+func (cache *RegionCache) Del(key string) {
+	cache.region(key).Del(key)
+}
+
+func (cache *RegionCache) GetIfPresent(key string) ([]byte, bool) {
+	return getIfPresent(cache.region(key), key)
+}
+
+//Close closes the region caches implementing io.Closer, it returns the first error if any
+func (cache *RegionCache) Close() error {
+	var err error
+	for _, c := range cache.caches() {
+		if closer, ok := c.(io.Closer); ok {
+			if e := closer.Close(); e != nil && err == nil {
+				err = e
+			}
+		}
+	}
+	return err
+}
+
+//Evictions returns the evictions of the region caches implementing CacheEvictionCounter
+func (cache *RegionCache) Evictions() uint64 {
+	var evictions uint64
+	for _, c := range cache.caches() {
+		if counter, ok := c.(CacheEvictionCounter); ok {
+			evictions += counter.Evictions()
+		}
+	}
+	return evictions
+}
+
+//Expirations returns the expirations of the region caches implementing CacheEvictionCounter
+func (cache *RegionCache) Expirations() uint64 {
+	var expirations uint64
+	for _, c := range cache.caches() {
+		if counter, ok := c.(CacheEvictionCounter); ok {
+			expirations += counter.Expirations()
+		}
+	}
+	return expirations
+}
+
+//Entries returns the entries of the region caches implementing CacheSnapshotter
+func (cache *RegionCache) Entries() []CacheEntry {
+	var entries []CacheEntry
+	for _, c := range cache.caches() {
+		if snapshotter, ok := c.(CacheSnapshotter); ok {
+			entries = append(entries, snapshotter.Entries()...)
+		}
+	}
+	return entries
+}
+
+//Restore restores every entry in the cache of its region if it implements CacheSnapshotter
+func (cache *RegionCache) Restore(entries []CacheEntry) int {
+	regions := map[string][]CacheEntry{}
+	for _, entry := range entries {
+		region := cacheRegion(entry.Key, cache.BasePath)
+		if _, ok := cache.Regions[region]; !ok {
+			region = ""
+		}
+		regions[region] = append(regions[region], entry)
+	}
+
+	restored := 0
+	for region, regionEntries := range regions {
+		c := cache.Default
+		if region != "" {
+			c = cache.Regions[region]
+		}
+		if snapshotter, ok := c.(CacheSnapshotter); ok {
+			restored += snapshotter.Restore(regionEntries)
+		}
+	}
+	return restored
+}
+
+func (cache *RegionCache) caches() []Cache {
+	caches := []Cache{cache.Default}
+	for _, c := range cache.Regions {
+		caches = append(caches, c)
+	}
+	return caches
+}
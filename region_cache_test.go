@@ -0,0 +1,117 @@
+package stormpath
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheRegion(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, CacheRegionAccounts, CacheRegion("https://api.stormpath.com/v1/accounts/1?expand=groups"))
+	assert.Equal(t, CacheRegionAccounts, CacheRegion("https://api.stormpath.com/v1/accounts/1/groups"))
+	assert.Equal(t, CacheRegionDirectories, CacheRegion("https://api.stormpath.com/v1/directories/1"))
+	assert.Equal(t, CacheRegionCustomData, CacheRegion("https://api.stormpath.com/v1/accounts/1/customData"))
+	assert.Equal(t, CacheRegionCustomData, CacheRegion("https://api.stormpath.com/v1/groups/1/customData/plan"))
+	assert.Equal(t, CacheRegionAPIKeys, CacheRegion("https://api.stormpath.com/v1/apiKeys/1"))
+	assert.Equal(t, "", CacheRegion("https://api.stormpath.com/v1"))
+}
+
+func TestCacheRegionRelativeToBasePath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, CacheRegionAccounts, cacheRegion("https://example.com/api/stormpath/v1/accounts/1", "/api/stormpath/v1/"))
+	assert.Equal(t, CacheRegionCustomData, cacheRegion("https://example.com/api/stormpath/v1/groups/1/customData", "/api/stormpath/v1"))
+	assert.Equal(t, CacheRegionDirectories, cacheRegion("https://example.com/directories/1", ""))
+	assert.Equal(t, "", cacheRegion("https://example.com/api/stormpath/v1", "/api/stormpath/v1"))
+	assert.Equal(t, "", cacheRegion("https://example.com/other/accounts/1", "/api/stormpath/v1"))
+	assert.Equal(t, "/api/stormpath/v1/", cacheRegionBasePath("https://example.com/api/stormpath/v1/"))
+}
+
+func TestRegionCacheUsesRegionTTL(t *testing.T) {
+	t.Parallel()
+
+	cache := NewRegionCache(time.Minute, time.Minute, map[string]CacheRegionConfiguration{
+		CacheRegionAccounts: {TTL: time.Second, TTI: time.Second},
+	}, 0, 0)
+	account := "https://api.stormpath.com/v1/accounts/1"
+	directory := "https://api.stormpath.com/v1/directories/1"
+
+	cache.Set(account, []byte("account"))
+	cache.Set(directory, []byte("directory"))
+
+	assert.True(t, cache.Regions[CacheRegionAccounts].Exists(account))
+	assert.False(t, cache.Default.Exists(account))
+	assert.True(t, cache.Default.Exists(directory))
+
+	time.Sleep(2 * time.Second)
+
+	assert.False(t, cache.Exists(account))
+	assert.Equal(t, []byte("directory"), cache.Get(directory))
+
+	cache.Del(directory)
+	assert.False(t, cache.Exists(directory))
+}
+
+func TestNewClientWithCacheRegions(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("regionKeyID", "regionKeySecret")
+	config.CacheManagerEnabled = true
+	config.CacheRegions = map[string]CacheRegionConfiguration{
+		CacheRegionDirectories: {TTL: time.Hour, TTI: time.Hour},
+	}
+
+	client := NewClient(config, nil)
+	cache, ok := client.Cache.(*RegionCache)
+
+	assert.True(t, ok)
+	assert.Len(t, cache.Regions, 1)
+	assert.Contains(t, cache.Regions, CacheRegionDirectories)
+}
+
+func TestNewClientWithCacheRegionsAndBaseURLPath(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("regionKeyID", "regionKeySecret")
+	config.BaseURL = "https://example.com/api/stormpath/v1/"
+	config.CacheManagerEnabled = true
+	config.CacheRegions = map[string]CacheRegionConfiguration{
+		CacheRegionDirectories: {TTL: time.Hour, TTI: time.Hour},
+	}
+	directory := "https://example.com/api/stormpath/v1/directories/1"
+
+	client := NewClient(config, nil)
+	cache := client.Cache.(*RegionCache)
+	cache.Set(directory, []byte("directory"))
+
+	assert.Equal(t, "/api/stormpath/v1/", cache.BasePath)
+	assert.True(t, cache.Regions[CacheRegionDirectories].Exists(directory))
+	assert.False(t, cache.Default.Exists(directory))
+	assert.Equal(t, time.Hour, config.cacheTTL(directory))
+}
+
+func TestRegionCacheSplitsLimits(t *testing.T) {
+	t.Parallel()
+
+	regions := map[string]CacheRegionConfiguration{
+		CacheRegionAccounts: {TTL: time.Minute, TTI: time.Minute},
+		CacheRegionGroups:   {TTL: time.Minute, TTI: time.Minute},
+	}
+	cache := NewRegionCache(time.Minute, time.Minute, regions, 7, 0)
+	defer cache.Close()
+
+	for i := 0; i < 10; i++ {
+		cache.Set(fmt.Sprintf("https://api.stormpath.com/v1/accounts/%d", i), []byte("account"))
+		cache.Set(fmt.Sprintf("https://api.stormpath.com/v1/groups/%d", i), []byte("group"))
+		cache.Set(fmt.Sprintf("https://api.stormpath.com/v1/directories/%d", i), []byte("directory"))
+	}
+
+	assert.Len(t, cache.Entries(), 9)
+	assert.Equal(t, 3, cache.Default.(*LocalCache).Count())
+	assert.Equal(t, int64(1), splitCacheLimit(1, 3))
+	assert.Equal(t, int64(0), splitCacheLimit(0, 3))
+}
@@ -0,0 +1,47 @@
+package stormpath
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+)
+
+//Authentication schemes supported by the ClientConfiguration AuthenticationScheme
+const (
+	SAuthc1Scheme = "SAUTHC1"
+	BasicScheme   = "BASIC"
+)
+
+//RequestAuthenticator authenticates the outgoing Stormpath requests with the given API key
+type RequestAuthenticator interface {
+	Authenticate(req *http.Request, payload []byte, apiKeyID string, apiKeySecret string)
+}
+
+//SAuthc1RequestAuthenticator authenticates requests using the Stormpath SAuthc1 digest algorithm,
+//it is the default and recommended scheme since the API key secret is never sent over the wire
+type SAuthc1RequestAuthenticator struct{}
+
+//BasicRequestAuthenticator authenticates requests using HTTP Basic authentication,
+//it can be used behind proxies that rewrite headers and break the SAuthc1 canonical request
+type BasicRequestAuthenticator struct{}
+
+//NewRequestAuthenticator returns the RequestAuthenticator for the given authentication scheme name,
+//if the scheme is not supported a warning is logged and it defaults to SAuthc1.
+//
+//NewClient resolves the configured scheme once, so an unsupported scheme is reported once per client.
+func NewRequestAuthenticator(scheme string) RequestAuthenticator {
+	switch strings.ToUpper(scheme) {
+	case BasicScheme:
+		return BasicRequestAuthenticator{}
+	case SAuthc1Scheme, "":
+		return SAuthc1RequestAuthenticator{}
+	}
+
+	Logger.Printf("[WARN] Unsupported authentication scheme %s, using %s", scheme, SAuthc1Scheme)
+	return SAuthc1RequestAuthenticator{}
+}
+
+//Authenticate sets the Authorization header with the API key id and secret
+func (BasicRequestAuthenticator) Authenticate(req *http.Request, payload []byte, apiKeyID string, apiKeySecret string) {
+	req.Header.Set(AuthorizationHeader, "Basic "+base64.StdEncoding.EncodeToString([]byte(apiKeyID+":"+apiKeySecret)))
+}
@@ -0,0 +1,75 @@
+package stormpath
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewRequestAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, SAuthc1RequestAuthenticator{}, NewRequestAuthenticator("SAUTHC1"))
+	assert.Equal(t, SAuthc1RequestAuthenticator{}, NewRequestAuthenticator(""))
+	assert.Equal(t, BasicRequestAuthenticator{}, NewRequestAuthenticator("BASIC"))
+	assert.Equal(t, BasicRequestAuthenticator{}, NewRequestAuthenticator("basic"))
+	assert.Equal(t, SAuthc1RequestAuthenticator{}, NewRequestAuthenticator("DIGEST"))
+}
+
+func TestBasicRequestAuthenticator(t *testing.T) {
+	t.Parallel()
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.stormpath.com/v1/tenants/current", nil)
+
+	BasicRequestAuthenticator{}.Authenticate(req, []byte{}, "MyId", "Shush!")
+
+	id, secret, ok := req.BasicAuth()
+	assert.True(t, ok)
+	assert.Equal(t, "MyId", id)
+	assert.Equal(t, "Shush!", secret)
+}
+
+func TestClientUsesConfiguredAuthenticationScheme(t *testing.T) {
+	t.Parallel()
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get(AuthorizationHeader)
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"tenant"}`))
+	}))
+	defer server.Close()
+
+	config := LoadConfigurationWithCreds("MyId", "Shush!")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+	config.AuthenticationScheme = BasicScheme
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Basic TXlJZDpTaHVzaCE=", authorization)
+
+	config.AuthenticationScheme = SAuthc1Scheme
+
+	_, err = CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.NoError(t, err)
+	assert.Contains(t, authorization, AuthenticationScheme+" "+SAUTHC1Id+"=MyId/")
+}
+
+func TestNewClientResolvesAuthenticationSchemeOnce(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("MyId", "Shush!")
+	config.CacheManagerEnabled = false
+
+	config.AuthenticationScheme = "basic"
+	assert.Equal(t, BasicRequestAuthenticator{}, NewClient(config, nil).authenticator)
+
+	config.AuthenticationScheme = "DIGEST"
+	assert.Equal(t, SAuthc1RequestAuthenticator{}, NewClient(config, nil).authenticator)
+}
@@ -0,0 +1,68 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var errIncompleteRequest = errors.New("coalesced request did not complete")
+
+//errAbandonedRequest is returned to the waiting callers when the caller executing the request gave up on it,
+//the request tells nothing about Stormpath so the waiting callers execute it again
+var errAbandonedRequest = errors.New("coalesced request abandoned by its caller")
+
+//inflightRequest is a request in flight whose response is shared by every caller requesting the same key
+type inflightRequest struct {
+	done      chan struct{}
+	data      []byte
+	err       error
+	abandoned bool
+}
+
+//requestGroup deduplicates concurrent identical requests, only the first caller for a key executes the request
+//and the callers arriving while it is in flight wait for its response
+type requestGroup struct {
+	mutex    sync.Mutex
+	requests map[string]*inflightRequest
+}
+
+func newRequestGroup() *requestGroup {
+	return &requestGroup{requests: map[string]*inflightRequest{}}
+}
+
+//do executes fn once for the concurrent callers with the same key, shared is true for the callers that got
+//the response of another caller. Waiting callers stop waiting when their ctx is done.
+//
+//If fn fails once the ctx of the executing caller is done, the waiting callers get errAbandonedRequest, any other
+//error, timeouts included, is shared as is.
+func (group *requestGroup) do(ctx context.Context, key string, fn func() ([]byte, error)) (data []byte, shared bool, err error) {
+	group.mutex.Lock()
+	if request, ok := group.requests[key]; ok {
+		group.mutex.Unlock()
+
+		select {
+		case <-request.done:
+			if request.abandoned {
+				return nil, true, errAbandonedRequest
+			}
+			return request.data, true, request.err
+		case <-ctx.Done():
+			return nil, true, ctx.Err()
+		}
+	}
+	request := &inflightRequest{done: make(chan struct{}), err: errIncompleteRequest}
+	group.requests[key] = request
+	group.mutex.Unlock()
+
+	defer func() {
+		group.mutex.Lock()
+		delete(group.requests, key)
+		group.mutex.Unlock()
+		close(request.done)
+	}()
+
+	request.data, request.err = fn()
+	request.abandoned = request.err != nil && ctx.Err() != nil
+	return request.data, false, request.err
+}
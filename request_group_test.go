@@ -0,0 +1,175 @@
+package stormpath
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newCoalescingTestServer(delay time.Duration) *testServer {
+	var server *testServer
+	server = newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		w.Write([]byte(`{"href":"` + server.URL + `/v1/accounts/1","username":"john"}`))
+	})
+	return server
+}
+
+func newCoalescingTestClient(server *testServer) *Client {
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+
+	_, client := newTestContext(config, NewLocalCache(time.Minute, time.Minute))
+	return client
+}
+
+func TestConcurrentGetsAreCoalesced(t *testing.T) {
+	t.Parallel()
+
+	server := newCoalescingTestServer(200 * time.Millisecond)
+	defer server.Close()
+	client := newCoalescingTestClient(server)
+	defer client.Cache.(*LocalCache).Close()
+	ctx := NewContext(context.Background(), client)
+
+	var wg sync.WaitGroup
+	accounts := make([]*Account, 20)
+	for i := range accounts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			account, err := GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+			assert.NoError(t, err)
+			accounts[i] = account
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, server.received(), 1)
+	for _, account := range accounts {
+		assert.Equal(t, "john", account.Username)
+	}
+
+	stats := client.CacheStats()
+	assert.Equal(t, uint64(19), stats.Coalesced+stats.Hits)
+	assert.Equal(t, uint64(1), stats.Sets)
+}
+
+func TestCoalescedGetRetriesWhenLeaderGivesUp(t *testing.T) {
+	t.Parallel()
+
+	server := newCoalescingTestServer(200 * time.Millisecond)
+	defer server.Close()
+	client := newCoalescingTestClient(server)
+	defer client.Cache.(*LocalCache).Close()
+	href := server.URL + "/v1/accounts/1"
+
+	leaderCtx, cancel := context.WithTimeout(NewContext(context.Background(), client), 50*time.Millisecond)
+	defer cancel()
+
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := GetAccountWithContext(leaderCtx, href, MakeAccountCriteria())
+		leaderErr <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	account, err := GetAccountWithContext(NewContext(context.Background(), client), href, MakeAccountCriteria())
+
+	assert.NoError(t, err)
+	assert.Equal(t, "john", account.Username)
+	assert.Error(t, <-leaderErr)
+	assert.Len(t, server.received(), 2)
+}
+
+func TestCoalescedGetSharesTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	config.ConnectionTimeout = 1
+	ctx, client := newTestContext(config, nil)
+	defer client.Close()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+			assert.Error(t, err)
+		}()
+	}
+	wg.Wait()
+
+	//The timeout of the executing caller isn't a caller giving up, the waiting callers get its error
+	assert.True(t, time.Since(start) < 1900*time.Millisecond)
+	assert.Len(t, server.received(), 1)
+}
+
+func TestRequestGroupWaiterContext(t *testing.T) {
+	t.Parallel()
+
+	group := newRequestGroup()
+	release := make(chan struct{})
+	started := make(chan struct{})
+
+	go group.do(context.Background(), key, func() ([]byte, error) {
+		close(started)
+		<-release
+		return []byte("data"), nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, shared, err := group.do(ctx, key, func() ([]byte, error) {
+		return nil, nil
+	})
+
+	assert.True(t, shared)
+	assert.Equal(t, context.Canceled, err)
+
+	close(release)
+}
+
+func TestRequestGroupPanic(t *testing.T) {
+	t.Parallel()
+
+	group := newRequestGroup()
+	started := make(chan struct{})
+	panicked := make(chan struct{})
+
+	go func() {
+		defer close(panicked)
+		defer func() { recover() }()
+		group.do(context.Background(), key, func() ([]byte, error) {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			panic("boom")
+		})
+	}()
+	<-started
+
+	_, shared, err := group.do(context.Background(), key, func() ([]byte, error) {
+		return []byte("data"), nil
+	})
+	<-panicked
+
+	assert.True(t, shared)
+	assert.Equal(t, errIncompleteRequest, err)
+}
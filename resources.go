@@ -1,6 +1,9 @@
 package stormpath
 
 import (
+	"context"
+	"io"
+	"reflect"
 	"strings"
 	"time"
 )
@@ -32,19 +35,91 @@ func (r collectionResource) GetSize() int {
 }
 
 //resource resprents the basic attributes of any resource (Application, Group, Account, etc.)
+//
+//A resource remembers the Client it was fetched with, the calls without context.Context are executed by that Client.
 type resource struct {
 	Href       string     `json:"href,omitempty"`
 	CreatedAt  *time.Time `json:"createdAt,omitempty"`
 	ModifiedAt *time.Time `json:"modifiedAt,omitempty"`
+
+	client *Client
 }
 
 func (r resource) IsCacheable() bool {
 	return true
 }
 
+//clientBinder is implemented by every resource to remember the Client it was fetched with
+type clientBinder interface {
+	bindClient(c *Client)
+}
+
+func (r *resource) bindClient(c *Client) {
+	r.client = c
+}
+
+//clientContext returns a context.Context bound to the Client the resource was fetched with,
+//the default client is used for the resources not fetched by any Client
+func (r *resource) clientContext() context.Context {
+	return r.withClient(context.Background())
+}
+
+//withClient resolves the Client of the WithContext calls of the resource: the Client bound to ctx if any,
+//else the Client the resource was fetched with, else the default client
+func (r *resource) withClient(ctx context.Context) context.Context {
+	if c, ok := ctx.Value(clientContextKey{}).(*Client); (ok && c != nil) || r.client == nil {
+		return ctx
+	}
+	return NewContext(ctx, r.client)
+}
+
+//applicationContext returns the context.Context of the calls of an authenticator without context.Context
+func applicationContext(app *Application) context.Context {
+	if app == nil {
+		return context.Background()
+	}
+	return app.clientContext()
+}
+
+//bindClient binds the given Client to every resource of a decoded result, including the expanded resources
+//and the items of the collections
+func bindClient(result interface{}, c *Client) {
+	bindClientValue(reflect.ValueOf(result), c)
+}
+
+func bindClientValue(v reflect.Value, c *Client) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			bindClientValue(v.Elem(), c)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			bindClientValue(v.Index(i), c)
+		}
+	case reflect.Struct:
+		if v.CanAddr() && v.Addr().CanInterface() {
+			if binder, ok := v.Addr().Interface().(clientBinder); ok {
+				binder.bindClient(c)
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.PkgPath == "" || field.Anonymous {
+				bindClientValue(v.Field(i), c)
+			}
+		}
+	}
+}
+
 //Delete deletes the given account, it wont modify the calling account
 func (r *resource) Delete() error {
-	return client.delete(r.Href)
+	return r.DeleteWithContext(r.clientContext())
+}
+
+//DeleteWithContext is the same as Delete with the addition of a context.Context
+func (r *resource) DeleteWithContext(ctx context.Context) error {
+	ctx = r.withClient(ctx)
+	return ClientFromContext(ctx).delete(ctx, r.Href)
 }
 
 type accountStoreResource struct {
@@ -57,9 +132,16 @@ type accountStoreResource struct {
 //
 //See: http://docs.stormpath.com/rest/product-guide/#application-accounts
 func (r *accountStoreResource) GetAccounts(criteria AccountCriteria) (*Accounts, error) {
+	return r.GetAccountsWithContext(r.clientContext(), criteria)
+}
+
+//GetAccountsWithContext is the same as GetAccounts with the addition of a context.Context
+func (r *accountStoreResource) GetAccountsWithContext(ctx context.Context, criteria AccountCriteria) (*Accounts, error) {
+	ctx = r.withClient(ctx)
 	accounts := &Accounts{}
 
-	err := client.get(
+	err := ClientFromContext(ctx).get(
+		ctx,
 		buildAbsoluteURL(r.Accounts.Href, criteria.toQueryString()),
 		accounts,
 	)
@@ -67,6 +149,48 @@ func (r *accountStoreResource) GetAccounts(criteria AccountCriteria) (*Accounts,
 	return accounts, err
 }
 
+//IterateAccounts returns an AccountIterator over all the accounts within a context of: application, directory, group, organization, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (r *accountStoreResource) IterateAccounts(criteria AccountCriteria) *AccountIterator {
+	return r.IterateAccountsWithContext(r.clientContext(), criteria)
+}
+
+//IterateAccountsWithContext is the same as IterateAccounts with the addition of a context.Context
+func (r *accountStoreResource) IterateAccountsWithContext(ctx context.Context, criteria AccountCriteria) *AccountIterator {
+	ctx = r.withClient(ctx)
+	return newAccountIterator(ctx, r.Accounts.Href, criteria.baseCriteria)
+}
+
+//ScanAccounts calls fn for every one of the accounts within a context of: application, directory, group, organization, in order, fetching up to concurrency pages in parallel.
+//
+//It is meant for large collections, once the first page returns the collection size the next pages are prefetched.
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+//The scan stops at the first error, including an error returned by fn.
+func (r *accountStoreResource) ScanAccounts(criteria AccountCriteria, concurrency int, fn func(*Account) error) error {
+	return r.ScanAccountsWithContext(r.clientContext(), criteria, concurrency, fn)
+}
+
+//ScanAccountsWithContext is the same as ScanAccounts with the addition of a context.Context, cancelling it stops the scan
+func (r *accountStoreResource) ScanAccountsWithContext(ctx context.Context, criteria AccountCriteria, concurrency int, fn func(*Account) error) error {
+	ctx = r.withClient(ctx)
+	return scanAccounts(ctx, r.Accounts.Href, criteria.baseCriteria, concurrency, fn)
+}
+
+//ExportAccounts writes every account matching the criteria to w in the given format,
+//with its profile, status, custom data and group names.
+//
+//The accounts are streamed page by page so the export doesn't hold the whole collection in memory.
+func (r *accountStoreResource) ExportAccounts(w io.Writer, format AccountFormat, criteria AccountCriteria) error {
+	return r.ExportAccountsWithContext(r.clientContext(), w, format, criteria)
+}
+
+//ExportAccountsWithContext is the same as ExportAccounts with the addition of a context.Context
+func (r *accountStoreResource) ExportAccountsWithContext(ctx context.Context, w io.Writer, format AccountFormat, criteria AccountCriteria) error {
+	ctx = r.withClient(ctx)
+	return exportAccounts(ctx, r.Accounts.Href, criteria, w, format)
+}
+
 func GetToken(href string) string {
 	return href[strings.LastIndex(href, "/")+1:]
 }
@@ -0,0 +1,124 @@
+package stormpath
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//RetryAfterHeader is the header Stormpath uses to tell how long to wait before retrying a throttled request
+const RetryAfterHeader = "Retry-After"
+
+//requestPayload returns the raw body of the given request without consuming it
+func requestPayload(req *http.Request) ([]byte, error) {
+	if req.GetBody == nil {
+		return emptyPayload(), nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	return ioutil.ReadAll(body)
+}
+
+//retryRequest creates a copy of the given request ready to be sent again
+func (client *Client) retryRequest(req *http.Request, payload []byte) *http.Request {
+	retry := req.WithContext(req.Context())
+
+	retry.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		retry.Header[k] = v
+	}
+	retry.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+	return retry
+}
+
+//retryDelay determines if the given failed attempt should be retried and how long to wait before doing so.
+//
+//Network errors and 5xx responses are only retried for idempotent requests, so for example loginAttempts
+//or any other POST is never sent twice if the first attempt could have been processed by Stormpath.
+//Throttled requests (429) are always retried since Stormpath didn't process them.
+//
+//A Retry-After longer than RetryMaxDelay isn't waited for, the failed response is returned to the caller instead.
+func (client *Client) retryDelay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if attempt >= client.ClientConfiguration.RetryMaxAttempts {
+		return 0, false
+	}
+	if req.Context().Err() != nil {
+		return 0, false
+	}
+
+	if err != nil {
+		return client.backoff(attempt), isIdempotent(req.Method) && isUnavailable(err)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		break
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if !isIdempotent(req.Method) {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get(RetryAfterHeader)); ok {
+		return retryAfter, retryAfter <= client.ClientConfiguration.RetryMaxDelay
+	}
+	return client.backoff(attempt), true
+}
+
+//backoff returns the exponential backoff delay with jitter for the given attempt,
+//the delay is a random value between half and the full exponential delay capped by RetryMaxDelay
+func (client *Client) backoff(attempt int) time.Duration {
+	delay := client.ClientConfiguration.RetryBaseDelay
+	for i := 1; i < attempt && delay < client.ClientConfiguration.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > client.ClientConfiguration.RetryMaxDelay {
+		delay = client.ClientConfiguration.RetryMaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+//parseRetryAfter parses a Retry-After header value either in seconds or as an HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(time.Now())
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,208 @@
+package stormpath
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type retryTestServer struct {
+	*httptest.Server
+	mutex          sync.Mutex
+	authorizations []string
+}
+
+func (s *retryTestServer) attempts() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.authorizations)
+}
+
+func newRetryTestServer(handler func(attempt int, w http.ResponseWriter)) *retryTestServer {
+	s := &retryTestServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mutex.Lock()
+		s.authorizations = append(s.authorizations, r.Header.Get(AuthorizationHeader))
+		attempt := len(s.authorizations)
+		s.mutex.Unlock()
+
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		handler(attempt, w)
+	}))
+	return s
+}
+
+func newRetryTestClient(baseURL string, maxAttempts int) *Client {
+	config := LoadConfigurationWithCreds("retryKeyID", "retryKeySecret")
+	config.BaseURL = baseURL + "/v1/"
+	config.CacheManagerEnabled = false
+	config.RetryMaxAttempts = maxAttempts
+	config.RetryBaseDelay = time.Millisecond
+	config.RetryMaxDelay = 5 * time.Millisecond
+
+	return NewClient(config, nil)
+}
+
+func TestRetryIdempotentRequest(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		if attempt < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"status":503,"message":"unavailable"}`))
+			return
+		}
+		w.Write([]byte(`{"href":"tenant","name":"retried"}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 3)
+
+	retriedTenant, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "retried", retriedTenant.Name)
+	assert.Equal(t, 3, server.attempts())
+	assert.NotEqual(t, server.authorizations[0], server.authorizations[1])
+	assert.NotEqual(t, server.authorizations[1], server.authorizations[2])
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`{"status":502,"message":"bad gateway"}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 2)
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.Error(t, err)
+	assert.Equal(t, 502, err.(Error).Status)
+	assert.Equal(t, 2, server.attempts())
+}
+
+func TestRetryDoesNotReplayLoginAttempts(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(`{"status":500,"message":"error"}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 3)
+	application := &Application{}
+	application.Href = server.URL + "/v1/applications/XXXX"
+
+	_, err := application.AuthenticateAccountWithContext(NewContext(context.Background(), c), "username", "password", "")
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, server.attempts())
+}
+
+func TestRetryThrottledLoginAttempts(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		if attempt == 1 {
+			w.Header().Set(RetryAfterHeader, "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"status":429,"message":"throttled"}`))
+			return
+		}
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"status":400,"code":7100,"message":"Invalid username or password."}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 3)
+	application := &Application{}
+	application.Href = server.URL + "/v1/applications/XXXX"
+
+	_, err := application.AuthenticateAccountWithContext(NewContext(context.Background(), c), "username", "password", "")
+
+	assert.Error(t, err)
+	assert.Equal(t, 7100, err.(Error).Code)
+	assert.Equal(t, 2, server.attempts())
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		w.Header().Set(RetryAfterHeader, "60")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":503,"message":"unavailable"}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 3)
+	c.ClientConfiguration.RetryMaxDelay = time.Minute
+	ctx, cancel := context.WithTimeout(NewContext(context.Background(), c), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, 1, server.attempts())
+}
+
+func TestRetryAfterLongerThanMaxDelay(t *testing.T) {
+	t.Parallel()
+
+	server := newRetryTestServer(func(attempt int, w http.ResponseWriter) {
+		w.Header().Set(RetryAfterHeader, "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"status":429,"message":"throttled"}`))
+	})
+	defer server.Close()
+
+	c := newRetryTestClient(server.URL, 3)
+	start := time.Now()
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.Error(t, err)
+	assert.Equal(t, 429, err.(Error).Status)
+	assert.Equal(t, 1, server.attempts())
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	delay, ok := parseRetryAfter("2")
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, delay)
+
+	delay, ok = parseRetryAfter(time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	assert.True(t, ok)
+	assert.Equal(t, time.Duration(0), delay)
+
+	_, ok = parseRetryAfter("")
+	assert.False(t, ok)
+
+	_, ok = parseRetryAfter("soon")
+	assert.False(t, ok)
+}
+
+func TestBackoffIsCapped(t *testing.T) {
+	t.Parallel()
+
+	c := newRetryTestClient("http://localhost", 10)
+
+	for attempt := 1; attempt < 10; attempt++ {
+		delay := c.backoff(attempt)
+		assert.True(t, delay <= c.ClientConfiguration.RetryMaxDelay)
+		assert.True(t, delay > 0)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	uuid "github.com/nu7hatch/gouuid"
 )
 
 //SAuthc1 algorithm constants
@@ -50,23 +52,35 @@ func Authenticate(req *http.Request, payload []byte, date time.Time, apiKeyID st
 
 	signedHeadersString := signedHeadersString(req.Header, sortedHeaderKeys)
 
-	canonicalRequest := buildCanonicalRequest(req, payload, signedHeadersString, sortedHeaderKeys)
+	canonicalRequest := buildCanonicalRequest(req, req.Header, payload, signedHeadersString, sortedHeaderKeys)
 
 	id := buildID(nonce, dateStamp, apiKeyID)
 
 	stringToSign := buildStringToSign(timestamp, id, canonicalRequest)
 
+	signature := buildSignature(stringToSign, dateStamp, nonce, apiKeySecret)
+
+	req.Header.Set(AuthorizationHeader, buildAuthorizationHeader(id, signedHeadersString, signature))
+}
+
+func buildSignature(stringToSign []byte, dateStamp string, nonce string, apiKeySecret string) []byte {
 	secret := []byte(AuthenticationScheme + apiKeySecret)
 	singDate := sing(dateStamp, secret)
 	singNonce := sing(nonce, singDate)
 	signing := sing(IDTerminator, singNonce)
 
-	signature := sing(string(stringToSign), signing)
+	return sing(string(stringToSign), signing)
+}
+
+//Authenticate signs the given request using the SAuthc1 algorithm with a fresh nonce and the current date
+func (SAuthc1RequestAuthenticator) Authenticate(req *http.Request, payload []byte, apiKeyID string, apiKeySecret string) {
+	uuid, _ := uuid.NewV4()
+	nonce := uuid.String()
 
-	req.Header.Set(AuthorizationHeader, buildAuthorizationHeader(id, signedHeadersString, signature))
+	Authenticate(req, payload, time.Now().In(time.UTC), apiKeyID, apiKeySecret, nonce)
 }
 
-func buildCanonicalRequest(req *http.Request, payload []byte, signedHeadersString string, sortedHeaderKeys []string) string {
+func buildCanonicalRequest(req *http.Request, headers http.Header, payload []byte, signedHeadersString string, sortedHeaderKeys []string) string {
 	buffer := buffPool.Get().(*bytes.Buffer)
 	buffer.Reset()
 	defer buffPool.Put(buffer)
@@ -77,7 +91,7 @@ func buildCanonicalRequest(req *http.Request, payload []byte, signedHeadersStrin
 	buffer.WriteString(NL)
 	canonicalizeQueryString(buffer, req.URL.Query())
 	buffer.WriteString(NL)
-	canonicalizeHeadersString(buffer, req.Header, sortedHeaderKeys)
+	canonicalizeHeadersString(buffer, headers, sortedHeaderKeys)
 	buffer.WriteString(NL)
 	buffer.WriteString(signedHeadersString)
 	buffer.WriteString(NL)
@@ -115,7 +129,8 @@ func buildStringToSign(timestamp string, id string, canonicalRequest string) []b
 	buffer.WriteString(NL)
 	buffer.WriteString(hex.EncodeToString(sha256Sum([]byte(canonicalRequest))))
 
-	return buffer.Bytes()
+	//The buffer goes back to the pool so return a copy of its contents
+	return []byte(buffer.String())
 }
 
 func buildAuthorizationHeader(id string, signedHeadersString string, signature []byte) string {
@@ -0,0 +1,321 @@
+package stormpath
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+//SAuthc1 verification errors
+var (
+	ErrMissingAuthorization = errors.New("missing SAuthc1 authorization header")
+	ErrInvalidAuthorization = errors.New("invalid SAuthc1 authorization header")
+	ErrUnknownAPIKey        = errors.New("unknown SAuthc1 API key")
+	ErrInvalidSignature     = errors.New("invalid SAuthc1 signature")
+	ErrRequestDateSkew      = errors.New("SAuthc1 request date outside of the allowed skew")
+	ErrNonceReplay          = errors.New("SAuthc1 nonce already used")
+	ErrRequestBodyTooLarge  = errors.New("request body larger than the SAuthc1 verifier MaxBodySize")
+)
+
+//DefaultSAuthc1MaxBodySize is the largest request body read by a SAuthc1Verifier Handler by default
+const DefaultSAuthc1MaxBodySize = 1 << 20
+
+type apiKeyIDContextKey struct{}
+
+//APIKeySecretFunc returns the secret of the given API key ID, ok is false if the API key doesn't exist
+type APIKeySecretFunc func(apiKeyID string) (secret string, ok bool)
+
+//NonceStore keeps track of the SAuthc1 nonces already used, to reject replayed requests
+type NonceStore interface {
+	//Use records the nonce until it expires, it returns false if the nonce was already used
+	Use(nonce string, expires time.Time) bool
+}
+
+//SAuthc1Verifier verifies SAuthc1 signed requests, it is the server side counterpart of Authenticate.
+//
+//MaxBodySize is the largest request body the Handler reads to verify the signature, zero disables the limit.
+type SAuthc1Verifier struct {
+	Secrets     APIKeySecretFunc
+	MaxSkew     time.Duration
+	Nonces      NonceStore
+	MaxBodySize int64
+}
+
+//sauthc1Authorization is a parsed SAuthc1 Authorization header whose date and API key were checked
+type sauthc1Authorization struct {
+	id            string
+	apiKeyID      string
+	dateStamp     string
+	nonce         string
+	timestamp     string
+	date          time.Time
+	signedHeaders string
+	signature     []byte
+	secret        string
+}
+
+//NewSAuthc1Verifier creates a new SAuthc1Verifier for the given API keys with a 15 minutes
+//max date skew, an in-memory NonceStore and a DefaultSAuthc1MaxBodySize body limit.
+//It panics if secrets is nil.
+func NewSAuthc1Verifier(secrets APIKeySecretFunc) *SAuthc1Verifier {
+	if secrets == nil {
+		panic("stormpath: NewSAuthc1Verifier requires an APIKeySecretFunc")
+	}
+
+	return &SAuthc1Verifier{
+		Secrets:     secrets,
+		MaxSkew:     15 * time.Minute,
+		Nonces:      NewLocalNonceStore(),
+		MaxBodySize: DefaultSAuthc1MaxBodySize,
+	}
+}
+
+//Verify checks the SAuthc1 Authorization header of the given request against its payload,
+//it returns the API key ID that signed the request.
+//
+//The request is rejected if the signature doesn't match, the X-Stormpath-Date is outside of the MaxSkew window
+//or the nonce was already used.
+func (v *SAuthc1Verifier) Verify(req *http.Request, payload []byte) (string, error) {
+	authorization, err := v.authorization(req)
+	if err != nil {
+		return "", err
+	}
+	return v.verifySignature(req, authorization, payload)
+}
+
+//authorization parses the Authorization header of the request and checks its date and API key,
+//everything that can be verified without the payload
+func (v *SAuthc1Verifier) authorization(req *http.Request) (*sauthc1Authorization, error) {
+	header := req.Header.Get(AuthorizationHeader)
+	if header == "" {
+		return nil, ErrMissingAuthorization
+	}
+
+	id, signedHeaders, signature, err := parseSAuthc1Header(header)
+	if err != nil {
+		return nil, err
+	}
+
+	idParts := strings.Split(id, string(SLASH))
+	if len(idParts) != 4 || idParts[3] != IDTerminator {
+		return nil, ErrInvalidAuthorization
+	}
+	authorization := &sauthc1Authorization{
+		id:            id,
+		apiKeyID:      idParts[0],
+		dateStamp:     idParts[1],
+		nonce:         idParts[2],
+		timestamp:     req.Header.Get(StormpathDateHeader),
+		signedHeaders: signedHeaders,
+		signature:     signature,
+	}
+
+	authorization.date, err = time.Parse(TimestampFormat, authorization.timestamp)
+	if err != nil || authorization.date.UTC().Format(DateFormat) != authorization.dateStamp {
+		return nil, ErrInvalidAuthorization
+	}
+
+	skew := time.Since(authorization.date)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > v.MaxSkew {
+		return nil, ErrRequestDateSkew
+	}
+
+	ok := false
+	if v.Secrets != nil {
+		authorization.secret, ok = v.Secrets(authorization.apiKeyID)
+	}
+	if !ok {
+		return nil, ErrUnknownAPIKey
+	}
+
+	return authorization, nil
+}
+
+//verifySignature checks the signature of the parsed Authorization header against the request and its payload
+//and records the nonce
+func (v *SAuthc1Verifier) verifySignature(req *http.Request, authorization *sauthc1Authorization, payload []byte) (string, error) {
+	headers, sortedHeaderKeys, err := signedRequestHeaders(req, authorization.signedHeaders)
+	if err != nil {
+		return "", err
+	}
+
+	canonicalRequest := buildCanonicalRequest(req, headers, payload, authorization.signedHeaders, sortedHeaderKeys)
+	stringToSign := buildStringToSign(authorization.timestamp, authorization.id, canonicalRequest)
+	expected := buildSignature(stringToSign, authorization.dateStamp, authorization.nonce, authorization.secret)
+
+	if !hmac.Equal(expected, authorization.signature) {
+		return "", ErrInvalidSignature
+	}
+
+	nonce := authorization.apiKeyID + string(SLASH) + authorization.nonce
+	if v.Nonces != nil && !v.Nonces.Use(nonce, authorization.date.Add(v.MaxSkew)) {
+		return "", ErrNonceReplay
+	}
+
+	return authorization.apiKeyID, nil
+}
+
+//Handler wraps the given http.Handler so only requests with a valid SAuthc1 signature reach it,
+//the API key ID that signed the request is available via VerifiedAPIKeyID on the request context.
+//
+//The Authorization header is checked before the body is read, and bodies larger than MaxBodySize are rejected
+//with a 413. Invalid requests get a Stormpath like 401 JSON error.
+func (v *SAuthc1Verifier) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, err := v.authorization(r)
+		if err != nil {
+			writeSAuthc1Error(w, http.StatusUnauthorized, "Authentication required.", err)
+			return
+		}
+
+		var body io.Reader = r.Body
+		if v.MaxBodySize > 0 {
+			//Read one byte past the limit to tell a body of exactly MaxBodySize from a larger one
+			body = io.LimitReader(r.Body, v.MaxBodySize+1)
+		}
+		payload, err := ioutil.ReadAll(body)
+		r.Body.Close()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if v.MaxBodySize > 0 && int64(len(payload)) > v.MaxBodySize {
+			writeSAuthc1Error(w, http.StatusRequestEntityTooLarge, "Request body too large.", ErrRequestBodyTooLarge)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(payload))
+
+		apiKeyID, err := v.verifySignature(r, authorization, payload)
+		if err != nil {
+			writeSAuthc1Error(w, http.StatusUnauthorized, "Authentication required.", err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), apiKeyIDContextKey{}, apiKeyID)))
+	})
+}
+
+//writeSAuthc1Error writes a Stormpath like JSON error
+func writeSAuthc1Error(w http.ResponseWriter, status int, message string, err error) {
+	w.Header().Set(ContentTypeHeader, ApplicationJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"status":           status,
+		"code":             status,
+		"message":          message,
+		"developerMessage": err.Error(),
+	})
+}
+
+//VerifiedAPIKeyID returns the API key ID verified by a SAuthc1Verifier Handler for the request context
+func VerifiedAPIKeyID(ctx context.Context) (string, bool) {
+	apiKeyID, ok := ctx.Value(apiKeyIDContextKey{}).(string)
+	return apiKeyID, ok
+}
+
+//parseSAuthc1Header parses an Authorization header value generated by buildAuthorizationHeader
+func parseSAuthc1Header(authorization string) (id string, signedHeaders string, signature []byte, err error) {
+	prefix := AuthenticationScheme + string(SPACE)
+	if !strings.HasPrefix(authorization, prefix) {
+		return "", "", nil, ErrInvalidAuthorization
+	}
+
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, prefix), CS) {
+		i := strings.IndexByte(part, EQ)
+		if i < 0 {
+			return "", "", nil, ErrInvalidAuthorization
+		}
+
+		value := part[i+1:]
+		switch part[:i] {
+		case SAUTHC1Id:
+			id = value
+		case SAUTHC1SignedHeaders:
+			signedHeaders = value
+		case SAUTHC1Signature:
+			signature, err = hex.DecodeString(value)
+			if err != nil {
+				return "", "", nil, ErrInvalidAuthorization
+			}
+		}
+	}
+
+	if id == "" || signedHeaders == "" || len(signature) == 0 {
+		return "", "", nil, ErrInvalidAuthorization
+	}
+	return id, signedHeaders, signature, nil
+}
+
+//signedRequestHeaders rebuilds the headers the client signed, in the order it signed them.
+//The host and x-stormpath-date headers are required to be signed.
+func signedRequestHeaders(req *http.Request, signedHeaders string) (http.Header, []string, error) {
+	headers := http.Header{}
+	keys := []string{}
+
+	for _, name := range strings.Split(signedHeaders, string(SemiColon)) {
+		key := http.CanonicalHeaderKey(name)
+		if key == HostHeader {
+			host := req.Host
+			if host == "" {
+				host = req.URL.Host
+			}
+			headers[key] = []string{host}
+		} else {
+			headers[key] = req.Header[key]
+		}
+		keys = append(keys, key)
+	}
+
+	if _, ok := headers[HostHeader]; !ok {
+		return nil, nil, ErrInvalidAuthorization
+	}
+	if _, ok := headers[StormpathDateHeader]; !ok {
+		return nil, nil, ErrInvalidAuthorization
+	}
+
+	return headers, keys, nil
+}
+
+type localNonceStore struct {
+	mutex     sync.Mutex
+	nonces    map[string]time.Time
+	lastPurge time.Time
+}
+
+//NewLocalNonceStore creates an in-memory NonceStore, expired nonces are purged as new ones are used
+func NewLocalNonceStore() NonceStore {
+	return &localNonceStore{nonces: map[string]time.Time{}}
+}
+
+func (store *localNonceStore) Use(nonce string, expires time.Time) bool {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	now := time.Now()
+	if now.Sub(store.lastPurge) > time.Minute {
+		for n, e := range store.nonces {
+			if e.Before(now) {
+				delete(store.nonces, n)
+			}
+		}
+		store.lastPurge = now
+	}
+
+	if e, exists := store.nonces[nonce]; exists && !e.Before(now) {
+		return false
+	}
+	store.nonces[nonce] = expires
+	return true
+}
@@ -0,0 +1,175 @@
+package stormpath
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testSecrets(apiKeyID string) (string, bool) {
+	if apiKeyID == "MyId" {
+		return "Shush!", true
+	}
+	return "", false
+}
+
+func signedTestRequest(date time.Time, apiKeyID string, nonce string) *http.Request {
+	req, _ := http.NewRequest(http.MethodPost, "https://api.stormpath.com/v1/directories?orderBy=name+asc", strings.NewReader(`{"name":"dir"}`))
+	req.Header.Set(ContentTypeHeader, ApplicationJSON)
+
+	Authenticate(req, []byte(`{"name":"dir"}`), date, apiKeyID, "Shush!", nonce)
+	return req
+}
+
+func TestSAuthc1VerifierVerify(t *testing.T) {
+	t.Parallel()
+
+	verifier := NewSAuthc1Verifier(testSecrets)
+	req := signedTestRequest(time.Now().UTC(), "MyId", "a43a9d25-ab06-421e-8605-33fd1e760825")
+
+	apiKeyID, err := verifier.Verify(req, []byte(`{"name":"dir"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "MyId", apiKeyID)
+}
+
+func TestSAuthc1VerifierRejectsInvalidRequests(t *testing.T) {
+	t.Parallel()
+
+	verifier := NewSAuthc1Verifier(testSecrets)
+	payload := []byte(`{"name":"dir"}`)
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.stormpath.com/v1/", nil)
+	_, err := verifier.Verify(req, payload)
+	assert.Equal(t, ErrMissingAuthorization, err)
+
+	req.Header.Set(AuthorizationHeader, "Basic TXlJZDpTaHVzaCE=")
+	_, err = verifier.Verify(req, payload)
+	assert.Equal(t, ErrInvalidAuthorization, err)
+
+	req = signedTestRequest(time.Now().UTC(), "MyId", "tampered")
+	_, err = verifier.Verify(req, []byte(`{"name":"other"}`))
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	req = signedTestRequest(time.Now().UTC(), "MyId", "header")
+	req.Header.Set(ContentTypeHeader, "text/plain")
+	_, err = verifier.Verify(req, payload)
+	assert.Equal(t, ErrInvalidSignature, err)
+
+	req = signedTestRequest(time.Now().UTC(), "UnknownId", "unknown")
+	_, err = verifier.Verify(req, payload)
+	assert.Equal(t, ErrUnknownAPIKey, err)
+
+	req = signedTestRequest(time.Now().UTC().Add(-time.Hour), "MyId", "skewed")
+	_, err = verifier.Verify(req, payload)
+	assert.Equal(t, ErrRequestDateSkew, err)
+}
+
+func TestSAuthc1VerifierRejectsReplayedNonce(t *testing.T) {
+	t.Parallel()
+
+	verifier := NewSAuthc1Verifier(testSecrets)
+	req := signedTestRequest(time.Now().UTC(), "MyId", "replayed")
+
+	_, err := verifier.Verify(req, []byte(`{"name":"dir"}`))
+	assert.NoError(t, err)
+
+	_, err = verifier.Verify(req, []byte(`{"name":"dir"}`))
+	assert.Equal(t, ErrNonceReplay, err)
+}
+
+func TestSAuthc1VerifierHandler(t *testing.T) {
+	t.Parallel()
+
+	var verifiedAPIKeyID string
+	server := httptest.NewServer(NewSAuthc1Verifier(testSecrets).Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifiedAPIKeyID, _ = VerifiedAPIKeyID(r.Context())
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"tenant","name":"verified"}`))
+	})))
+	defer server.Close()
+
+	config := LoadConfigurationWithCreds("MyId", "Shush!")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	verifiedTenant, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "verified", verifiedTenant.Name)
+	assert.Equal(t, "MyId", verifiedAPIKeyID)
+
+	config = LoadConfigurationWithCreds("MyId", "WrongSecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	_, err = CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, err.(Error).Status)
+}
+
+type readTrackingBody struct {
+	*strings.Reader
+	read bool
+}
+
+func (b *readTrackingBody) Read(p []byte) (int, error) {
+	b.read = true
+	return b.Reader.Read(p)
+}
+
+func (b *readTrackingBody) Close() error {
+	return nil
+}
+
+func TestSAuthc1VerifierHandlerLimitsBody(t *testing.T) {
+	t.Parallel()
+
+	verifier := NewSAuthc1Verifier(testSecrets)
+	verifier.MaxBodySize = 8
+	handler := verifier.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	body := &readTrackingBody{Reader: strings.NewReader(`{"name":"dir"}`)}
+	req := httptest.NewRequest(http.MethodPost, "https://api.stormpath.com/v1/directories", body)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.False(t, body.read)
+
+	req = signedTestRequest(time.Now().UTC(), "MyId", "c0e3b2a1-7c9e-4d47-9a55-0b8a6c1de2f4")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), ErrRequestBodyTooLarge.Error())
+
+	verifier.MaxBodySize = int64(len(`{"name":"dir"}`))
+	req = signedTestRequest(time.Now().UTC(), "MyId", "9e7d6c5b-4a3f-4e2d-8c1b-0a9f8e7d6c5b")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	verifier.MaxBodySize = 0
+	req = signedTestRequest(time.Now().UTC(), "MyId", "5d1f8e2a-3b4c-4e6f-8a9b-1c2d3e4f5a6b")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestNewSAuthc1VerifierRequiresSecrets(t *testing.T) {
+	t.Parallel()
+
+	assert.Panics(t, func() { NewSAuthc1Verifier(nil) })
+	assert.Equal(t, int64(DefaultSAuthc1MaxBodySize), NewSAuthc1Verifier(testSecrets).MaxBodySize)
+}
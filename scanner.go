@@ -0,0 +1,116 @@
+package stormpath
+
+import "context"
+
+//DefaultScanConcurrency is the number of pages fetched in parallel by the collection scanners when no concurrency is given
+const DefaultScanConcurrency = 4
+
+//scannedPage is a fetched page waiting to be emitted, emit calls the scan callback for every item of the page
+type scannedPage struct {
+	emit func() error
+	err  error
+}
+
+//scanPages fetches every page of a collection resource with up to concurrency requests in flight
+//and emits the pages in order.
+//
+//The first page is fetched alone to learn the collection size, then the remaining pages are prefetched.
+//At most concurrency pages are fetched or waiting to be emitted at any time, so memory stays bounded
+//no matter the collection size. The scan stops at the first fetch or emit error, or when ctx is done.
+func scanPages(ctx context.Context, href string, criteria baseCriteria, concurrency int, fetch func(ctx context.Context, url string) (collectionResource, func() error, error)) error {
+	if criteria.limit == 0 {
+		criteria.limit = DefaultIteratorPageSize
+	}
+	if concurrency <= 0 {
+		concurrency = DefaultScanConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	first, emitFirst, err := fetch(ctx, buildAbsoluteURL(href, criteria.toQueryString()))
+	if err != nil {
+		return err
+	}
+
+	offset := criteria.offset
+	if first.Offset != nil {
+		offset = *first.Offset
+	}
+	//Stormpath caps the page limit, the remaining pages are strided by the limit it applied
+	if first.Limit != nil && *first.Limit > 0 {
+		criteria.limit = *first.Limit
+	}
+	offsets := []int{}
+	if first.Size != nil {
+		for o := offset + criteria.limit; o < *first.Size; o += criteria.limit {
+			offsets = append(offsets, o)
+		}
+	}
+
+	pages := make([]chan scannedPage, len(offsets))
+	for i := range pages {
+		pages[i] = make(chan scannedPage, 1)
+	}
+	slots := make(chan struct{}, concurrency)
+
+	go func() {
+		for i, o := range offsets {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			pageCriteria := criteria
+			pageCriteria.offset = o
+
+			go func(page chan scannedPage, url string) {
+				_, emit, err := fetch(ctx, url)
+				page <- scannedPage{emit, err}
+			}(pages[i], buildAbsoluteURL(href, pageCriteria.toQueryString()))
+		}
+	}()
+
+	if err := emitFirst(); err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		select {
+		case p := <-page:
+			<-slots
+			if p.err != nil {
+				return p.err
+			}
+			if err := p.emit(); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+//scanAccounts scans an accounts collection calling fn for every account in order
+func scanAccounts(ctx context.Context, href string, criteria baseCriteria, concurrency int, fn func(*Account) error) error {
+	return scanPages(ctx, href, criteria, concurrency, func(ctx context.Context, url string) (collectionResource, func() error, error) {
+		accounts := &Accounts{}
+
+		err := ClientFromContext(ctx).get(ctx, url, accounts)
+		if err != nil {
+			return accounts.collectionResource, nil, err
+		}
+
+		return accounts.collectionResource, func() error {
+			for i := range accounts.Items {
+				if err := fn(&accounts.Items[i]); err != nil {
+					return err
+				}
+			}
+			return nil
+		}, nil
+	})
+}
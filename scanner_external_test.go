@@ -0,0 +1,36 @@
+package stormpath_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/jarias/stormpath-sdk-go"
+	"github.com/jarias/stormpath-sdk-go/stormpathtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScanAccountsAboveMaxPageLimit(t *testing.T) {
+	t.Parallel()
+
+	server := stormpathtest.NewServer()
+	defer server.Close()
+	ctx := stormpath.NewContext(context.Background(), server.NewClient())
+
+	directory := stormpath.NewDirectory("scan")
+	assert.NoError(t, stormpath.CreateDirectoryWithContext(ctx, directory))
+	for i := 0; i < 250; i++ {
+		account := stormpath.NewAccount(fmt.Sprintf("user%d", i), "Passw0rd!", fmt.Sprintf("user%d@test.org", i), "Scan", "User")
+		assert.NoError(t, directory.RegisterAccountWithContext(ctx, account))
+	}
+
+	//Stormpath caps the page limit at 100
+	usernames := map[string]bool{}
+	err := directory.ScanAccountsWithContext(ctx, stormpath.MakeAccountsCriteria().Limit(200), 2, func(account *stormpath.Account) error {
+		usernames[account.Username] = true
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, usernames, 250)
+}
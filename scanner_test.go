@@ -0,0 +1,146 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+//delayRequests delays every request of the client bound to ctx, the returned func tells the maximum number
+//of requests that were in flight
+func delayRequests(ctx context.Context, delay time.Duration) func() int {
+	var mutex sync.Mutex
+	active, maxActive := 0, 0
+
+	ClientFromContext(ctx).Use(func(req *http.Request, result interface{}, next Invoker) error {
+		mutex.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mutex.Unlock()
+
+		time.Sleep(delay)
+		err := next(req, result)
+
+		mutex.Lock()
+		active--
+		mutex.Unlock()
+		return err
+	})
+
+	return func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return maxActive
+	}
+}
+
+func TestScanAccountsInOrder(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(95, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+	maxActive := delayRequests(ctx, 5*time.Millisecond)
+
+	usernames := []string{}
+	err := tenant.ScanAccountsWithContext(ctx, MakeAccountsCriteria().Limit(10), 3, func(account *Account) error {
+		usernames = append(usernames, account.Username)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Len(t, usernames, 95)
+	for i, username := range usernames {
+		assert.Equal(t, strconv.Itoa(i), username)
+	}
+	assert.Len(t, server.queries(), 10)
+	assert.True(t, maxActive() > 1)
+	assert.True(t, maxActive() <= 3)
+}
+
+func TestScanAccountsSinglePage(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(5, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	count := 0
+	err := tenant.ScanAccountsWithContext(ctx, MakeAccountsCriteria(), 0, func(account *Account) error {
+		count++
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 5, count)
+	assert.Equal(t, []string{"limit=25&offset=0"}, server.queries())
+}
+
+func TestScanAccountsStopsOnFetchError(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(50, 20)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	count := 0
+	err := tenant.ScanAccountsWithContext(ctx, MakeAccountsCriteria().Limit(10), 2, func(account *Account) error {
+		count++
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, 500, err.(Error).Status)
+	assert.Equal(t, 20, count)
+}
+
+func TestScanAccountsStopsOnCallbackError(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(100, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+
+	stop := errors.New("stop")
+	count := 0
+	err := tenant.ScanAccountsWithContext(ctx, MakeAccountsCriteria().Limit(10), 2, func(account *Account) error {
+		count++
+		if count == 15 {
+			return stop
+		}
+		return nil
+	})
+
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 15, count)
+}
+
+func TestScanAccountsCancellation(t *testing.T) {
+	t.Parallel()
+
+	server := newIteratorTestServer(1000, -1)
+	defer server.Close()
+	ctx, tenant := newIteratorTestTenant(server)
+	delayRequests(ctx, 5*time.Millisecond)
+	ctx, cancel := context.WithCancel(ctx)
+
+	count := 0
+	err := tenant.ScanAccountsWithContext(ctx, MakeAccountsCriteria().Limit(10), 2, func(account *Account) error {
+		count++
+		if count == 10 {
+			cancel()
+		}
+		return nil
+	})
+
+	assert.Error(t, err)
+	assert.True(t, count < 1000)
+}
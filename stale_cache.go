@@ -0,0 +1,179 @@
+package stormpath
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"time"
+)
+
+//staleEntryHeader prefixes the cache entries stored with their freshness deadline when stale serving is enabled,
+//it is followed by the deadline as a big endian unix time in nanoseconds and then by the cached JSON
+const staleEntryHeader = "\x00stormpath-stale\x00"
+
+//defaultRevalidateTimeout bounds the background revalidation requests when no connection timeout is configured
+const defaultRevalidateTimeout = 30 * time.Second
+
+//ErrStale is matched by the StaleError returned when a response is served from an expired cache entry
+//because Stormpath couldn't be reached, use errors.Is(err, ErrStale) or IsStale
+var ErrStale = errors.New("stale cached response")
+
+//StaleError is returned by a GET call served from an expired cache entry because Stormpath couldn't be reached,
+//the result of the call holds the cached resource, e.g. GetAccount returns the cached account with the StaleError.
+//Err is the error of the failed request and Age how long ago the cache entry expired.
+type StaleError struct {
+	Err error
+	Age time.Duration
+}
+
+func (e StaleError) Error() string {
+	return fmt.Sprintf("serving cached response stale for %s: %s", e.Age, e.Err)
+}
+
+//Unwrap returns the error of the failed request
+func (e StaleError) Unwrap() error {
+	return e.Err
+}
+
+//Is matches ErrStale
+func (e StaleError) Is(target error) bool {
+	return target == ErrStale
+}
+
+//IsStale returns true if the error flags a result served from an expired cache entry, in which case the
+//result is usable but may be outdated
+func IsStale(err error) bool {
+	return errors.Is(err, ErrStale)
+}
+
+//staleWindow returns how long after their expiration cache entries may still be served
+func (config ClientConfiguration) staleWindow() time.Duration {
+	if config.CacheStaleRevalidate > config.CacheStaleIfError {
+		return config.CacheStaleRevalidate
+	}
+	return config.CacheStaleIfError
+}
+
+//cacheTTL returns the TTL of the cache entries of the given key, the TTL of its region if configured
+func (config ClientConfiguration) cacheTTL(key string) time.Duration {
+	if region, ok := config.CacheRegions[cacheRegion(key, cacheRegionBasePath(config.BaseURL))]; ok {
+		return region.TTL
+	}
+	return config.CacheTTL
+}
+
+//encodeCacheEntry prefixes the data with the stale entry header and its freshness deadline
+func encodeCacheEntry(data []byte, freshUntil time.Time) []byte {
+	entry := make([]byte, len(staleEntryHeader)+8+len(data))
+	n := copy(entry, staleEntryHeader)
+	binary.BigEndian.PutUint64(entry[n:], uint64(freshUntil.UnixNano()))
+	copy(entry[n+8:], data)
+	return entry
+}
+
+//decodeCacheEntry returns the data and freshness deadline of the cache entry,
+//entries stored without stale entry header have a zero deadline and are always fresh
+func decodeCacheEntry(entry []byte) ([]byte, time.Time) {
+	if len(entry) < len(staleEntryHeader)+8 || !bytes.HasPrefix(entry, []byte(staleEntryHeader)) {
+		return entry, time.Time{}
+	}
+	n := len(staleEntryHeader)
+	return entry[n+8:], time.Unix(0, int64(binary.BigEndian.Uint64(entry[n:])))
+}
+
+//isStale returns true if the cache entry with the given freshness deadline is expired at the given time
+func isStale(freshUntil time.Time, now time.Time) bool {
+	return !freshUntil.IsZero() && now.After(freshUntil)
+}
+
+//isUnavailable returns true if err means Stormpath couldn't process the request, a network error or timeout,
+//a throttled request or a 5xx response, as opposed to an error response for the request itself or a client
+//whose TLS configuration couldn't be loaded.
+//
+//The transport timeouts match context.DeadlineExceeded, callers must tell them apart from the requests abandoned
+//by their caller with the request context.Context.
+func isUnavailable(err error) bool {
+	if errors.Is(err, ErrTLSConfiguration) {
+		return false
+	}
+
+	spError := Error{}
+	if !errors.As(err, &spError) {
+		return true
+	}
+	return spError.Status == http.StatusTooManyRequests || spError.Status >= http.StatusInternalServerError
+}
+
+//revalidate refreshes the stale cache entry of the GET request in the background, the refresh goes through the
+//client interceptors and is coalesced with any identical request in flight. The refresh isn't bound to the
+//request context.Context, it is bounded by the connection timeout instead.
+func (client *Client) revalidate(request *http.Request, result interface{}) {
+	resultType := reflect.TypeOf(result)
+	if resultType == nil || resultType.Kind() != reflect.Ptr {
+		return
+	}
+
+	timeout := time.Duration(client.ClientConfiguration.ConnectionTimeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultRevalidateTimeout
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(NewContext(context.Background(), client), timeout)
+		defer cancel()
+
+		refresh, err := http.NewRequest(http.MethodGet, request.URL.String(), nil)
+		if err != nil {
+			return
+		}
+		refresh = refresh.WithContext(ctx)
+		for k, v := range request.Header {
+			refresh.Header[k] = v
+		}
+
+		client.chain(client.refresh)(refresh, reflect.New(resultType.Elem()).Interface())
+	}()
+}
+
+//refresh is the Invoker of the revalidation requests, it executes the request bypassing the cache entry
+//being refreshed and caches the response
+func (client *Client) refresh(request *http.Request, result interface{}) error {
+	data, shared, err := client.fetch(request)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return err
+	}
+
+	if !shared {
+		client.cacheResponse(request, result, data, false)
+	}
+	return nil
+}
+
+//staleFallback decodes the stale cache entry into result if the request failed because Stormpath couldn't
+//be reached and the entry expired within the stale if error window, otherwise it returns err
+func (client *Client) staleFallback(request *http.Request, result interface{}, stale []byte, freshUntil time.Time, err error) error {
+	age := time.Since(freshUntil)
+	if len(stale) == 0 || age > client.ClientConfiguration.CacheStaleIfError || request.Context().Err() != nil || !isUnavailable(err) {
+		return err
+	}
+
+	if result != nil {
+		if decodeErr := json.Unmarshal(stale, result); decodeErr != nil {
+			return err
+		}
+		bindClient(result, client)
+	}
+
+	_, key := cacheKey(request.URL)
+	client.cacheMetrics.stale(key)
+	Logger.Printf("[WARN] Serving cached response stale for %s [%s]", age, request.URL.String())
+	return StaleError{Err: err, Age: age}
+}
@@ -0,0 +1,218 @@
+package stormpath
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type staleTestServer struct {
+	*testServer
+	status int32
+	hang   int32
+}
+
+func newStaleTestServer() *staleTestServer {
+	s := &staleTestServer{status: http.StatusOK}
+	s.testServer = newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&s.hang) == 1 {
+			<-r.Context().Done()
+			return
+		}
+
+		status := int(atomic.LoadInt32(&s.status))
+		if status != http.StatusOK {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"status":%d,"code":%d,"message":"%s"}`, status, status, http.StatusText(status))
+			return
+		}
+		fmt.Fprintf(w, `{"href":"%s/v1/accounts/1","username":"v%d"}`, s.URL, len(s.received()))
+	})
+	return s
+}
+
+func newStaleTestClient(server *staleTestServer, revalidate time.Duration, ifError time.Duration) (context.Context, *Client) {
+	config := server.configuration()
+	config.CacheManagerEnabled = true
+	config.CacheTTL = 50 * time.Millisecond
+	config.CacheTTI = 50 * time.Millisecond
+	config.CacheStaleRevalidate = revalidate
+	config.CacheStaleIfError = ifError
+
+	return newTestContext(config, nil)
+}
+
+func TestCacheEntryEncoding(t *testing.T) {
+	t.Parallel()
+
+	freshUntil := time.Now().Add(time.Minute)
+	data, deadline := decodeCacheEntry(encodeCacheEntry([]byte(`{"href":"1"}`), freshUntil))
+
+	assert.Equal(t, []byte(`{"href":"1"}`), data)
+	assert.True(t, freshUntil.Equal(deadline))
+
+	data, deadline = decodeCacheEntry([]byte(`{"href":"1"}`))
+
+	assert.Equal(t, []byte(`{"href":"1"}`), data)
+	assert.True(t, deadline.IsZero())
+	assert.False(t, isStale(deadline, time.Now()))
+}
+
+func TestStaleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	server := newStaleTestServer()
+	defer server.Close()
+	ctx, client := newStaleTestClient(server, time.Minute, 0)
+	defer client.Close()
+	href := server.URL + "/v1/accounts/1"
+
+	var intercepted int32
+	client.Use(func(req *http.Request, result interface{}, next Invoker) error {
+		atomic.AddInt32(&intercepted, 1)
+		return next(req, result)
+	})
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", account.Username)
+
+	time.Sleep(80 * time.Millisecond)
+
+	account, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	assert.Equal(t, "v1", account.Username)
+
+	for i := 0; i < 100 && client.CacheStats().Sets < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	account, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+	assert.Equal(t, "v2", account.Username)
+	assert.Len(t, server.received(), 2)
+	assert.Equal(t, uint64(1), client.CacheStats().Stale)
+	//The background refresh goes through the interceptors too
+	assert.Equal(t, int32(4), atomic.LoadInt32(&intercepted))
+}
+
+func TestStaleIfError(t *testing.T) {
+	t.Parallel()
+
+	server := newStaleTestServer()
+	defer server.Close()
+	ctx, client := newStaleTestClient(server, 0, time.Minute)
+	defer client.Close()
+	href := server.URL + "/v1/accounts/1"
+
+	_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+
+	time.Sleep(80 * time.Millisecond)
+	atomic.StoreInt32(&server.status, http.StatusServiceUnavailable)
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.True(t, IsStale(err))
+	assert.True(t, errors.Is(err, ErrStale))
+	status, _ := ErrorStatus(err)
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+	assert.Equal(t, "v1", account.Username)
+
+	//Only unavailability falls back to stale data
+	atomic.StoreInt32(&server.status, http.StatusNotFound)
+
+	_, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.False(t, IsStale(err))
+	assert.True(t, IsNotFound(err))
+
+	server.Close()
+
+	account, err = GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.True(t, IsStale(err))
+	assert.Equal(t, "v1", account.Username)
+}
+
+func TestStaleIfErrorReturnsTheStaleResource(t *testing.T) {
+	t.Parallel()
+
+	server := newStaleTestServer()
+	defer server.Close()
+	ctx, client := newStaleTestClient(server, 0, time.Minute)
+	defer client.Close()
+	href := server.URL + "/v1/accounts/1"
+	org := &Organization{DefaultAccountStoreMapping: &OrganizationAccountStoreMapping{}}
+	org.DefaultAccountStoreMapping.Href = href
+
+	_, err := org.GetDefaultAccountStoreMappingWithContext(ctx, MakeOrganizationAccountStoreMappingCriteria())
+	assert.NoError(t, err)
+
+	time.Sleep(80 * time.Millisecond)
+	atomic.StoreInt32(&server.status, http.StatusServiceUnavailable)
+
+	mapping, err := org.GetDefaultAccountStoreMappingWithContext(ctx, MakeOrganizationAccountStoreMappingCriteria())
+
+	assert.True(t, IsStale(err))
+	assert.NotNil(t, mapping)
+	assert.Equal(t, href, mapping.Href)
+}
+
+func TestStaleIfErrorTimeout(t *testing.T) {
+	t.Parallel()
+
+	server := newStaleTestServer()
+	defer server.Close()
+	ctx, client := newStaleTestClient(server, 0, time.Minute)
+	defer client.Close()
+	client.HTTPClient.Transport.(*http.Transport).ResponseHeaderTimeout = 100 * time.Millisecond
+	href := server.URL + "/v1/accounts/1"
+
+	_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.NoError(t, err)
+
+	time.Sleep(80 * time.Millisecond)
+	atomic.StoreInt32(&server.hang, 1)
+
+	account, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.True(t, IsStale(err))
+	assert.Equal(t, "v1", account.Username)
+
+	//A caller giving up doesn't get stale data
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+
+	_, err = GetAccountWithContext(timeout, href, MakeAccountCriteria())
+
+	assert.Error(t, err)
+	assert.False(t, IsStale(err))
+}
+
+func TestStaleDisabled(t *testing.T) {
+	t.Parallel()
+
+	server := newStaleTestServer()
+	defer server.Close()
+	ctx, client := newStaleTestClient(server, 0, 0)
+	defer client.Close()
+	href := server.URL + "/v1/accounts/1"
+
+	GetAccountWithContext(ctx, href, MakeAccountCriteria())
+	assert.Equal(t, []byte(`{"href":"`+href+`","username":"v1"}`), client.Cache.Get(href))
+
+	time.Sleep(80 * time.Millisecond)
+	atomic.StoreInt32(&server.status, http.StatusServiceUnavailable)
+
+	_, err := GetAccountWithContext(ctx, href, MakeAccountCriteria())
+
+	assert.Error(t, err)
+	assert.False(t, IsStale(err))
+}
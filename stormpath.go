@@ -2,10 +2,11 @@ package stormpath
 
 import (
 	"bytes"
-	"crypto/tls"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
@@ -14,8 +15,6 @@ import (
 	"time"
 
 	"io/ioutil"
-
-	uuid "github.com/nu7hatch/gouuid"
 )
 
 //Version is the current SDK Version
@@ -34,70 +33,204 @@ const (
 	UserAgentHeader           = "User-Agent"
 )
 
+//client is the default Client configured by Init, it is used by any SDK call that doesn't have a Client
+//bound to its context.Context
 var client *Client
+
+//ErrNoClient is returned by the SDK calls that have no Client to execute them: their context.Context isn't bound
+//to one with NewContext, their resource wasn't fetched by one and Init wasn't called
+var ErrNoClient = errors.New("no Stormpath client, call Init or bind a Client to the context with NewContext")
+
 var buffPool = sync.Pool{
 	New: func() interface{} {
 		return &bytes.Buffer{}
 	},
 }
 
+type clientContextKey struct{}
+
 //Client is low level REST client for any Stormpath request,
 //it holds the credentials, an the actual http client, and the cache.
 //The Cache can be initialize in nil and the client would simply ignore it
 //and don't cache any response.
+//
+//Interceptors wrap the execution of every request done by the client, see Use.
 type Client struct {
 	ClientConfiguration ClientConfiguration
 	HTTPClient          *http.Client
 	Cache               Cache
 	WebSDKToken         string
+	Interceptors        []Interceptor
+
+	cacheIndex    *cacheIndex
+	cacheMetrics  *cacheMetrics
+	ownsCache     bool
+	inflight      *requestGroup
+	breaker       *circuitBreaker
+	authenticator RequestAuthenticator
 }
 
-//Init initializes the underlying client that communicates with Stormpath
+//Init initializes the default client that communicates with Stormpath,
+//the configured hrefs are preloaded in its cache in the background, see Client.WarmUp
 func Init(clientConfiguration ClientConfiguration, cache Cache) {
+	c := NewClient(clientConfiguration, cache)
+	client = c
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultWarmUpTimeout)
+		defer cancel()
+		c.WarmUp(ctx)
+	}()
+}
+
+//NewClient creates a new independent Client with its own configuration, http client and cache.
+//
+//To execute SDK calls with the returned Client, bind it to a context.Context with NewContext
+//and use the WithContext variant of the calls.
+//
+//The cache created by the client is restored from the configured snapshot file and saved to it by Close,
+//a given cache is managed by its owner with SaveCacheSnapshot and LoadCacheSnapshot.
+func NewClient(clientConfiguration ClientConfiguration, cache Cache) *Client {
 	InitLog()
 
-	tr := &http.Transport{
-		TLSClientConfig:    &tls.Config{},
-		DisableCompression: true,
+	httpClient := &http.Client{Transport: newHTTPTransport(clientConfiguration)}
+
+	c := &Client{
+		ClientConfiguration: clientConfiguration,
+		HTTPClient:          httpClient,
+		cacheIndex:          newCacheIndex(),
+		cacheMetrics:        newCacheMetrics(),
+		inflight:            newRequestGroup(),
+		breaker:             newCircuitBreaker(clientConfiguration),
+		authenticator:       NewRequestAuthenticator(clientConfiguration.AuthenticationScheme),
+	}
+	httpClient.CheckRedirect = c.checkRedirect
+
+	//Stale entries must outlive their TTL in the cache to be served
+	stale := clientConfiguration.staleWindow()
+	if clientConfiguration.CacheManagerEnabled && cache == nil && len(clientConfiguration.CacheRegions) > 0 {
+		regions := map[string]CacheRegionConfiguration{}
+		for name, region := range clientConfiguration.CacheRegions {
+			regions[name] = CacheRegionConfiguration{TTL: region.TTL + stale, TTI: region.TTI + stale}
+		}
+		regionCache := NewRegionCache(
+			clientConfiguration.CacheTTL+stale,
+			clientConfiguration.CacheTTI+stale,
+			regions,
+			clientConfiguration.CacheMaxEntries,
+			clientConfiguration.CacheMaxBytes,
+		)
+		regionCache.BasePath = cacheRegionBasePath(clientConfiguration.BaseURL)
+		c.Cache = regionCache
+	} else if clientConfiguration.CacheManagerEnabled && cache == nil {
+		c.Cache = NewBoundedLocalCache(
+			clientConfiguration.CacheTTL+stale,
+			clientConfiguration.CacheTTI+stale,
+			clientConfiguration.CacheMaxEntries,
+			clientConfiguration.CacheMaxBytes,
+		)
+	} else if clientConfiguration.CacheManagerEnabled && cache != nil {
+		//Caches implementing TTLSetter get the TTL of the region of every entry
+		if _, ok := cache.(TTLSetter); !ok && len(clientConfiguration.CacheRegions) > 0 {
+			Logger.Printf("[WARN] Cache regions are ignored by the given cache, its own TTL and TTI apply to every entry")
+		}
+		c.Cache = cache
 	}
-	httpClient := &http.Client{Transport: tr}
-	httpClient.CheckRedirect = checkRedirect
+	c.ownsCache = cache == nil
 
-	client = &Client{clientConfiguration, httpClient, nil, ""}
+	if c.Cache != nil && len(clientConfiguration.CacheEncryptionKeys) > 0 {
+		namespace := clientConfiguration.CacheNamespace
+		if namespace == "" {
+			namespace = clientConfiguration.APIKeyID
+		}
 
-	if clientConfiguration.CacheManagerEnabled && cache == nil {
-		client.Cache = NewLocalCache(clientConfiguration.CacheTTL, clientConfiguration.CacheTTI)
-	} else if clientConfiguration.CacheManagerEnabled && cache != nil {
-		client.Cache = cache
+		encrypted, err := NewEncryptedCache(c.Cache, namespace, clientConfiguration.CacheEncryptionKeys...)
+		if err != nil {
+			//Never fall back to caching the responses in clear
+			Logger.Printf("[ERROR] Couldn't configure the cache encryption, caching is disabled [%s]", err)
+			if closer, ok := c.Cache.(io.Closer); ok && c.ownsCache {
+				closer.Close()
+			}
+			c.Cache = nil
+		} else {
+			c.Cache = encrypted
+		}
+	}
+
+	if c.ownsCache {
+		c.restoreCacheSnapshot()
+	}
+
+	return c
+}
+
+//Close saves the cache snapshot if a snapshot file is configured and releases the resources of the cache created
+//by NewClient, such as the LocalCache janitor goroutine.
+//A cache given to NewClient is never saved nor closed by the client since it could be shared, its owner must close it.
+func (client *Client) Close() error {
+	var err error
+	if client.ownsCache && client.Cache != nil && client.ClientConfiguration.CacheSnapshotFile != "" {
+		err = client.SaveCacheSnapshot(client.ClientConfiguration.CacheSnapshotFile)
+	}
+
+	if closer, ok := client.Cache.(io.Closer); ok && client.ownsCache {
+		if closeErr := closer.Close(); err == nil {
+			err = closeErr
+		}
 	}
+	return err
 }
 
-//GetClient returns the configured client
+//GetClient returns the default client configured by Init
 func GetClient() *Client {
 	return client
 }
 
-func (client *Client) postURLEncodedForm(urlStr string, body string, result interface{}) error {
-	return client.execute(http.MethodPost, urlStr, []byte(body), result, ApplicationFormURLencoded)
+//NewContext returns a copy of parent bound to the given Client,
+//any WithContext SDK call using the returned context.Context is executed by that Client.
+func NewContext(parent context.Context, c *Client) context.Context {
+	return context.WithValue(parent, clientContextKey{}, c)
 }
 
-func (client *Client) post(urlStr string, body interface{}, result interface{}) error {
-	return client.execute(http.MethodPost, urlStr, body, result, ApplicationJSON)
+//ClientFromContext returns the Client bound to the given context.Context,
+//if there is none it returns the default client configured by Init, which is nil until Init is called.
+func ClientFromContext(ctx context.Context) *Client {
+	if c, ok := ctx.Value(clientContextKey{}).(*Client); ok && c != nil {
+		return c
+	}
+	return client
 }
 
-func (client *Client) get(urlStr string, result interface{}) error {
-	return client.execute(http.MethodGet, urlStr, emptyPayload(), result, ApplicationJSON)
+func (client *Client) postURLEncodedForm(ctx context.Context, urlStr string, body string, result interface{}) error {
+	return client.execute(ctx, http.MethodPost, urlStr, []byte(body), result, ApplicationFormURLencoded)
 }
 
-func (client *Client) delete(urlStr string) error {
-	return client.do(client.newRequest(http.MethodDelete, urlStr, emptyPayload(), ApplicationJSON))
+func (client *Client) post(ctx context.Context, urlStr string, body interface{}, result interface{}) error {
+	return client.execute(ctx, http.MethodPost, urlStr, body, result, ApplicationJSON)
 }
 
-func (client *Client) execute(method string, urlStr string, body interface{}, result interface{}, contentType string) error {
-	return client.doWithResult(client.newRequest(method, urlStr, body, contentType), result)
+func (client *Client) get(ctx context.Context, urlStr string, result interface{}) error {
+	return client.execute(ctx, http.MethodGet, urlStr, emptyPayload(), result, ApplicationJSON)
+}
+
+func (client *Client) delete(ctx context.Context, urlStr string) error {
+	if client == nil {
+		return ErrNoClient
+	}
+	return client.chain(client.do)(client.newRequest(ctx, http.MethodDelete, urlStr, emptyPayload(), ApplicationJSON), nil)
 }
 
-func buildRelativeURL(parts ...string) string {
+func (client *Client) execute(ctx context.Context, method string, urlStr string, body interface{}, result interface{}, contentType string) error {
+	if client == nil {
+		return ErrNoClient
+	}
+	return client.chain(client.doWithResult)(client.newRequest(ctx, method, urlStr, body, contentType), result)
+}
+
+func (client *Client) buildRelativeURL(parts ...string) string {
+	if client == nil {
+		return ""
+	}
 	p := append([]string{client.ClientConfiguration.BaseURL}, parts...)
 	return buildAbsoluteURL(p...)
 }
@@ -115,7 +248,11 @@ func buildAbsoluteURL(parts ...string) string {
 	return buffer.String()
 }
 
-func (client *Client) newRequest(method string, urlStr string, body interface{}, contentType string) *http.Request {
+//newRequest creates a new Stormpath request, the given context.Context is attached to the request
+//so cancellation and deadlines are honored while the request is executed.
+//
+//The request is signed right before it is sent, so interceptors are free to modify it.
+func (client *Client) newRequest(ctx context.Context, method string, urlStr string, body interface{}, contentType string) *http.Request {
 	var encodedBody []byte
 
 	if contentType != ApplicationJSON || method == http.MethodGet || method == http.MethodDelete {
@@ -128,18 +265,32 @@ func (client *Client) newRequest(method string, urlStr string, body interface{},
 		}
 	}
 	req, _ := http.NewRequest(method, urlStr, bytes.NewReader(encodedBody))
+	req = req.WithContext(ctx)
 
 	req.Header.Set(UserAgentHeader, strings.TrimSpace(fmt.Sprintf("stormpath-sdk-go/%s %s", version, client.WebSDKToken)))
 	req.Header.Set(AcceptHeader, ApplicationJSON)
 	req.Header.Set(ContentTypeHeader, contentType)
 
-	uuid, _ := uuid.NewV4()
-	nonce := uuid.String()
-
-	Authenticate(req, encodedBody, time.Now().In(time.UTC), client.ClientConfiguration.APIKeyID, client.ClientConfiguration.APIKeySecret, nonce)
 	return req
 }
 
+//authenticate authenticates the given request with the client credentials,
+//using the RequestAuthenticator resolved by NewClient for the configured authentication scheme
+func (client *Client) authenticate(req *http.Request, payload []byte) {
+	req.Header.Del(AuthorizationHeader)
+
+	authenticator := client.authenticator
+	if authenticator == nil {
+		authenticator = SAuthc1RequestAuthenticator{}
+	}
+	authenticator.Authenticate(
+		req,
+		payload,
+		client.ClientConfiguration.APIKeyID,
+		client.ClientConfiguration.APIKeySecret,
+	)
+}
+
 //buildExpandParam coverts a slice of expand attributes to a url.Values with
 //only one value "expand=attr1,attr2,etc"
 func buildExpandParam(expandAttributes []string) url.Values {
@@ -194,73 +345,157 @@ func emptyPayload() []byte {
 //doWithResult executes the given StormpathRequest and serialize the response body into the given expected result,
 //it returns an error if any occurred while executing the request or serializing the response
 func (client *Client) doWithResult(request *http.Request, result interface{}) error {
-	var jsonData []byte
 	var err error
 
-	key := request.URL.String()
-
-	if client.Cache != nil && request.Method == http.MethodGet && client.Cache.Exists(key) {
-		jsonData = client.Cache.Get(key)
+	start := time.Now()
+	jsonData, freshUntil := client.cachedResponse(request)
+	cached := len(jsonData) > 0
+
+	//An expired entry is served while it is refreshed within the stale while revalidate window,
+	//past it the entry is only kept as a fallback in case Stormpath can't be reached
+	var stale []byte
+	if cached && isStale(freshUntil, start) {
+		if start.Sub(freshUntil) <= client.ClientConfiguration.CacheStaleRevalidate {
+			_, key := cacheKey(request.URL)
+			client.cacheMetrics.stale(key)
+			client.revalidate(request, result)
+		} else {
+			stale, jsonData, cached = jsonData, nil, false
+		}
 	}
 
-	if len(jsonData) == 0 {
-		response, err := client.execRequest(request)
+	shared := false
+	if !cached {
+		jsonData, shared, err = client.fetch(request)
 		if err != nil {
-			return err
-		}
-		jsonData, err = ioutil.ReadAll(response.Body)
-		if err != nil {
-			return err
+			return client.staleFallback(request, result, stale, freshUntil, err)
 		}
 	}
 
 	if result != nil {
 		err = json.NewDecoder(bytes.NewBuffer(jsonData)).Decode(result)
 	}
+	if request.Method == http.MethodGet {
+		_, key := cacheKey(request.URL)
+		client.cacheMetrics.request(key, cached, client.Cache != nil, time.Since(start))
+	}
 
-	if client.Cache != nil && err == nil && result != nil {
-		switch request.Method {
-		case http.MethodPost, http.MethodDelete:
-			client.Cache.Del(key)
-			break
-		case http.MethodGet:
-			c, ok := result.(Cacheable)
-			if ok &&
-				c.IsCacheable() &&
-				!strings.Contains(key, "passwordResetTokens") &&
-				!strings.Contains(key, "authTokens") {
-				client.Cache.Set(key, jsonData)
-			}
-		}
+	if err == nil && result != nil {
+		bindClient(result, client)
+		client.invalidateCache(request, jsonData)
+		client.cacheResponse(request, result, jsonData, cached || shared)
 	}
 
 	return err
 }
 
+//fetch executes the request and reads the response body, concurrent identical GET requests are coalesced
+//into a single request whose response is shared, in which case shared is true for every caller but the one
+//that executed it
+func (client *Client) fetch(request *http.Request) (data []byte, shared bool, err error) {
+	exec := func() ([]byte, error) {
+		response, err := client.execRequest(request)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(response.Body)
+	}
+
+	if request.Method != http.MethodGet || client.inflight == nil {
+		data, err = exec()
+		return data, false, err
+	}
+
+	_, key := cacheKey(request.URL)
+	for {
+		data, shared, err = client.inflight.do(request.Context(), key, exec)
+		//The caller that executed the request gave up, the callers still waiting for it execute it again
+		if err == errAbandonedRequest {
+			continue
+		}
+		if shared {
+			client.cacheMetrics.coalesce(key)
+		}
+		return data, shared, err
+	}
+}
+
 //do executes the StormpathRequest without expecting a response body as a result,
 //it returns an error if any occurred while executing the request
-func (client *Client) do(request *http.Request) error {
+func (client *Client) do(request *http.Request, _ interface{}) error {
 	_, err := client.execRequest(request)
+	if err == nil {
+		client.invalidateCache(request, emptyPayload())
+	}
 	return err
 }
 
-//execRequest executes a request, it would return a byte slice with the raw resoponse data and an error if any occurred
+//execRequest executes a request, it would return a byte slice with the raw resoponse data and an error if any occurred.
+//
+//Each attempt is signed with a fresh nonce and date, failed attempts are retried according to
+//the client retry configuration. While the circuit of the request endpoint class is open the request
+//fails right away with a CircuitOpenError.
 func (client *Client) execRequest(req *http.Request) (*http.Response, error) {
+	done, err := client.breaker.allow(req.Context(), breakerClass(req))
+	if err != nil {
+		Logger.Printf("[ERROR] %s [%s]", err, req.URL.String())
+		return nil, err
+	}
+
+	resp, err := client.execAttempts(req)
+	done(err)
+	return resp, err
+}
+
+//execAttempts executes the attempts of the request until one succeeds or it can't be retried
+func (client *Client) execAttempts(req *http.Request) (*http.Response, error) {
+	payload, err := requestPayload(req)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		client.authenticate(req, payload)
+		resp, err := client.execAttempt(req)
+
+		delay, retry := client.retryDelay(req, resp, err, attempt)
+		if !retry {
+			return resp, handleResponseError(req, resp, err)
+		}
+		Logger.Printf("[WARN] Retrying Stormpath request in %s, attempt %d failed [%s]", delay, attempt, req.URL.String())
+
+		if resp != nil {
+			io.Copy(ioutil.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, handleResponseError(req, nil, req.Context().Err())
+		case <-time.After(delay):
+		}
+
+		req = client.retryRequest(req, payload)
+	}
+}
+
+//execAttempt executes a single attempt of the given request
+func (client *Client) execAttempt(req *http.Request) (*http.Response, error) {
 	if logLevel == "DEBUG" {
 		//Print request
 		dump, _ := httputil.DumpRequest(req, true)
 		Logger.Printf("[DEBUG] Stormpath request\n%s", dump)
 	}
 	resp, err := client.HTTPClient.Do(req)
-	if logLevel == "DEBUG" {
+	if logLevel == "DEBUG" && err == nil {
 		//Print response
 		dump, _ := httputil.DumpResponse(resp, true)
 		Logger.Printf("[DEBUG] Stormpath response\n%s", dump)
 	}
-	return resp, handleResponseError(req, resp, err)
+	return resp, err
 }
 
-func checkRedirect(req *http.Request, via []*http.Request) error {
+func (client *Client) checkRedirect(req *http.Request, via []*http.Request) error {
 	//Go client defautl behavior is to bail after 10 redirects
 	if len(via) > 10 {
 		return errors.New("stopped after 10 redirects")
@@ -271,15 +506,11 @@ func checkRedirect(req *http.Request, via []*http.Request) error {
 		return nil
 	}
 	// Re-Authenticate the redirect request
-	uuid, _ := uuid.NewV4()
-	nonce := uuid.String()
-
-	//In Go 1.8 the authorization header remains in the redirect request causing auth errors
-	req.Header.Del(AuthorizationHeader)
-
+	//In Go 1.8 the authorization header remains in the redirect request causing auth errors,
+	//authenticate takes care of removing it before signing again.
 	//We can use an empty payload cause the only redirect is for the current tenant
 	//this could change in the future
-	Authenticate(req, emptyPayload(), time.Now().In(time.UTC), client.ClientConfiguration.APIKeyID, client.ClientConfiguration.APIKeySecret, nonce)
+	client.authenticate(req, emptyPayload())
 
 	return nil
 }
@@ -0,0 +1,124 @@
+package stormpath
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientFromContextDefaultsToInitClient(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, GetClient(), ClientFromContext(context.Background()))
+}
+
+func TestClientFromContext(t *testing.T) {
+	t.Parallel()
+
+	c := NewClient(LoadConfigurationWithCreds("id", "secret"), nil)
+
+	assert.Equal(t, c, ClientFromContext(NewContext(context.Background(), c)))
+	assert.NotEqual(t, GetClient(), c)
+}
+
+func TestCurrentTenantWithBoundClient(t *testing.T) {
+	t.Parallel()
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get(AuthorizationHeader)
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"` + "http://" + r.Host + `/v1/tenants/bound","name":"bound"}`))
+	}))
+	defer server.Close()
+
+	config := LoadConfigurationWithCreds("boundKeyID", "boundKeySecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	c := NewClient(config, nil)
+
+	boundTenant, err := CurrentTenantWithContext(NewContext(context.Background(), c))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "bound", boundTenant.Name)
+	assert.Contains(t, authorization, "sauthc1Id=boundKeyID/")
+}
+
+func TestResourceRemembersItsClient(t *testing.T) {
+	t.Parallel()
+
+	var authorizations []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Method+" "+r.URL.Path+" "+r.Header.Get(AuthorizationHeader))
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"http://` + r.Host + `/v1/accounts/1","username":"john",` +
+			`"groups":{"href":"http://` + r.Host + `/v1/accounts/1/groups","offset":0,"limit":25,"size":1,` +
+			`"items":[{"href":"http://` + r.Host + `/v1/groups/1","name":"admins"}]}}`))
+	}))
+	defer server.Close()
+
+	config := LoadConfigurationWithCreds("boundKeyID", "boundKeySecret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	account, err := GetAccountWithContext(NewContext(context.Background(), NewClient(config, nil)), server.URL+"/v1/accounts/1", MakeAccountCriteria().WithGroups(DefaultPageRequest))
+	assert.NoError(t, err)
+
+	assert.NoError(t, account.Update())
+	assert.NoError(t, account.Groups.Items[0].Update())
+
+	assert.Len(t, authorizations, 3)
+	assert.Contains(t, authorizations[1], "POST /v1/accounts/1 SAuthc1 sauthc1Id=boundKeyID/")
+	assert.Contains(t, authorizations[2], "POST /v1/groups/1 SAuthc1 sauthc1Id=boundKeyID/")
+}
+
+func TestResourceWithContextUsesItsClient(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"href":"http://` + r.Host + `/v1/accounts/1","username":"john"}`))
+	})
+	defer server.Close()
+
+	config := server.configuration()
+	config.APIKeyID = "boundKeyID"
+	ctx, _ := newTestContext(config, nil)
+
+	account, err := GetAccountWithContext(ctx, server.URL+"/v1/accounts/1", MakeAccountCriteria())
+	assert.NoError(t, err)
+
+	//A context without Client executes the calls with the Client the resource was fetched with
+	assert.NoError(t, account.UpdateWithContext(context.Background()))
+	assert.NoError(t, account.DeleteWithContext(context.Background()))
+
+	requests := server.received()
+	assert.Len(t, requests, 3)
+	for _, request := range requests[1:] {
+		assert.Contains(t, request.header.Get(AuthorizationHeader), "sauthc1Id=boundKeyID/")
+	}
+
+	//A Client bound to the context still takes precedence
+	other := server.configuration()
+	other.APIKeyID = "otherKeyID"
+	otherCtx, _ := newTestContext(other, nil)
+	assert.NoError(t, account.UpdateWithContext(otherCtx))
+	assert.Contains(t, server.received()[3].header.Get(AuthorizationHeader), "sauthc1Id=otherKeyID/")
+}
+
+func TestNoClient(t *testing.T) {
+	t.Parallel()
+
+	var c *Client
+
+	assert.Equal(t, ErrNoClient, c.get(context.Background(), "https://api.stormpath.com/v1/accounts/1", &Account{}))
+	assert.Equal(t, ErrNoClient, c.delete(context.Background(), "https://api.stormpath.com/v1/accounts/1"))
+	assert.Empty(t, c.buildRelativeURL("accounts"))
+}
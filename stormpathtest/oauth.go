@@ -0,0 +1,235 @@
+package stormpathtest
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/jarias/stormpath-sdk-go"
+	"gopkg.in/dgrijalva/jwt-go.v3"
+)
+
+//Default Stormpath OAuth policy token TTLs
+const (
+	AccessTokenTTL  = time.Hour
+	RefreshTokenTTL = 60 * 24 * time.Hour
+)
+
+//loginAttempt authenticates an account of the application with a basic login attempt
+func (s *Server) loginAttempt(w http.ResponseWriter, r *http.Request, application *record, query params) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	payload, ok := readPayload(w, r)
+	if !ok {
+		return
+	}
+
+	value, _ := payload["value"].(string)
+	credentials, err := base64.StdEncoding.DecodeString(value)
+	if t, _ := payload["type"].(string); t != "basic" || err != nil || !strings.Contains(string(credentials), ":") {
+		badRequest(2000, "Login attempt type basic and value are required.").write(w)
+		return
+	}
+	usernamePassword := strings.SplitN(string(credentials), ":", 2)
+
+	account, loginErr := s.login(application, usernamePassword[0], usernamePassword[1], linkHref(payload, "accountStore"))
+	if loginErr != nil {
+		loginErr.write(w)
+		return
+	}
+
+	var result interface{} = map[string]interface{}{"href": account.href}
+	if _, ok := query.expansions()["account"]; ok {
+		result = s.render(account, nil)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"account": result})
+}
+
+//login finds the application account with the given username or email and checks its password,
+//if accountStoreHref is not empty the account is looked up only in that account store
+func (s *Server) login(application *record, username string, password string, accountStoreHref string) (*record, *apiError) {
+	accounts := s.applicationAccounts(application)
+
+	if accountStoreHref != "" {
+		store, ok := s.records[accountStoreHref]
+		if !ok || len(s.all("accountStoreMappings", func(m *record) bool {
+			return m.links["application"] == application.href && m.links["accountStore"] == accountStoreHref
+		})) == 0 {
+			return nil, badRequest(2014, "The specified account store is not mapped to the application.")
+		}
+
+		if store.kind == "directories" {
+			accounts = s.all("accounts", linkedTo("directory", store.href))
+		} else {
+			accounts = s.linked(s.all("groupMemberships", linkedTo("group", store.href)), "account")
+		}
+	}
+
+	for _, account := range accounts {
+		if !strings.EqualFold(account.attributes["username"].(string), username) && !strings.EqualFold(account.attributes["email"].(string), username) {
+			continue
+		}
+		if account.password != password {
+			break
+		}
+
+		switch account.attributes["status"] {
+		case "DISABLED":
+			return nil, badRequest(7101, "Login attempt failed because the Account is disabled.")
+		case "UNVERIFIED":
+			return nil, badRequest(7102, "Login attempt failed because the Account is not verified.")
+		}
+		return account, nil
+	}
+
+	return nil, badRequest(7100, "Invalid username or password.")
+}
+
+//oauthToken implements the application oauth/token endpoint for the password and refresh_token grant types
+func (s *Server) oauthToken(w http.ResponseWriter, r *http.Request, application *record) {
+	if r.Method != http.MethodPost {
+		writeMethodNotAllowed(w)
+		return
+	}
+	r.ParseForm()
+
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		account, err := s.login(application, r.PostForm.Get("username"), r.PostForm.Get("password"), r.PostForm.Get("accountStore"))
+		if err != nil {
+			writeOAuthError(w, "invalid_grant", err.message)
+			return
+		}
+
+		refreshToken := s.createToken("refreshTokens", application, account, nil)
+		s.writeOAuthResponse(w, s.createToken("accessTokens", application, account, refreshToken), refreshToken)
+	case "refresh_token":
+		refreshToken := s.token("refreshTokens", application, r.PostForm.Get("refresh_token"))
+		if refreshToken == nil || refreshToken.expiresAt.Before(time.Now()) {
+			writeOAuthError(w, "invalid_grant", "Token is invalid.")
+			return
+		}
+
+		account := s.records[refreshToken.links["account"]]
+		if account.attributes["status"] != "ENABLED" {
+			writeOAuthError(w, "invalid_grant", "Login attempt failed because the Account is disabled.")
+			return
+		}
+		s.writeOAuthResponse(w, s.createToken("accessTokens", application, account, refreshToken), refreshToken)
+	default:
+		writeOAuthError(w, "unsupported_grant_type", "Unsupported grant type.")
+	}
+}
+
+//authToken validates an access or refresh token issued for the application
+func (s *Server) authToken(w http.ResponseWriter, r *http.Request, application *record, encoded string, query params) {
+	if r.Method != http.MethodGet {
+		writeMethodNotAllowed(w)
+		return
+	}
+
+	token := s.token("accessTokens", application, encoded)
+	if token == nil {
+		token = s.token("refreshTokens", application, encoded)
+	}
+	if token == nil {
+		writeError(w, http.StatusNotFound, 10014, "Token does not exist.", "Token does not exist. This can occur if the token has been manually deleted, or if the token has expired and removed by Stormpath.")
+		return
+	}
+	if token.expiresAt.Before(time.Now()) {
+		badRequest(10011, "Token is invalid because the expiration time has passed.").write(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.render(token, query.expansions()))
+}
+
+//token returns the token of the given kind issued for the application with the given JWT
+func (s *Server) token(kind string, application *record, encoded string) *record {
+	for _, token := range s.all(kind, linkedTo("application", application.href)) {
+		if token.attributes["jwt"] == encoded {
+			return token
+		}
+	}
+	return nil
+}
+
+//createToken issues a new access or refresh token, access tokens are owned by the refresh token issued with them
+//and are deleted with it
+func (s *Server) createToken(kind string, application *record, account *record, refreshToken *record) *record {
+	stt := "refresh"
+	ttl := RefreshTokenTTL
+	if kind == "accessTokens" {
+		stt = "access"
+		ttl = AccessTokenTTL
+	}
+
+	token := s.create(kind, map[string]interface{}{}, map[string]string{
+		"account":     account.href,
+		"application": application.href,
+		"tenant":      s.tenant.href,
+	})
+
+	now := time.Now()
+	token.expiresAt = now.Add(ttl)
+
+	claims := stormpath.AccessTokenClaims{}
+	claims.Id = stormpath.GetToken(token.href)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = token.expiresAt.Unix()
+	claims.Issuer = application.href
+	claims.Subject = account.href
+	if refreshToken != nil {
+		token.owner = refreshToken.href
+		claims.RefreshTokenID = stormpath.GetToken(refreshToken.href)
+	}
+
+	signed := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed.Header["kid"] = s.APIKeyID
+	signed.Header["stt"] = stt
+	encoded, _ := signed.SignedString([]byte(s.APIKeySecret))
+
+	token.attributes["jwt"] = encoded
+	token.attributes["expandedJwt"] = map[string]interface{}{
+		"claims": map[string]interface{}{
+			"exp": claims.ExpiresAt,
+			"iat": claims.IssuedAt,
+			"iss": claims.Issuer,
+			"jti": claims.Id,
+			"rti": claims.RefreshTokenID,
+			"sub": claims.Subject,
+		},
+		"header": map[string]interface{}{
+			"alg": jwt.SigningMethodHS256.Alg(),
+			"kid": s.APIKeyID,
+			"stt": stt,
+		},
+		"signature": encoded[strings.LastIndex(encoded, ".")+1:],
+	}
+
+	return token
+}
+
+func (s *Server) writeOAuthResponse(w http.ResponseWriter, accessToken *record, refreshToken *record) {
+	writeJSON(w, http.StatusOK, stormpath.OAuthResponse{
+		AccessToken:              accessToken.attributes["jwt"].(string),
+		RefreshToken:             refreshToken.attributes["jwt"].(string),
+		TokenType:                "Bearer",
+		ExpiresIn:                int(AccessTokenTTL / time.Second),
+		StormpathAccessTokenHref: accessToken.href,
+	})
+}
+
+//writeOAuthError writes an OAuth2 error response
+func writeOAuthError(w http.ResponseWriter, oauthError string, message string) {
+	writeJSON(w, http.StatusBadRequest, map[string]interface{}{
+		"status":  http.StatusBadRequest,
+		"code":    http.StatusBadRequest,
+		"error":   oauthError,
+		"message": message,
+	})
+}
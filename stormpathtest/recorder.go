@@ -0,0 +1,361 @@
+package stormpathtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/jarias/stormpath-sdk-go"
+)
+
+//RecorderMode defines if a Recorder records real interactions or replays recorded ones
+type RecorderMode int
+
+//Recorder modes
+const (
+	Replay RecorderMode = iota
+	Record
+)
+
+//Scrubbed replaces any secret value in the recorded interactions
+const Scrubbed = "[SCRUBBED]"
+
+//Headers never written to a fixture, they carry credentials, nonces and dates that change on every request
+var scrubbedHeaders = []string{
+	stormpath.AuthorizationHeader,
+	stormpath.StormpathDateHeader,
+	"Proxy-Authorization",
+	"Cookie",
+	"Set-Cookie",
+}
+
+//Request and response fields holding secrets or tokens, either JSON attributes, form or query values
+var scrubbedFields = map[string]bool{
+	"password":      true,
+	"secret":        true,
+	"apiKeySecret":  true,
+	"clientSecret":  true,
+	"client_secret": true,
+	"access_token":  true,
+	"refresh_token": true,
+	"id_token":      true,
+	"accessToken":   true,
+	"refreshToken":  true,
+	"jwt":           true,
+}
+
+//Path segment followed by a JWT, the token is scrubbed from the recorded and matched paths
+const authTokensSegment = "authTokens"
+
+//Recorder is an http.RoundTripper that records the Stormpath interactions to a fixture file
+//and replays them without network access.
+//
+//Recorded requests are scrubbed from credentials, secrets and tokens. Replayed requests are matched on method,
+//path and canonical query string, each recorded interaction is replayed once in the recorded order.
+//
+//The Recorder never modifies the request it is given, the body is read from GetBody or from a copy.
+type Recorder struct {
+	Mode      RecorderMode
+	Fixture   string
+	Transport http.RoundTripper
+	//Secrets holds extra values to scrub from the recorded interactions
+	Secrets []string
+
+	mutex        sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+//Interaction is a recorded request and response pair
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+//RecordedRequest is the scrubbed request of an Interaction
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	Path   string      `json:"path"`
+	Query  string      `json:"query,omitempty"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+//RecordedResponse is the scrubbed response of an Interaction
+type RecordedResponse struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+//NewRecorder creates a Recorder for the given fixture file,
+//in Replay mode the fixture is loaded right away and must exist.
+//
+//In Record mode the given transport executes the real requests, if it is nil http.DefaultTransport is used.
+func NewRecorder(fixture string, mode RecorderMode, transport http.RoundTripper) (*Recorder, error) {
+	r := &Recorder{Mode: mode, Fixture: fixture, Transport: transport}
+
+	if mode == Replay {
+		data, err := ioutil.ReadFile(fixture)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &r.interactions)
+		if err != nil {
+			return nil, err
+		}
+		r.replayed = make([]bool, len(r.interactions))
+	}
+
+	return r, nil
+}
+
+//Install plugs the Recorder into the given client HTTPClient, the client API key secret is scrubbed
+//from the recorded interactions and in Record mode the client transport executes the real requests.
+func (r *Recorder) Install(c *stormpath.Client) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Transport == nil {
+		r.Transport = c.HTTPClient.Transport
+	}
+	r.Secrets = append(r.Secrets, c.ClientConfiguration.APIKeySecret)
+	c.HTTPClient.Transport = r
+}
+
+//RoundTrip records or replays the given request depending on the Recorder mode
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, outgoing, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.Mode == Replay {
+		if outgoing.Body != nil {
+			outgoing.Body.Close()
+		}
+		return r.replay(req)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	resp, err := transport.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.interactions = append(r.interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   canonicalPath(req.URL),
+			Query:  canonicalQuery(req.URL),
+			Header: r.scrubHeader(req.Header),
+			Body:   r.scrubBody(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     r.scrubHeader(resp.Header),
+			Body:       r.scrubBody(respBody),
+		},
+	})
+
+	return resp, nil
+}
+
+//Stop writes the recorded interactions to the fixture file, in Replay mode it does nothing
+func (r *Recorder) Stop() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.Mode == Replay {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(r.Fixture, data, os.FileMode(0644))
+}
+
+//replay returns the first not yet replayed interaction matching the request method, path and query
+func (r *Recorder) replay(req *http.Request) (*http.Response, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	path := canonicalPath(req.URL)
+	query := canonicalQuery(req.URL)
+
+	for i, interaction := range r.interactions {
+		recorded := interaction.Request
+		if r.replayed[i] || recorded.Method != req.Method || canonicalPath(&url.URL{Path: recorded.Path}) != path || recorded.Query != query {
+			continue
+		}
+		r.replayed[i] = true
+
+		header := http.Header{}
+		for name, values := range interaction.Response.Header {
+			header[name] = values
+		}
+
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          ioutil.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("no recorded interaction left for %s %s?%s in %s", req.Method, path, query, r.Fixture)
+}
+
+//requestBody returns the request body and the request to send to the transport without modifying the given one,
+//the body is read from GetBody when the request has it, otherwise the request is cloned with a copy of its body
+func requestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+
+	if req.GetBody != nil {
+		copy, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer copy.Close()
+
+		body, err := ioutil.ReadAll(copy)
+		if err != nil {
+			return nil, nil, err
+		}
+		return body, req, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outgoing := req.Clone(req.Context())
+	outgoing.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return body, outgoing, nil
+}
+
+//canonicalPath returns the request path without the trailing slash the SDK adds before query strings,
+//the JWT of an authTokens path is scrubbed
+func canonicalPath(u *url.URL) string {
+	segments := strings.Split(strings.TrimSuffix(u.Path, "/"), "/")
+	for i := 0; i < len(segments)-1; i++ {
+		if segments[i] == authTokensSegment {
+			segments[i+1] = Scrubbed
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+//canonicalQuery returns the query string sorted by key with the secret values scrubbed
+func canonicalQuery(u *url.URL) string {
+	values := u.Query()
+	for name := range values {
+		if scrubbedFields[name] {
+			values.Set(name, Scrubbed)
+		}
+	}
+	return values.Encode()
+}
+
+func (r *Recorder) scrubHeader(header http.Header) http.Header {
+	scrubbed := http.Header{}
+	for name, values := range header {
+		for _, value := range values {
+			scrubbed.Add(name, r.scrubSecrets(value))
+		}
+	}
+	for _, name := range scrubbedHeaders {
+		scrubbed.Del(name)
+	}
+
+	return scrubbed
+}
+
+//scrubBody scrubs the secret fields of a JSON or form encoded body and any of the Recorder secrets
+func (r *Recorder) scrubBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var document interface{}
+	if json.Unmarshal(body, &document) == nil {
+		scrubbed, _ := json.Marshal(scrubJSON(document))
+		return r.scrubSecrets(string(scrubbed))
+	}
+
+	if values, err := url.ParseQuery(string(body)); err == nil && strings.Contains(string(body), "=") {
+		for name := range values {
+			if scrubbedFields[name] {
+				values.Set(name, Scrubbed)
+			}
+		}
+		return r.scrubSecrets(values.Encode())
+	}
+
+	return r.scrubSecrets(string(body))
+}
+
+//scrubJSON scrubs the secret attributes of a JSON document, basic login attempt values hold
+//the base64 encoded username and password so they are scrubbed too
+func scrubJSON(document interface{}) interface{} {
+	switch v := document.(type) {
+	case map[string]interface{}:
+		for name, value := range v {
+			if scrubbedFields[name] {
+				v[name] = Scrubbed
+			} else {
+				v[name] = scrubJSON(value)
+			}
+		}
+		if v["type"] == "basic" && v["value"] != nil {
+			v["value"] = Scrubbed
+		}
+	case []interface{}:
+		for i, value := range v {
+			v[i] = scrubJSON(value)
+		}
+	}
+	return document
+}
+
+func (r *Recorder) scrubSecrets(value string) string {
+	for _, secret := range r.Secrets {
+		if secret != "" {
+			value = strings.Replace(value, secret, Scrubbed, -1)
+		}
+	}
+	return value
+}
@@ -0,0 +1,192 @@
+package stormpathtest
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jarias/stormpath-sdk-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := ioutil.TempDir("", "stormpathtest")
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+
+	server := NewServer()
+	client := server.NewClient()
+
+	recorder, err := NewRecorder(fixture, Record, nil)
+	assert.NoError(t, err)
+	recorder.Install(client)
+	ctx := stormpath.NewContext(context.Background(), client)
+
+	application := createTestApplication(ctx, t)
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, application.RegisterAccountWithContext(ctx, account))
+	recordedTenant, err := stormpath.CurrentTenantWithContext(ctx)
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.Stop())
+	server.Close()
+
+	data, _ := ioutil.ReadFile(fixture)
+	assert.NotContains(t, string(data), server.APIKeySecret)
+	assert.NotContains(t, string(data), "Passw0rd!")
+	assert.NotContains(t, string(data), stormpath.AuthorizationHeader)
+	assert.NotContains(t, string(data), stormpath.StormpathDateHeader)
+
+	client = server.NewClient()
+	recorder, err = NewRecorder(fixture, Replay, nil)
+	assert.NoError(t, err)
+	recorder.Install(client)
+	ctx = stormpath.NewContext(context.Background(), client)
+
+	replayedApplication := &stormpath.Application{Name: "app"}
+	assert.NoError(t, stormpath.CreateApplicationWithContext(ctx, replayedApplication))
+	assert.Equal(t, application.Href, replayedApplication.Href)
+
+	replayedAccount := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, replayedApplication.RegisterAccountWithContext(ctx, replayedAccount))
+	assert.Equal(t, account.Href, replayedAccount.Href)
+
+	replayedTenant, err := stormpath.CurrentTenantWithContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, recordedTenant.Href, replayedTenant.Href)
+
+	_, err = stormpath.CurrentTenantWithContext(ctx)
+	assert.Error(t, err)
+}
+
+func TestRecordScrubsOAuthTokens(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := ioutil.TempDir("", "stormpathtest")
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+
+	server := NewServer()
+	client := server.NewClient()
+
+	recorder, err := NewRecorder(fixture, Record, nil)
+	assert.NoError(t, err)
+	recorder.Install(client)
+	ctx := stormpath.NewContext(context.Background(), client)
+
+	application := createTestApplication(ctx, t)
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, application.RegisterAccountWithContext(ctx, account))
+	response, err := application.GetOAuthTokenWithContext(ctx, "john", "Passw0rd!")
+	assert.NoError(t, err)
+	token, err := application.ValidateTokenWithContext(ctx, response.AccessToken)
+	assert.NoError(t, err)
+	_, err = application.RefreshOAuthTokenWithContext(ctx, response.RefreshToken)
+	assert.NoError(t, err)
+
+	assert.NoError(t, recorder.Stop())
+	server.Close()
+
+	data, _ := ioutil.ReadFile(fixture)
+	assert.NotContains(t, string(data), response.AccessToken)
+	assert.NotContains(t, string(data), response.RefreshToken)
+	assert.Contains(t, string(data), "/authTokens/"+Scrubbed)
+
+	client = server.NewClient()
+	recorder, err = NewRecorder(fixture, Replay, nil)
+	assert.NoError(t, err)
+	recorder.Install(client)
+	ctx = stormpath.NewContext(context.Background(), client)
+
+	replayedApplication := &stormpath.Application{Name: "app"}
+	assert.NoError(t, stormpath.CreateApplicationWithContext(ctx, replayedApplication))
+	assert.NoError(t, replayedApplication.RegisterAccountWithContext(ctx, stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")))
+	_, err = replayedApplication.GetOAuthTokenWithContext(ctx, "john", "Passw0rd!")
+	assert.NoError(t, err)
+
+	replayedToken, err := replayedApplication.ValidateTokenWithContext(ctx, "another.jwt.token")
+	assert.NoError(t, err)
+	assert.Equal(t, token.Href, replayedToken.Href)
+	assert.Equal(t, Scrubbed, replayedToken.JWT)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRecordKeepsRequestBody(t *testing.T) {
+	t.Parallel()
+
+	var sent []string
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		body, _ := ioutil.ReadAll(req.Body)
+		sent = append(sent, string(body))
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader("{}"))}, nil
+	})
+
+	recorder, err := NewRecorder("", Record, transport)
+	assert.NoError(t, err)
+
+	withGetBody, _ := http.NewRequest(http.MethodPost, "https://api.stormpath.com/v1/accounts", strings.NewReader(`{"email":"john@test.org"}`))
+	body := withGetBody.Body
+	_, err = recorder.RoundTrip(withGetBody)
+	assert.NoError(t, err)
+	assert.True(t, body == withGetBody.Body)
+
+	withoutGetBody, _ := http.NewRequest(http.MethodPost, "https://api.stormpath.com/v1/groups", ioutil.NopCloser(strings.NewReader(`{"name":"admins"}`)))
+	body = withoutGetBody.Body
+	_, err = recorder.RoundTrip(withoutGetBody)
+	assert.NoError(t, err)
+	assert.True(t, body == withoutGetBody.Body)
+
+	assert.Equal(t, []string{`{"email":"john@test.org"}`, `{"name":"admins"}`}, sent)
+	assert.Equal(t, `{"email":"john@test.org"}`, recorder.interactions[0].Request.Body)
+	assert.Equal(t, `{"name":"admins"}`, recorder.interactions[1].Request.Body)
+}
+
+func TestReplayMatchesCanonicalQuery(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := ioutil.TempDir("", "stormpathtest")
+	defer os.RemoveAll(dir)
+	fixture := filepath.Join(dir, "fixture.json")
+
+	ioutil.WriteFile(fixture, []byte(`[{
+		"request": {"method": "GET", "path": "/v1/applications/app/accounts", "query": "email=john%40test.org&limit=25&offset=0"},
+		"response": {"statusCode": 200, "header": {"Content-Type": ["application/json"]}, "body": "{\"href\":\"accounts\",\"size\":1,\"items\":[{\"email\":\"john@test.org\"}]}"}
+	}]`), 0644)
+
+	recorder, err := NewRecorder(fixture, Replay, nil)
+	assert.NoError(t, err)
+
+	client := stormpath.NewClient(stormpath.LoadConfigurationWithCreds("id", "secret"), nil)
+	recorder.Install(client)
+	ctx := stormpath.NewContext(context.Background(), client)
+
+	application := &stormpath.Application{}
+	application.Href = "https://api.stormpath.com/v1/applications/app"
+	application.Accounts = &stormpath.Accounts{}
+	application.Accounts.Href = application.Href + "/accounts"
+
+	accounts, err := application.GetAccountsWithContext(ctx, stormpath.MakeAccountsCriteria().EmailEq("john@test.org"))
+
+	assert.NoError(t, err)
+	assert.Len(t, accounts.Items, 1)
+	assert.Equal(t, "john@test.org", accounts.Items[0].Email)
+}
+
+func TestNewRecorderMissingFixture(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRecorder("./doesnotexist.json", Replay, nil)
+
+	assert.Error(t, err)
+}
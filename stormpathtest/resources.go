@@ -0,0 +1,587 @@
+package stormpathtest
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+//apiError is a Stormpath error response
+type apiError struct {
+	status  int
+	code    int
+	message string
+}
+
+func (e *apiError) write(w http.ResponseWriter) {
+	writeError(w, e.status, e.code, e.message, e.message)
+}
+
+func badRequest(code int, message string) *apiError {
+	return &apiError{http.StatusBadRequest, code, message}
+}
+
+func conflict(message string) *apiError {
+	return &apiError{http.StatusConflict, 2001, message}
+}
+
+var methodNotAllowed = &apiError{http.StatusMethodNotAllowed, http.StatusMethodNotAllowed, "Method not allowed."}
+
+//Attributes that can be set by a create or update request
+var writable = map[string][]string{
+	"tenants":      {},
+	"applications": {"name", "description", "status"},
+	"directories":  {"name", "description", "status"},
+	"accounts":     {"username", "email", "givenName", "middleName", "surname", "status"},
+	"groups":       {"name", "description", "status"},
+}
+
+//Resource names used in the error messages
+var resourceNames = map[string]string{
+	"applications": "Application",
+	"directories":  "Directory",
+	"groups":       "Group",
+}
+
+//Custom data keys that can't be set
+var reservedCustomData = map[string]bool{
+	"href":       true,
+	"createdAt":  true,
+	"modifiedAt": true,
+	"meta":       true,
+	"spMeta":     true,
+	"spmeta":     true,
+	"ionmeta":    true,
+	"ionMeta":    true,
+}
+
+//pick returns the writable string attributes of the payload
+func pick(kind string, payload map[string]interface{}) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for _, name := range writable[kind] {
+		if value, ok := payload[name].(string); ok {
+			attributes[name] = value
+		}
+	}
+	return attributes
+}
+
+func validStatus(kind string, attributes map[string]interface{}) *apiError {
+	status, ok := attributes["status"].(string)
+	if !ok {
+		return nil
+	}
+
+	status = strings.ToUpper(status)
+	if status != "ENABLED" && status != "DISABLED" && (kind != "accounts" || status != "UNVERIFIED") {
+		return badRequest(2002, "Invalid status value "+status+".")
+	}
+	attributes["status"] = status
+	return nil
+}
+
+func mergeCustomData(r *record, customData map[string]interface{}) {
+	for name, value := range customData {
+		if !reservedCustomData[name] {
+			r.customData[name] = value
+		}
+	}
+	r.modifiedAt = time.Now().UTC()
+}
+
+//mergePayloadCustomData merges the customData object of a create or update payload
+func mergePayloadCustomData(r *record, payload map[string]interface{}) {
+	if customData, ok := payload["customData"].(map[string]interface{}); ok {
+		mergeCustomData(r, customData)
+	}
+}
+
+//linkHref returns the href of a {"href": "..."} payload attribute
+func linkHref(payload map[string]interface{}, name string) string {
+	link, _ := payload[name].(map[string]interface{})
+	href, _ := link["href"].(string)
+	return href
+}
+
+//named returns the resource of the given kind with the given name, case insensitive
+func (s *Server) named(kind string, name string, predicate func(*record) bool) *record {
+	for _, r := range s.all(kind, predicate) {
+		if n, _ := r.attributes["name"].(string); strings.EqualFold(n, name) {
+			return r
+		}
+	}
+	return nil
+}
+
+func (s *Server) createRoot(w http.ResponseWriter, kind string, payload map[string]interface{}, query params) {
+	var created *record
+	var err *apiError
+
+	switch kind {
+	case "applications":
+		created, err = s.createApplication(payload, query.get("createDirectory"))
+	case "directories":
+		created, err = s.createDirectory(payload)
+	case "groupMemberships":
+		created, err = s.createMembership(payload)
+	case "accountStoreMappings":
+		created, err = s.createMapping(linkHref(payload, "application"), payload)
+	default:
+		err = methodNotAllowed
+	}
+
+	if err != nil {
+		err.write(w)
+		return
+	}
+	writeJSON(w, http.StatusCreated, s.render(created, query.expansions()))
+}
+
+func (s *Server) createChild(parent *record, name string, payload map[string]interface{}) (*record, *apiError) {
+	switch parent.kind + "/" + name {
+	case "directories/accounts":
+		return s.createAccount(parent, payload)
+	case "directories/groups":
+		return s.createGroup(parent, payload)
+	case "applications/accountStoreMappings":
+		return s.createMapping(parent.href, payload)
+	case "applications/accounts":
+		store := s.defaultStore(parent, "isDefaultAccountStore")
+		if store == nil {
+			return nil, badRequest(5100, "The application does not have a default account store.")
+		}
+		if store.kind == "directories" {
+			return s.createAccount(store, payload)
+		}
+		account, err := s.createAccount(s.records[store.links["directory"]], payload)
+		if err == nil {
+			s.create("groupMemberships", map[string]interface{}{}, map[string]string{"account": account.href, "group": store.href})
+		}
+		return account, err
+	case "applications/groups":
+		store := s.defaultStore(parent, "isDefaultGroupStore")
+		if store == nil {
+			return nil, badRequest(5101, "The application does not have a default group store.")
+		}
+		return s.createGroup(store, payload)
+	}
+
+	return nil, methodNotAllowed
+}
+
+func (s *Server) createApplication(payload map[string]interface{}, createDirectory string) (*record, *apiError) {
+	attributes := pick("applications", payload)
+	if err := validStatus("applications", attributes); err != nil {
+		return nil, err
+	}
+
+	name, _ := attributes["name"].(string)
+	if name == "" {
+		return nil, badRequest(2000, "Application name is required; it cannot be null, empty, or blank.")
+	}
+	if s.named("applications", name, nil) != nil {
+		return nil, conflict("Application name already exists.")
+	}
+
+	directoryName := ""
+	if createDirectory != "" && createDirectory != "false" {
+		directoryName = createDirectory
+		if createDirectory == "true" {
+			directoryName = name + " Directory"
+		}
+		if s.named("directories", directoryName, nil) != nil {
+			return nil, conflict("Directory name already exists.")
+		}
+	}
+
+	if _, ok := attributes["status"]; !ok {
+		attributes["status"] = "ENABLED"
+	}
+
+	application := s.create("applications", attributes, nil)
+	mergePayloadCustomData(application, payload)
+
+	if directoryName != "" {
+		directory := s.create("directories", map[string]interface{}{"name": directoryName, "status": "ENABLED"}, nil)
+		s.create(
+			"accountStoreMappings",
+			map[string]interface{}{"listIndex": 0, "isDefaultAccountStore": true, "isDefaultGroupStore": true},
+			map[string]string{"application": application.href, "accountStore": directory.href},
+		)
+	}
+
+	return application, nil
+}
+
+func (s *Server) createDirectory(payload map[string]interface{}) (*record, *apiError) {
+	attributes := pick("directories", payload)
+	if err := validStatus("directories", attributes); err != nil {
+		return nil, err
+	}
+
+	name, _ := attributes["name"].(string)
+	if name == "" {
+		return nil, badRequest(2000, "Directory name is required; it cannot be null, empty, or blank.")
+	}
+	if s.named("directories", name, nil) != nil {
+		return nil, conflict("Directory name already exists.")
+	}
+
+	if _, ok := attributes["status"]; !ok {
+		attributes["status"] = "ENABLED"
+	}
+
+	directory := s.create("directories", attributes, nil)
+	mergePayloadCustomData(directory, payload)
+
+	return directory, nil
+}
+
+func (s *Server) createAccount(directory *record, payload map[string]interface{}) (*record, *apiError) {
+	attributes := pick("accounts", payload)
+	if err := validStatus("accounts", attributes); err != nil {
+		return nil, err
+	}
+
+	email, _ := attributes["email"].(string)
+	if email == "" {
+		return nil, badRequest(2000, "Account email address is required; it cannot be null, empty, or blank.")
+	}
+	password, _ := payload["password"].(string)
+	if password == "" {
+		return nil, badRequest(2000, "Account password is required; it cannot be null, empty, or blank.")
+	}
+	if username, _ := attributes["username"].(string); username == "" {
+		attributes["username"] = email
+	}
+	if err := s.uniqueAccount(directory, nil, attributes); err != nil {
+		return nil, err
+	}
+
+	if _, ok := attributes["status"]; !ok {
+		attributes["status"] = "ENABLED"
+	}
+
+	account := s.create("accounts", attributes, map[string]string{"directory": directory.href})
+	account.password = password
+	mergePayloadCustomData(account, payload)
+
+	return account, nil
+}
+
+//uniqueAccount checks the account email and username are unique within the directory
+func (s *Server) uniqueAccount(directory *record, account *record, attributes map[string]interface{}) *apiError {
+	for _, other := range s.all("accounts", linkedTo("directory", directory.href)) {
+		if other == account {
+			continue
+		}
+		if email, ok := attributes["email"].(string); ok && strings.EqualFold(email, other.attributes["email"].(string)) {
+			return conflict("Account with that email already exists. Please choose another email.")
+		}
+		if username, ok := attributes["username"].(string); ok && strings.EqualFold(username, other.attributes["username"].(string)) {
+			return conflict("Account with that username already exists. Please choose another username.")
+		}
+	}
+	return nil
+}
+
+func (s *Server) createGroup(directory *record, payload map[string]interface{}) (*record, *apiError) {
+	attributes := pick("groups", payload)
+	if err := validStatus("groups", attributes); err != nil {
+		return nil, err
+	}
+
+	name, _ := attributes["name"].(string)
+	if name == "" {
+		return nil, badRequest(2000, "Group name is required; it cannot be null, empty, or blank.")
+	}
+	if s.named("groups", name, linkedTo("directory", directory.href)) != nil {
+		return nil, conflict("Group name already exists.")
+	}
+
+	if _, ok := attributes["status"]; !ok {
+		attributes["status"] = "ENABLED"
+	}
+
+	group := s.create("groups", attributes, map[string]string{"directory": directory.href})
+	mergePayloadCustomData(group, payload)
+
+	return group, nil
+}
+
+func (s *Server) createMembership(payload map[string]interface{}) (*record, *apiError) {
+	account, ok := s.records[linkHref(payload, "account")]
+	if !ok || account.kind != "accounts" {
+		return nil, badRequest(2000, "Group membership account is required.")
+	}
+	group, ok := s.records[linkHref(payload, "group")]
+	if !ok || group.kind != "groups" {
+		return nil, badRequest(2000, "Group membership group is required.")
+	}
+
+	if len(s.all("groupMemberships", func(m *record) bool {
+		return m.links["account"] == account.href && m.links["group"] == group.href
+	})) > 0 {
+		return nil, conflict("The account is already a member of the group.")
+	}
+
+	return s.create("groupMemberships", map[string]interface{}{}, map[string]string{"account": account.href, "group": group.href}), nil
+}
+
+func (s *Server) createMapping(applicationHref string, payload map[string]interface{}) (*record, *apiError) {
+	application, ok := s.records[applicationHref]
+	if !ok || application.kind != "applications" {
+		return nil, badRequest(2000, "Account store mapping application is required.")
+	}
+	store, ok := s.records[linkHref(payload, "accountStore")]
+	if !ok || (store.kind != "directories" && store.kind != "groups") {
+		return nil, badRequest(2000, "Account store mapping account store is required.")
+	}
+
+	mappings := s.all("accountStoreMappings", linkedTo("application", application.href))
+	for _, m := range mappings {
+		if m.links["accountStore"] == store.href {
+			return nil, conflict("The account store is already mapped to the application.")
+		}
+	}
+
+	mapping := s.create(
+		"accountStoreMappings",
+		map[string]interface{}{"listIndex": len(mappings), "isDefaultAccountStore": false, "isDefaultGroupStore": false},
+		map[string]string{"application": application.href, "accountStore": store.href},
+	)
+	if err := s.updateMapping(mapping, payload); err != nil {
+		s.delete(mapping)
+		return nil, err
+	}
+
+	return mapping, nil
+}
+
+//updateMapping updates the mapping list index and default store flags, only one mapping per application
+//can be the default account or group store
+func (s *Server) updateMapping(mapping *record, payload map[string]interface{}) *apiError {
+	if isDefault, ok := payload["isDefaultGroupStore"].(bool); ok && isDefault && s.records[mapping.links["accountStore"]].kind != "directories" {
+		return badRequest(5103, "Only directories can be the default group store.")
+	}
+
+	for _, flag := range []string{"isDefaultAccountStore", "isDefaultGroupStore"} {
+		isDefault, ok := payload[flag].(bool)
+		if !ok {
+			continue
+		}
+		if isDefault {
+			for _, m := range s.all("accountStoreMappings", linkedTo("application", mapping.links["application"])) {
+				m.attributes[flag] = false
+			}
+		}
+		mapping.attributes[flag] = isDefault
+	}
+
+	for _, name := range []string{"listIndex", "collectionResourceIndex"} {
+		if index, ok := payload[name].(float64); ok {
+			mapping.attributes["listIndex"] = int(index)
+		}
+	}
+
+	return nil
+}
+
+//update updates the writable attributes, password and custom data of the given resource
+func (s *Server) update(r *record, payload map[string]interface{}) *apiError {
+	if r.kind == "accountStoreMappings" {
+		if err := s.updateMapping(r, payload); err != nil {
+			return err
+		}
+		r.modifiedAt = time.Now().UTC()
+		return nil
+	}
+
+	if _, ok := writable[r.kind]; !ok {
+		return methodNotAllowed
+	}
+
+	attributes := pick(r.kind, payload)
+	if err := validStatus(r.kind, attributes); err != nil {
+		return err
+	}
+
+	if name, ok := attributes["name"].(string); ok {
+		var other *record
+		switch r.kind {
+		case "applications", "directories":
+			other = s.named(r.kind, name, nil)
+		case "groups":
+			other = s.named(r.kind, name, linkedTo("directory", r.links["directory"]))
+		}
+		if other != nil && other != r {
+			return conflict(resourceNames[r.kind] + " name already exists.")
+		}
+	}
+
+	if r.kind == "accounts" {
+		if err := s.uniqueAccount(s.records[r.links["directory"]], r, attributes); err != nil {
+			return err
+		}
+		if password, _ := payload["password"].(string); password != "" {
+			r.password = password
+		}
+	}
+
+	for name, value := range attributes {
+		r.attributes[name] = value
+	}
+	mergePayloadCustomData(r, payload)
+	r.modifiedAt = time.Now().UTC()
+
+	return nil
+}
+
+//delete deletes the given resource and the resources that depend on it
+func (s *Server) delete(r *record) {
+	delete(s.records, r.href)
+
+	dependants := []*record{}
+	switch r.kind {
+	case "applications":
+		dependants = append(dependants, s.all("accountStoreMappings", linkedTo("application", r.href))...)
+		dependants = append(dependants, s.all("accessTokens", linkedTo("application", r.href))...)
+		dependants = append(dependants, s.all("refreshTokens", linkedTo("application", r.href))...)
+	case "directories":
+		dependants = append(dependants, s.all("accounts", linkedTo("directory", r.href))...)
+		dependants = append(dependants, s.all("groups", linkedTo("directory", r.href))...)
+		dependants = append(dependants, s.all("accountStoreMappings", linkedTo("accountStore", r.href))...)
+	case "accounts":
+		dependants = append(dependants, s.all("groupMemberships", linkedTo("account", r.href))...)
+		dependants = append(dependants, s.all("accessTokens", linkedTo("account", r.href))...)
+		dependants = append(dependants, s.all("refreshTokens", linkedTo("account", r.href))...)
+	case "groups":
+		dependants = append(dependants, s.all("groupMemberships", linkedTo("group", r.href))...)
+		dependants = append(dependants, s.all("accountStoreMappings", linkedTo("accountStore", r.href))...)
+	case "refreshTokens":
+		dependants = append(dependants, s.all("accessTokens", func(t *record) bool { return t.owner == r.href })...)
+	}
+
+	for _, dependant := range dependants {
+		if _, exists := s.records[dependant.href]; exists {
+			s.delete(dependant)
+		}
+	}
+}
+
+//list returns the items of the given resource collection
+func (s *Server) list(parent *record, name string) ([]*record, bool) {
+	switch parent.kind + "/" + name {
+	case "tenants/applications", "tenants/directories", "tenants/accounts", "tenants/groups":
+		return s.all(name, nil), true
+	case "tenants/organizations", "applications/apiKeys", "accounts/apiKeys":
+		return []*record{}, true
+	case "applications/accountStoreMappings":
+		return s.mappings(parent), true
+	case "applications/accounts":
+		return s.applicationAccounts(parent), true
+	case "applications/groups":
+		return s.applicationGroups(parent), true
+	case "directories/accounts", "directories/groups":
+		return s.all(name, linkedTo("directory", parent.href)), true
+	case "accounts/groupMemberships":
+		return s.all("groupMemberships", linkedTo("account", parent.href)), true
+	case "accounts/groups":
+		return s.linked(s.all("groupMemberships", linkedTo("account", parent.href)), "group"), true
+	case "accounts/applications":
+		return s.all("applications", func(application *record) bool {
+			return contains(s.applicationAccounts(application), parent)
+		}), true
+	case "accounts/accessTokens", "accounts/refreshTokens":
+		return s.all(name, linkedTo("account", parent.href)), true
+	case "groups/accountMemberships":
+		return s.all("groupMemberships", linkedTo("group", parent.href)), true
+	case "groups/accounts":
+		return s.linked(s.all("groupMemberships", linkedTo("group", parent.href)), "account"), true
+	}
+
+	return nil, false
+}
+
+//mappings returns the account store mappings of the application sorted by list index
+func (s *Server) mappings(application *record) []*record {
+	mappings := s.all("accountStoreMappings", linkedTo("application", application.href))
+	sort.Stable(byListIndex(mappings))
+	return mappings
+}
+
+type byListIndex []*record
+
+func (m byListIndex) Len() int      { return len(m) }
+func (m byListIndex) Swap(i, j int) { m[i], m[j] = m[j], m[i] }
+func (m byListIndex) Less(i, j int) bool {
+	return m[i].attributes["listIndex"].(int) < m[j].attributes["listIndex"].(int)
+}
+
+func (s *Server) defaultStore(application *record, flag string) *record {
+	for _, m := range s.mappings(application) {
+		if m.attributes[flag] == true {
+			return s.records[m.links["accountStore"]]
+		}
+	}
+	return nil
+}
+
+//applicationAccounts returns the accounts of the application mapped account stores
+func (s *Server) applicationAccounts(application *record) []*record {
+	accounts := []*record{}
+	for _, m := range s.mappings(application) {
+		store := s.records[m.links["accountStore"]]
+		var storeAccounts []*record
+		if store.kind == "directories" {
+			storeAccounts = s.all("accounts", linkedTo("directory", store.href))
+		} else {
+			storeAccounts = s.linked(s.all("groupMemberships", linkedTo("group", store.href)), "account")
+		}
+		for _, account := range storeAccounts {
+			if !contains(accounts, account) {
+				accounts = append(accounts, account)
+			}
+		}
+	}
+	return accounts
+}
+
+//applicationGroups returns the groups of the application mapped account stores
+func (s *Server) applicationGroups(application *record) []*record {
+	groups := []*record{}
+	for _, m := range s.mappings(application) {
+		store := s.records[m.links["accountStore"]]
+		storeGroups := []*record{store}
+		if store.kind == "directories" {
+			storeGroups = s.all("groups", linkedTo("directory", store.href))
+		}
+		for _, group := range storeGroups {
+			if !contains(groups, group) {
+				groups = append(groups, group)
+			}
+		}
+	}
+	return groups
+}
+
+//linked returns the resources the given records link to with the given name
+func (s *Server) linked(records []*record, name string) []*record {
+	result := []*record{}
+	for _, r := range records {
+		if linked, ok := s.records[r.links[name]]; ok {
+			result = append(result, linked)
+		}
+	}
+	return result
+}
+
+func contains(records []*record, r *record) bool {
+	for _, other := range records {
+		if other == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+package stormpathtest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+//route dispatches the request to the resource, collection or action addressed by its path:
+//
+//	/v1/{kind}
+//	/v1/{kind}/{id}
+//	/v1/{kind}/{id}/customData[/{key}]
+//	/v1/{kind}/{id}/{collection}
+//	/v1/applications/{id}/{loginAttempts|oauth/token|authTokens/{jwt}}
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	query := params(r.URL.Query())
+
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/v1/"), "/"), "/")
+
+	if len(segments) == 1 {
+		if r.Method != http.MethodPost {
+			writeMethodNotAllowed(w)
+			return
+		}
+		payload, ok := readPayload(w, r)
+		if ok {
+			s.createRoot(w, segments[0], payload, query)
+		}
+		return
+	}
+
+	if segments[0] == "tenants" && segments[1] == "current" {
+		http.Redirect(w, r, s.tenant.href, http.StatusFound)
+		return
+	}
+
+	resource, ok := s.records[s.baseURL+segments[0]+"/"+segments[1]]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	if len(segments) == 2 {
+		s.serveResource(w, r, resource, query)
+		return
+	}
+
+	switch {
+	case segments[2] == "customData" && customDataAware[resource.kind]:
+		s.serveCustomData(w, r, resource, segments[3:])
+	case resource.kind == "applications" && segments[2] == "loginAttempts" && len(segments) == 3:
+		s.loginAttempt(w, r, resource, query)
+	case resource.kind == "applications" && segments[2] == "oauth" && len(segments) == 4 && segments[3] == "token":
+		s.oauthToken(w, r, resource)
+	case resource.kind == "applications" && segments[2] == "authTokens" && len(segments) == 4:
+		s.authToken(w, r, resource, segments[3], query)
+	case len(segments) == 3:
+		s.serveCollection(w, r, resource, segments[2], query)
+	default:
+		writeNotFound(w)
+	}
+}
+
+func (s *Server) serveResource(w http.ResponseWriter, r *http.Request, resource *record, query params) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.render(resource, query.expansions()))
+	case http.MethodPost:
+		payload, ok := readPayload(w, r)
+		if !ok {
+			return
+		}
+		if err := s.update(resource, payload); err != nil {
+			err.write(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, s.render(resource, query.expansions()))
+	case http.MethodDelete:
+		if resource.kind == "tenants" {
+			writeMethodNotAllowed(w)
+			return
+		}
+		s.delete(resource)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (s *Server) serveCollection(w http.ResponseWriter, r *http.Request, parent *record, name string, query params) {
+	items, ok := s.list(parent, name)
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.renderCollection(parent.href+"/"+name, items, query))
+	case http.MethodPost:
+		payload, ok := readPayload(w, r)
+		if !ok {
+			return
+		}
+		created, err := s.createChild(parent, name, payload)
+		if err != nil {
+			err.write(w)
+			return
+		}
+		writeJSON(w, http.StatusCreated, s.render(created, query.expansions()))
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+func (s *Server) serveCustomData(w http.ResponseWriter, r *http.Request, resource *record, key []string) {
+	if len(key) > 1 {
+		writeNotFound(w)
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(key) == 0:
+		writeJSON(w, http.StatusOK, renderCustomData(resource))
+	case r.Method == http.MethodGet:
+		value, ok := resource.customData[key[0]]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{key[0]: value})
+	case r.Method == http.MethodPost && len(key) == 0:
+		payload, ok := readPayload(w, r)
+		if !ok {
+			return
+		}
+		mergeCustomData(resource, payload)
+		writeJSON(w, http.StatusOK, renderCustomData(resource))
+	case r.Method == http.MethodDelete && len(key) == 0:
+		resource.customData = map[string]interface{}{}
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodDelete:
+		delete(resource.customData, key[0])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeMethodNotAllowed(w)
+	}
+}
+
+//readPayload decodes the JSON request body, an empty body is an empty payload
+func readPayload(w http.ResponseWriter, r *http.Request) (map[string]interface{}, bool) {
+	payload := map[string]interface{}{}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, http.StatusBadRequest, "Invalid request body.", err.Error())
+		return nil, false
+	}
+	if len(body) == 0 {
+		return payload, true
+	}
+
+	if err := json.Unmarshal(body, &payload); err != nil {
+		writeError(w, http.StatusBadRequest, http.StatusBadRequest, "Invalid JSON request body.", err.Error())
+		return nil, false
+	}
+
+	return payload, true
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter) {
+	writeError(w, http.StatusMethodNotAllowed, http.StatusMethodNotAllowed, "Method not allowed.", "The requested HTTP method is not supported by the resource.")
+}
@@ -0,0 +1,458 @@
+//Package stormpathtest provides an in-memory fake of the Stormpath REST API to test code using the SDK without
+//a Stormpath account or network access.
+//
+//	server := stormpathtest.Init()
+//	defer server.Close()
+//
+//	tenant, err := stormpath.CurrentTenant()
+package stormpathtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jarias/stormpath-sdk-go"
+	uuid "github.com/nu7hatch/gouuid"
+)
+
+//Server is an in-memory fake of the Stormpath REST API running on an httptest.Server.
+//
+//It implements tenants, applications, directories, accounts, groups, group memberships, account store mappings,
+//custom data, login attempts and the OAuth2 token endpoints. Requests must be authenticated with the server
+//API key using either the SAuthc1 or the Basic authentication scheme.
+type Server struct {
+	*httptest.Server
+	APIKeyID     string
+	APIKeySecret string
+
+	mutex   sync.Mutex
+	baseURL string
+	tenant  *record
+	records map[string]*record
+	order   []*record
+}
+
+//record is a stored resource, links holds the href of the related resources (directory, application, etc.)
+type record struct {
+	kind       string
+	href       string
+	attributes map[string]interface{}
+	links      map[string]string
+	customData map[string]interface{}
+	password   string
+	owner      string
+	expiresAt  time.Time
+	createdAt  time.Time
+	modifiedAt time.Time
+}
+
+//Sub collections exposed by each kind of resource
+var collections = map[string][]string{
+	"tenants":      {"applications", "directories", "accounts", "groups", "organizations"},
+	"applications": {"accounts", "groups", "accountStoreMappings", "apiKeys"},
+	"directories":  {"accounts", "groups"},
+	"accounts":     {"groups", "groupMemberships", "applications", "accessTokens", "refreshTokens", "apiKeys"},
+	"groups":       {"accounts", "accountMemberships"},
+}
+
+//Kinds of resources with custom data
+var customDataAware = map[string]bool{
+	"tenants":      true,
+	"applications": true,
+	"directories":  true,
+	"accounts":     true,
+	"groups":       true,
+}
+
+//NewServer starts a new fake Stormpath server with an empty tenant and a random API key
+func NewServer() *Server {
+	s := &Server{
+		APIKeyID:     newID(),
+		APIKeySecret: newID() + newID(),
+		records:      map[string]*record{},
+	}
+
+	s.Server = httptest.NewServer(s.authenticate(http.HandlerFunc(s.route)))
+	s.baseURL = s.URL + "/v1/"
+	s.tenant = s.create("tenants", map[string]interface{}{"name": "stormpathtest", "key": "stormpathtest"}, nil)
+
+	return s
+}
+
+//Init starts a new fake Stormpath server and initializes the default SDK client with it,
+//the client cache is disabled so every call observes the server state.
+func Init() *Server {
+	s := NewServer()
+	stormpath.Init(s.ClientConfiguration(), nil)
+	return s
+}
+
+//ClientConfiguration returns a client configuration pointing to the server with its API key
+func (s *Server) ClientConfiguration() stormpath.ClientConfiguration {
+	config := stormpath.LoadConfigurationWithCreds(s.APIKeyID, s.APIKeySecret)
+	config.BaseURL = s.baseURL
+	config.CacheManagerEnabled = false
+
+	return config
+}
+
+//NewClient returns a new SDK client pointing to the server, see stormpath.NewContext to use it
+func (s *Server) NewClient() *stormpath.Client {
+	return stormpath.NewClient(s.ClientConfiguration(), nil)
+}
+
+//TenantHref returns the href of the server tenant
+func (s *Server) TenantHref() string {
+	return s.tenant.href
+}
+
+//authenticate rejects any request not signed with the server API key
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	sauthc1 := stormpath.NewSAuthc1Verifier(func(apiKeyID string) (string, bool) {
+		return s.APIKeySecret, apiKeyID == s.APIKeyID
+	}).Handler(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKeyID, apiKeySecret, ok := r.BasicAuth(); ok {
+			if apiKeyID != s.APIKeyID || apiKeySecret != s.APIKeySecret {
+				writeError(w, http.StatusUnauthorized, http.StatusUnauthorized, "Authentication required.", "Invalid API key credentials.")
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+		sauthc1.ServeHTTP(w, r)
+	})
+}
+
+func newID() string {
+	id, _ := uuid.NewV4()
+	return strings.Replace(id.String(), "-", "", -1)
+}
+
+//create stores a new resource of the given kind
+func (s *Server) create(kind string, attributes map[string]interface{}, links map[string]string) *record {
+	now := time.Now().UTC()
+	r := &record{
+		kind:       kind,
+		href:       s.baseURL + kind + "/" + newID(),
+		attributes: attributes,
+		links:      map[string]string{},
+		customData: map[string]interface{}{},
+		createdAt:  now,
+		modifiedAt: now,
+	}
+	for name, href := range links {
+		r.links[name] = href
+	}
+	if s.tenant != nil && customDataAware[kind] {
+		r.links["tenant"] = s.tenant.href
+	}
+
+	s.records[r.href] = r
+	s.order = append(s.order, r)
+
+	return r
+}
+
+//all returns the resources of the given kind in creation order that satisfy the given predicate
+func (s *Server) all(kind string, predicate func(*record) bool) []*record {
+	result := []*record{}
+	for _, r := range s.order {
+		if _, exists := s.records[r.href]; exists && r.kind == kind && (predicate == nil || predicate(r)) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+//linkedTo returns a predicate matching the resources with the given link
+func linkedTo(name string, href string) func(*record) bool {
+	return func(r *record) bool {
+		return r.links[name] == href
+	}
+}
+
+//render returns the JSON representation of the given resource expanding the given attributes
+func (s *Server) render(r *record, expand map[string]params) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, value := range r.attributes {
+		result[name] = value
+	}
+
+	result["href"] = r.href
+	result["createdAt"] = r.createdAt
+	result["modifiedAt"] = r.modifiedAt
+
+	for name, href := range r.links {
+		result[name] = map[string]interface{}{"href": href}
+	}
+	for _, name := range collections[r.kind] {
+		result[name] = map[string]interface{}{"href": r.href + "/" + name}
+	}
+	if customDataAware[r.kind] {
+		result["customData"] = map[string]interface{}{"href": r.href + "/customData"}
+	}
+
+	switch r.kind {
+	case "applications":
+		result["defaultAccountStoreMapping"] = nil
+		result["defaultGroupStoreMapping"] = nil
+		for _, m := range s.all("accountStoreMappings", linkedTo("application", r.href)) {
+			if m.attributes["isDefaultAccountStore"] == true {
+				result["defaultAccountStoreMapping"] = map[string]interface{}{"href": m.href}
+			}
+			if m.attributes["isDefaultGroupStore"] == true {
+				result["defaultGroupStoreMapping"] = map[string]interface{}{"href": m.href}
+			}
+		}
+	case "directories":
+		result["provider"] = map[string]interface{}{"href": r.href + "/provider", "providerId": "stormpath"}
+	case "accounts":
+		result["fullName"] = strings.TrimSpace(strings.Join(nonEmpty(r.attributes["givenName"], r.attributes["middleName"], r.attributes["surname"]), " "))
+		result["emailVerificationToken"] = nil
+	}
+
+	for name, page := range expand {
+		link, ok := result[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		href, _ := link["href"].(string)
+		if expanded, ok := s.resolve(href, page); ok {
+			result[name] = expanded
+		}
+	}
+
+	return result
+}
+
+//resolve renders the resource or collection with the given href
+func (s *Server) resolve(href string, query params) (interface{}, bool) {
+	if r, ok := s.records[href]; ok {
+		return s.render(r, nil), true
+	}
+	if strings.HasSuffix(href, "/customData") {
+		if r, ok := s.records[strings.TrimSuffix(href, "/customData")]; ok && customDataAware[r.kind] {
+			return renderCustomData(r), true
+		}
+	}
+
+	i := strings.LastIndex(href, "/")
+	if i < 0 {
+		return nil, false
+	}
+	if parent, ok := s.records[href[:i]]; ok {
+		if items, ok := s.list(parent, href[i+1:]); ok {
+			return s.renderCollection(href, items, query), true
+		}
+	}
+
+	return nil, false
+}
+
+//params holds the query parameters of a request
+type params map[string][]string
+
+func (q params) get(name string) string {
+	if values := q[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func (q params) int(name string, def int) int {
+	if i, err := strconv.Atoi(q.get(name)); err == nil && i >= 0 {
+		return i
+	}
+	return def
+}
+
+//expansions parses the expand query parameter, "groups(offset:0,limit:10),directory"
+func (q params) expansions() map[string]params {
+	result := map[string]params{}
+
+	expand := q.get("expand")
+	for expand != "" {
+		name := expand
+		page := params{}
+
+		end := strings.IndexAny(expand, ",(")
+		if end >= 0 && expand[end] == '(' {
+			closing := strings.IndexByte(expand, ')')
+			if closing < 0 {
+				closing = len(expand) - 1
+			}
+			for _, option := range strings.Split(expand[end+1:closing], ",") {
+				kv := strings.SplitN(option, ":", 2)
+				if len(kv) == 2 {
+					page[strings.TrimSpace(kv[0])] = []string{strings.TrimSpace(kv[1])}
+				}
+			}
+			name = expand[:end]
+			expand = strings.TrimPrefix(expand[closing+1:], ",")
+		} else if end >= 0 {
+			name = expand[:end]
+			expand = expand[end+1:]
+		} else {
+			expand = ""
+		}
+
+		result[strings.TrimSpace(name)] = page
+	}
+
+	return result
+}
+
+//renderCollection renders a page of the given items, they can be filtered by any attribute or the q query parameter,
+//a * at the beginning or end of a filter value works as a wildcard.
+func (s *Server) renderCollection(href string, items []*record, query params) map[string]interface{} {
+	expand := query.expansions()
+
+	filtered := []map[string]interface{}{}
+	for _, item := range items {
+		rendered := s.render(item, expand)
+		if matches(rendered, query) {
+			filtered = append(filtered, rendered)
+		}
+	}
+
+	if orderBy := strings.Fields(query.get("orderBy")); len(orderBy) > 0 {
+		sort.Stable(orderedItems{filtered, orderBy[0], len(orderBy) > 1 && strings.EqualFold(orderBy[1], "desc")})
+	}
+
+	offset := query.int("offset", 0)
+	limit := query.int("limit", 25)
+	if limit == 0 || limit > 100 {
+		limit = 100
+	}
+
+	page := []map[string]interface{}{}
+	for i := offset; i < len(filtered) && i < offset+limit; i++ {
+		page = append(page, filtered[i])
+	}
+
+	return map[string]interface{}{
+		"href":   href,
+		"offset": offset,
+		"limit":  limit,
+		"size":   len(filtered),
+		"items":  page,
+	}
+}
+
+//orderedItems sorts rendered items by a string attribute, case insensitive
+type orderedItems struct {
+	items     []map[string]interface{}
+	attribute string
+	desc      bool
+}
+
+func (o orderedItems) Len() int      { return len(o.items) }
+func (o orderedItems) Swap(i, j int) { o.items[i], o.items[j] = o.items[j], o.items[i] }
+func (o orderedItems) Less(i, j int) bool {
+	a, _ := o.items[i][o.attribute].(string)
+	b, _ := o.items[j][o.attribute].(string)
+	if o.desc {
+		return strings.ToLower(a) > strings.ToLower(b)
+	}
+	return strings.ToLower(a) < strings.ToLower(b)
+}
+
+var reservedQueryParams = map[string]bool{
+	"offset":  true,
+	"limit":   true,
+	"expand":  true,
+	"orderBy": true,
+	"q":       true,
+}
+
+func matches(rendered map[string]interface{}, query params) bool {
+	for name, values := range query {
+		if reservedQueryParams[name] || len(values) == 0 {
+			continue
+		}
+		value, _ := rendered[name].(string)
+		if !match(value, values[0]) {
+			return false
+		}
+	}
+
+	if q := strings.ToLower(query.get("q")); q != "" {
+		for _, value := range rendered {
+			if s, ok := value.(string); ok && strings.Contains(strings.ToLower(s), q) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+func match(value string, pattern string) bool {
+	value = strings.ToLower(value)
+	pattern = strings.ToLower(pattern)
+
+	prefix := strings.HasPrefix(pattern, "*")
+	suffix := strings.HasSuffix(pattern, "*") && len(pattern) > 1
+	pattern = strings.Trim(pattern, "*")
+
+	switch {
+	case prefix && suffix:
+		return strings.Contains(value, pattern)
+	case prefix:
+		return strings.HasSuffix(value, pattern)
+	case suffix:
+		return strings.HasPrefix(value, pattern)
+	}
+	return value == pattern
+}
+
+func renderCustomData(r *record) map[string]interface{} {
+	result := map[string]interface{}{}
+	for name, value := range r.customData {
+		result[name] = value
+	}
+	result["href"] = r.href + "/customData"
+	result["createdAt"] = r.createdAt
+	result["modifiedAt"] = r.modifiedAt
+
+	return result
+}
+
+func nonEmpty(values ...interface{}) []string {
+	result := []string{}
+	for _, value := range values {
+		if s, ok := value.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set(stormpath.ContentTypeHeader, stormpath.ApplicationJSON)
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+//writeError writes a Stormpath error response
+func writeError(w http.ResponseWriter, status int, code int, message string, developerMessage string) {
+	writeJSON(w, status, map[string]interface{}{
+		"status":           status,
+		"code":             code,
+		"message":          message,
+		"developerMessage": developerMessage,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, http.StatusNotFound, "The requested resource does not exist.", "The requested resource does not exist.")
+}
@@ -0,0 +1,253 @@
+package stormpathtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jarias/stormpath-sdk-go"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestContext() (*Server, context.Context) {
+	server := NewServer()
+	return server, stormpath.NewContext(context.Background(), server.NewClient())
+}
+
+func createTestApplication(ctx context.Context, t *testing.T) *stormpath.Application {
+	application := &stormpath.Application{Name: "app"}
+
+	err := stormpath.CreateApplicationWithContext(ctx, application)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return application
+}
+
+func TestInit(t *testing.T) {
+	server := Init()
+	defer server.Close()
+
+	tenant, err := stormpath.CurrentTenant()
+
+	assert.NoError(t, err)
+	assert.Equal(t, server.TenantHref(), tenant.Href)
+	assert.Equal(t, "stormpathtest", tenant.Name)
+}
+
+func TestRejectsInvalidCredentials(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+
+	config := server.ClientConfiguration()
+	config.APIKeySecret = "wrong"
+
+	_, err := stormpath.CurrentTenantWithContext(stormpath.NewContext(context.Background(), stormpath.NewClient(config, nil)))
+
+	assert.Error(t, err)
+	assert.Equal(t, 401, err.(stormpath.Error).Status)
+
+	config = server.ClientConfiguration()
+	config.AuthenticationScheme = stormpath.BasicScheme
+
+	tenant, err := stormpath.CurrentTenantWithContext(stormpath.NewContext(context.Background(), stormpath.NewClient(config, nil)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, server.TenantHref(), tenant.Href)
+}
+
+func TestCreateApplication(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+
+	assert.NotEmpty(t, application.Href)
+	assert.Equal(t, stormpath.Enabled, application.Status)
+	assert.NotNil(t, application.DefaultAccountStoreMapping)
+
+	mappings, err := application.GetAccountStoreMappingsWithContext(ctx, stormpath.MakeApplicationAccountStoreMappingsCriteria())
+	assert.NoError(t, err)
+	assert.Len(t, mappings.Items, 1)
+	assert.True(t, mappings.Items[0].IsDefaultAccountStore)
+
+	err = stormpath.CreateApplicationWithContext(ctx, &stormpath.Application{Name: "APP"})
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(stormpath.Error).Status)
+
+	err = stormpath.CreateApplicationWithContext(ctx, &stormpath.Application{})
+	assert.Error(t, err)
+	assert.Equal(t, 400, err.(stormpath.Error).Status)
+}
+
+func TestRegisterAndAuthenticateAccount(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	err := application.RegisterAccountWithContext(ctx, account)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "John Doe", account.FullName)
+	assert.Empty(t, account.Password)
+
+	err = application.RegisterAccountWithContext(ctx, stormpath.NewAccount("other", "Passw0rd!", "JOHN@test.org", "John", "Doe"))
+	assert.Error(t, err)
+	assert.Equal(t, 409, err.(stormpath.Error).Status)
+
+	authenticated, err := application.AuthenticateAccountWithContext(ctx, "john@test.org", "Passw0rd!", "")
+	assert.NoError(t, err)
+	assert.Equal(t, account.Href, authenticated.Href)
+
+	_, err = application.AuthenticateAccountWithContext(ctx, "john", "wrong", "")
+	assert.Error(t, err)
+	assert.Equal(t, 7100, err.(stormpath.Error).Code)
+
+	account.Status = stormpath.Disabled
+	assert.NoError(t, account.UpdateWithContext(ctx))
+
+	_, err = application.AuthenticateAccountWithContext(ctx, "john", "Passw0rd!", "")
+	assert.Error(t, err)
+	assert.Equal(t, 7101, err.(stormpath.Error).Code)
+
+	accounts, err := application.GetAccountsWithContext(ctx, stormpath.MakeAccountsCriteria().EmailEq("JOHN@*"))
+	assert.NoError(t, err)
+	assert.Len(t, accounts.Items, 1)
+	assert.Equal(t, 1, accounts.GetSize())
+}
+
+func TestGroupMemberships(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, application.RegisterAccountWithContext(ctx, account))
+
+	group := stormpath.NewGroup("admins")
+	assert.NoError(t, application.CreateGroupWithContext(ctx, group))
+
+	_, err := account.AddToGroupWithContext(ctx, group)
+	assert.NoError(t, err)
+
+	memberships, err := account.GetGroupMembershipsWithContext(ctx, stormpath.MakeGroupMemershipCriteria())
+	assert.NoError(t, err)
+	assert.Len(t, memberships.Items, 1)
+	assert.Equal(t, group.Href, memberships.Items[0].Group.Href)
+
+	groupAccounts, err := group.GetAccountsWithContext(ctx, stormpath.MakeAccountsCriteria())
+	assert.NoError(t, err)
+	assert.Len(t, groupAccounts.Items, 1)
+
+	assert.NoError(t, account.RemoveFromGroupWithContext(ctx, group))
+
+	memberships, err = account.GetGroupMembershipsWithContext(ctx, stormpath.MakeGroupMemershipCriteria())
+	assert.NoError(t, err)
+	assert.Len(t, memberships.Items, 0)
+}
+
+func TestAccountStoreMappings(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+
+	directory := stormpath.NewDirectory("partners")
+	assert.NoError(t, stormpath.CreateDirectoryWithContext(ctx, directory))
+
+	mapping := stormpath.NewApplicationAccountStoreMapping(application.Href, directory.Href)
+	assert.NoError(t, mapping.SaveWithContext(ctx))
+
+	partner := stormpath.NewAccount("partner", "Passw0rd!", "partner@test.org", "Partner", "Doe")
+	assert.NoError(t, directory.RegisterAccountWithContext(ctx, partner))
+
+	_, err := application.AuthenticateAccountWithContext(ctx, "partner", "Passw0rd!", directory.Href)
+	assert.NoError(t, err)
+
+	assert.Error(t, stormpath.NewApplicationAccountStoreMapping(application.Href, directory.Href).SaveWithContext(ctx))
+
+	assert.NoError(t, application.PurgeWithContext(ctx))
+
+	_, err = stormpath.GetDirectoryWithContext(ctx, directory.Href, stormpath.MakeDirectoryCriteria())
+	assert.Error(t, err)
+	assert.Equal(t, 404, err.(stormpath.Error).Status)
+}
+
+func TestCustomData(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+
+	customData, err := application.UpdateCustomDataWithContext(ctx, stormpath.CustomData{"plan": "gold", "href": "ignored"})
+	assert.NoError(t, err)
+	assert.Equal(t, "gold", customData["plan"])
+	assert.Equal(t, application.Href+"/customData", customData["href"])
+
+	customData, err = application.GetCustomDataWithContext(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, "gold", customData["plan"])
+
+	assert.NoError(t, application.DeleteCustomDataWithContext(ctx))
+
+	customData, err = application.GetCustomDataWithContext(ctx)
+	assert.NoError(t, err)
+	assert.NotContains(t, customData, "plan")
+}
+
+func TestOAuthTokens(t *testing.T) {
+	t.Parallel()
+
+	server, ctx := newTestContext()
+	defer server.Close()
+
+	application := createTestApplication(ctx, t)
+	account := stormpath.NewAccount("john", "Passw0rd!", "john@test.org", "John", "Doe")
+	assert.NoError(t, application.RegisterAccountWithContext(ctx, account))
+
+	_, err := application.GetOAuthTokenWithContext(ctx, "john", "wrong")
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_grant", err.(stormpath.Error).OAuth2Error)
+
+	response, err := application.GetOAuthTokenWithContext(ctx, "john", "Passw0rd!")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer", response.TokenType)
+	assert.NotEmpty(t, response.RefreshToken)
+
+	token, err := application.ValidateTokenWithContext(ctx, response.AccessToken)
+	assert.NoError(t, err)
+	assert.Equal(t, account.Href, token.Account.Href)
+	assert.Equal(t, "access", token.ExpandedJWT.Header.STT)
+	assert.Equal(t, response.StormpathAccessTokenHref, token.Href)
+
+	refreshed, err := application.RefreshOAuthTokenWithContext(ctx, response.RefreshToken)
+	assert.NoError(t, err)
+	assert.NotEqual(t, response.AccessToken, refreshed.AccessToken)
+
+	refreshTokens, err := account.GetRefreshTokensWithContext(ctx, stormpath.MakeOAuthTokensCriteria())
+	assert.NoError(t, err)
+	assert.Len(t, refreshTokens.Items, 1)
+
+	assert.NoError(t, refreshTokens.Items[0].DeleteWithContext(ctx))
+
+	_, err = application.ValidateTokenWithContext(ctx, refreshed.AccessToken)
+	assert.Error(t, err)
+
+	_, err = application.RefreshOAuthTokenWithContext(ctx, response.RefreshToken)
+	assert.Error(t, err)
+}
@@ -1,5 +1,7 @@
 package stormpath
 
+import "context"
+
 //Tenant
 //
 //When you sign up for Stormpath, a private data space is created for you. This space is represented as a Tenant resource in the Stormpath REST API. Your Tenant resource can be thought of as your global starting point. You can access everything in your space by accessing your Tenant resource first and then interacting with its other linked resources (Applications, Directories, etc).
@@ -16,9 +18,15 @@ type Tenant struct {
 
 //CurrentTenant retrieves the Tenant associated with the current API key.
 func CurrentTenant() (*Tenant, error) {
+	return CurrentTenantWithContext(context.Background())
+}
+
+//CurrentTenantWithContext is the same as CurrentTenant with the addition of a context.Context
+func CurrentTenantWithContext(ctx context.Context) (*Tenant, error) {
+	c := ClientFromContext(ctx)
 	tenant := &Tenant{}
 
-	err := client.get(buildRelativeURL("tenants", "current"), tenant)
+	err := c.get(ctx, c.buildRelativeURL("tenants", "current"), tenant)
 
 	return tenant, err
 }
@@ -27,68 +35,178 @@ func CurrentTenant() (*Tenant, error) {
 //
 //The collection can be filtered and/or paginated by passing the desire ApplicationCriteria value.
 func (tenant *Tenant) GetApplications(criteria ApplicationCriteria) (*Applications, error) {
+	return tenant.GetApplicationsWithContext(tenant.clientContext(), criteria)
+}
+
+//GetApplicationsWithContext is the same as GetApplications with the addition of a context.Context
+func (tenant *Tenant) GetApplicationsWithContext(ctx context.Context, criteria ApplicationCriteria) (*Applications, error) {
+	ctx = tenant.withClient(ctx)
 	apps := &Applications{}
 
-	err := client.get(buildAbsoluteURL(tenant.Applications.Href, criteria.toQueryString()), apps)
-	if err != nil {
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(tenant.Applications.Href, criteria.toQueryString()), apps)
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return apps, nil
+	return apps, err
+}
+
+//IterateApplications returns an ApplicationIterator over all the applications associated with the Tenant, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (tenant *Tenant) IterateApplications(criteria ApplicationCriteria) *ApplicationIterator {
+	return tenant.IterateApplicationsWithContext(tenant.clientContext(), criteria)
+}
+
+//IterateApplicationsWithContext is the same as IterateApplications with the addition of a context.Context
+func (tenant *Tenant) IterateApplicationsWithContext(ctx context.Context, criteria ApplicationCriteria) *ApplicationIterator {
+	ctx = tenant.withClient(ctx)
+	return newApplicationIterator(ctx, tenant.Applications.Href, criteria.baseCriteria)
 }
 
 //GetAccounts retrieves the collection of all the accounts associated with the Tenant.
 //
 //The collection can be filtered and/or paginated by passing the desire AccountCriteria value.
 func (tenant *Tenant) GetAccounts(criteria AccountCriteria) (*Accounts, error) {
+	return tenant.GetAccountsWithContext(tenant.clientContext(), criteria)
+}
+
+//GetAccountsWithContext is the same as GetAccounts with the addition of a context.Context
+func (tenant *Tenant) GetAccountsWithContext(ctx context.Context, criteria AccountCriteria) (*Accounts, error) {
+	ctx = tenant.withClient(ctx)
 	accounts := &Accounts{}
 
-	err := client.get(buildAbsoluteURL(tenant.Accounts.Href, criteria.toQueryString()), accounts)
-	if err != nil {
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(tenant.Accounts.Href, criteria.toQueryString()), accounts)
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return accounts, nil
+	return accounts, err
+}
+
+//IterateAccounts returns an AccountIterator over all the accounts associated with the Tenant, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (tenant *Tenant) IterateAccounts(criteria AccountCriteria) *AccountIterator {
+	return tenant.IterateAccountsWithContext(tenant.clientContext(), criteria)
+}
+
+//IterateAccountsWithContext is the same as IterateAccounts with the addition of a context.Context
+func (tenant *Tenant) IterateAccountsWithContext(ctx context.Context, criteria AccountCriteria) *AccountIterator {
+	ctx = tenant.withClient(ctx)
+	return newAccountIterator(ctx, tenant.Accounts.Href, criteria.baseCriteria)
+}
+
+//ScanAccounts calls fn for every one of the accounts associated with the Tenant, in order, fetching up to concurrency pages in parallel.
+//
+//It is meant for large collections, once the first page returns the collection size the next pages are prefetched.
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+//The scan stops at the first error, including an error returned by fn.
+func (tenant *Tenant) ScanAccounts(criteria AccountCriteria, concurrency int, fn func(*Account) error) error {
+	return tenant.ScanAccountsWithContext(tenant.clientContext(), criteria, concurrency, fn)
+}
+
+//ScanAccountsWithContext is the same as ScanAccounts with the addition of a context.Context, cancelling it stops the scan
+func (tenant *Tenant) ScanAccountsWithContext(ctx context.Context, criteria AccountCriteria, concurrency int, fn func(*Account) error) error {
+	ctx = tenant.withClient(ctx)
+	return scanAccounts(ctx, tenant.Accounts.Href, criteria.baseCriteria, concurrency, fn)
 }
 
 //GetGroups retrieves the collection of all the groups associated with the Tenant.
 //
 //The collection can be filtered and/or paginated by passing the desire GroupCriteria value.
 func (tenant *Tenant) GetGroups(criteria GroupCriteria) (*Groups, error) {
+	return tenant.GetGroupsWithContext(tenant.clientContext(), criteria)
+}
+
+//GetGroupsWithContext is the same as GetGroups with the addition of a context.Context
+func (tenant *Tenant) GetGroupsWithContext(ctx context.Context, criteria GroupCriteria) (*Groups, error) {
+	ctx = tenant.withClient(ctx)
 	groups := &Groups{}
 
-	err := client.get(buildAbsoluteURL(tenant.Groups.Href, criteria.toQueryString()), groups)
-	if err != nil {
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(tenant.Groups.Href, criteria.toQueryString()), groups)
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return groups, nil
+	return groups, err
+}
+
+//IterateGroups returns a GroupIterator over all the groups associated with the Tenant, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (tenant *Tenant) IterateGroups(criteria GroupCriteria) *GroupIterator {
+	return tenant.IterateGroupsWithContext(tenant.clientContext(), criteria)
+}
+
+//IterateGroupsWithContext is the same as IterateGroups with the addition of a context.Context
+func (tenant *Tenant) IterateGroupsWithContext(ctx context.Context, criteria GroupCriteria) *GroupIterator {
+	ctx = tenant.withClient(ctx)
+	return newGroupIterator(ctx, tenant.Groups.Href, criteria.baseCriteria)
 }
 
 //GetDirectories retrieves the collection of all the directories associated with the Tenant.
 //
 //The collection can be filtered and/or paginated by passing the desire DirectoryCriteria value
 func (tenant *Tenant) GetDirectories(criteria DirectoryCriteria) (*Directories, error) {
+	return tenant.GetDirectoriesWithContext(tenant.clientContext(), criteria)
+}
+
+//GetDirectoriesWithContext is the same as GetDirectories with the addition of a context.Context
+func (tenant *Tenant) GetDirectoriesWithContext(ctx context.Context, criteria DirectoryCriteria) (*Directories, error) {
+	ctx = tenant.withClient(ctx)
 	directories := &Directories{}
 
-	err := client.get(buildAbsoluteURL(tenant.Directories.Href, criteria.toQueryString()), directories)
-	if err != nil {
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(tenant.Directories.Href, criteria.toQueryString()), directories)
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return directories, nil
+	return directories, err
+}
+
+//IterateDirectories returns a DirectoryIterator over all the directories associated with the Tenant, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (tenant *Tenant) IterateDirectories(criteria DirectoryCriteria) *DirectoryIterator {
+	return tenant.IterateDirectoriesWithContext(tenant.clientContext(), criteria)
+}
+
+//IterateDirectoriesWithContext is the same as IterateDirectories with the addition of a context.Context
+func (tenant *Tenant) IterateDirectoriesWithContext(ctx context.Context, criteria DirectoryCriteria) *DirectoryIterator {
+	ctx = tenant.withClient(ctx)
+	return newDirectoryIterator(ctx, tenant.Directories.Href, criteria.baseCriteria)
 }
 
 //GetOrganizations retrieves the collection of all the organizations associated with the Tenant.
 //
 //The collection can be filtered and/or paginated by passing the desire OrganizationCriteria value
 func (tenant *Tenant) GetOrganizations(criteria OrganizationCriteria) (*Organizations, error) {
+	return tenant.GetOrganizationsWithContext(tenant.clientContext(), criteria)
+}
+
+//GetOrganizationsWithContext is the same as GetOrganizations with the addition of a context.Context
+func (tenant *Tenant) GetOrganizationsWithContext(ctx context.Context, criteria OrganizationCriteria) (*Organizations, error) {
+	ctx = tenant.withClient(ctx)
 	organizations := &Organizations{}
 
-	err := client.get(buildAbsoluteURL(tenant.Organizations.Href, criteria.toQueryString()), organizations)
-	if err != nil {
+	err := ClientFromContext(ctx).get(ctx, buildAbsoluteURL(tenant.Organizations.Href, criteria.toQueryString()), organizations)
+	if err != nil && !IsStale(err) {
 		return nil, err
 	}
 
-	return organizations, nil
+	return organizations, err
+}
+
+//IterateOrganizations returns an OrganizationIterator over all the organizations associated with the Tenant, the pages are fetched lazily while iterating.
+//
+//The criteria filters and expansions are applied to every page, its limit is used as the page size.
+func (tenant *Tenant) IterateOrganizations(criteria OrganizationCriteria) *OrganizationIterator {
+	return tenant.IterateOrganizationsWithContext(tenant.clientContext(), criteria)
+}
+
+//IterateOrganizationsWithContext is the same as IterateOrganizations with the addition of a context.Context
+func (tenant *Tenant) IterateOrganizationsWithContext(ctx context.Context, criteria OrganizationCriteria) *OrganizationIterator {
+	ctx = tenant.withClient(ctx)
+	return newOrganizationIterator(ctx, tenant.Organizations.Href, criteria.baseCriteria)
 }
@@ -1,6 +1,7 @@
 package stormpath
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"testing"
@@ -64,6 +65,26 @@ func TestGetCurrentTenant(t *testing.T) {
 	assert.NotEmpty(t, currentTenant.Directories.Href)
 }
 
+func TestGetCurrentTenantWithContext(t *testing.T) {
+	t.Parallel()
+
+	currentTenant, err := CurrentTenantWithContext(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, tenant.Href, currentTenant.Href)
+}
+
+func TestGetCurrentTenantWithCanceledContext(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := CurrentTenantWithContext(ctx)
+
+	assert.Error(t, err)
+}
+
 //TODO: Fix panic: runtime error: invalid memory address or nil pointer dereference
 // This error is caused by Purge() method when there were errors during application creation.
 func TestTenantCreateApplication(t *testing.T) {
@@ -0,0 +1,121 @@
+package stormpath
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+//testServer is the fake Stormpath API shared by the unit tests, it records every request it receives
+//and tracks how many of them are in flight before passing them to the test handler.
+//
+//Tests that only need the regular API behavior use the stormpathtest server from the stormpath_test package,
+//testServer is meant for the tests that inject failures, delays or inspect the raw requests.
+type testServer struct {
+	*httptest.Server
+	mutex     sync.Mutex
+	requests  []testRequest
+	active    int
+	maxActive int
+}
+
+//testRequest is a request received by a testServer, its path has no trailing slash
+type testRequest struct {
+	method string
+	path   string
+	query  url.Values
+	header http.Header
+	body   []byte
+}
+
+//newTestServer starts a testServer answering with the given handler, the JSON content type is set on every response
+//and the request body can still be read by the handler
+func newTestServer(handler http.HandlerFunc) *testServer {
+	s := &testServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		s.mutex.Lock()
+		s.requests = append(s.requests, testRequest{
+			method: r.Method,
+			path:   strings.TrimSuffix(r.URL.Path, "/"),
+			query:  r.URL.Query(),
+			header: r.Header.Clone(),
+			body:   body,
+		})
+		s.active++
+		if s.active > s.maxActive {
+			s.maxActive = s.active
+		}
+		s.mutex.Unlock()
+
+		defer func() {
+			s.mutex.Lock()
+			s.active--
+			s.mutex.Unlock()
+		}()
+
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		handler(w, r)
+	}))
+	return s
+}
+
+//received returns the requests received so far
+func (s *testServer) received() []testRequest {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]testRequest{}, s.requests...)
+}
+
+//requestsTo returns the requests received with the given method and path
+func (s *testServer) requestsTo(method string, path string) []testRequest {
+	requests := []testRequest{}
+	for _, request := range s.received() {
+		if request.method == method && request.path == strings.TrimSuffix(path, "/") {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+//count returns the number of requests received with the given method and path
+func (s *testServer) count(method string, path string) int {
+	return len(s.requestsTo(method, path))
+}
+
+//queries returns the encoded query string of every request received so far
+func (s *testServer) queries() []string {
+	queries := []string{}
+	for _, request := range s.received() {
+		queries = append(queries, request.query.Encode())
+	}
+	return queries
+}
+
+//maxInFlight returns the maximum number of requests that were served concurrently
+func (s *testServer) maxInFlight() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.maxActive
+}
+
+//configuration returns a ClientConfiguration calling the server with the cache disabled
+func (s *testServer) configuration() ClientConfiguration {
+	config := LoadConfigurationWithCreds("testKeyID", "testKeySecret")
+	config.BaseURL = s.URL + "/v1/"
+	config.CacheManagerEnabled = false
+	return config
+}
+
+//newTestContext returns a context bound to a new Client created with the given configuration and cache
+func newTestContext(config ClientConfiguration, cache Cache) (context.Context, *Client) {
+	client := NewClient(config, cache)
+	return NewContext(context.Background(), client), client
+}
@@ -0,0 +1,124 @@
+package stormpath
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+//ErrTLSConfiguration is matched by the TLSConfigurationError of the requests of a client whose TLS files
+//couldn't be loaded, use errors.Is(err, ErrTLSConfiguration)
+var ErrTLSConfiguration = errors.New("invalid Stormpath client TLS configuration")
+
+//TLSConfigurationError is returned by every request of a client whose TLS files couldn't be loaded,
+//Err is the error of ClientConfiguration.TLSConfig
+type TLSConfigurationError struct {
+	Err error
+}
+
+func (e TLSConfigurationError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrTLSConfiguration, e.Err)
+}
+
+//Is matches ErrTLSConfiguration
+func (e TLSConfigurationError) Is(target error) bool {
+	return target == ErrTLSConfiguration
+}
+
+func (e TLSConfigurationError) Unwrap() error {
+	return e.Err
+}
+
+//tlsErrorTransport fails every request with the error of the TLS configuration
+type tlsErrorTransport struct {
+	err error
+}
+
+func (t tlsErrorTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	return nil, t.err
+}
+
+//newHTTPTransport creates the http.Transport for the given client configuration, it honors the
+//proxy, connection timeout and TLS settings.
+//
+//If the TLS files can't be loaded the error is logged and the returned transport fails every request with a
+//TLSConfigurationError, the configured certificate validation is never silently skipped.
+func newHTTPTransport(clientConfiguration ClientConfiguration) http.RoundTripper {
+	timeout := time.Duration(clientConfiguration.ConnectionTimeout) * time.Second
+
+	tlsConfig, err := clientConfiguration.TLSConfig()
+	if err != nil {
+		Logger.Printf("[ERROR] Couldn't load Stormpath client TLS configuration, every request fails [%s]", err)
+		return tlsErrorTransport{TLSConfigurationError{Err: err}}
+	}
+
+	return &http.Transport{
+		TLSClientConfig:       tlsConfig,
+		DisableCompression:    true,
+		Proxy:                 proxyURL(clientConfiguration),
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+	}
+}
+
+//proxyURL returns the http.Transport Proxy func for the configured proxy, or nil if no proxy is configured
+func proxyURL(clientConfiguration ClientConfiguration) func(*http.Request) (*url.URL, error) {
+	if clientConfiguration.ProxyHost == "" {
+		return nil
+	}
+
+	host := clientConfiguration.ProxyHost
+	if clientConfiguration.ProxyPort != 0 {
+		host = net.JoinHostPort(host, strconv.Itoa(clientConfiguration.ProxyPort))
+	}
+
+	u := &url.URL{Scheme: "http", Host: host}
+	if clientConfiguration.ProxyUsername != "" {
+		u.User = url.UserPassword(clientConfiguration.ProxyUsername, clientConfiguration.ProxyPassword)
+	}
+
+	return http.ProxyURL(u)
+}
+
+//TLSConfig returns the tls.Config for the configured CA bundle and client certificate,
+//if none are configured it returns the default tls.Config
+func (config ClientConfiguration) TLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if config.TLSCAFile != "" {
+		pem, err := ioutil.ReadFile(config.TLSCAFile)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no valid certificates found in %s", config.TLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if config.TLSCertFile != "" || config.TLSKeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.TLSCertFile, config.TLSKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,144 @@
+package stormpath
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyURL(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("id", "secret")
+	config.ProxyHost = "proxy.local"
+	config.ProxyPort = 3128
+	config.ProxyUsername = "user"
+	config.ProxyPassword = "p@ss"
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.stormpath.com/v1/tenants/current", nil)
+	u, err := proxyURL(config)(req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "proxy.local:3128", u.Host)
+	assert.Equal(t, "user", u.User.Username())
+	password, _ := u.User.Password()
+	assert.Equal(t, "p@ss", password)
+}
+
+func TestNoProxyURL(t *testing.T) {
+	t.Parallel()
+
+	assert.Nil(t, proxyURL(LoadConfigurationWithCreds("id", "secret")))
+}
+
+func TestRequestThroughAuthenticatedProxy(t *testing.T) {
+	t.Parallel()
+
+	var proxyAuthorization string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxyAuthorization = r.Header.Get("Proxy-Authorization")
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"tenant","name":"proxied"}`))
+	}))
+	defer proxy.Close()
+
+	proxyHost, _ := url.Parse(proxy.URL)
+	port, _ := strconv.Atoi(proxyHost.Port())
+
+	config := LoadConfigurationWithCreds("id", "secret")
+	config.BaseURL = "http://api.stormpath.invalid/v1/"
+	config.CacheManagerEnabled = false
+	config.ProxyHost = proxyHost.Hostname()
+	config.ProxyPort = port
+	config.ProxyUsername = "user"
+	config.ProxyPassword = "password"
+
+	proxiedTenant, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "proxied", proxiedTenant.Name)
+	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("user:password")), proxyAuthorization)
+}
+
+func TestRequestWithCustomCABundle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentTypeHeader, ApplicationJSON)
+		w.Write([]byte(`{"href":"tenant","name":"tls"}`))
+	}))
+	defer server.Close()
+
+	caFile, _ := ioutil.TempFile("", "stormpath-ca")
+	defer os.Remove(caFile.Name())
+	pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	caFile.Close()
+
+	config := LoadConfigurationWithCreds("id", "secret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+	assert.Error(t, err)
+
+	config.TLSCAFile = caFile.Name()
+
+	tlsTenant, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+	assert.NoError(t, err)
+	assert.Equal(t, "tls", tlsTenant.Name)
+}
+
+func TestTLSConfigInvalidFiles(t *testing.T) {
+	t.Parallel()
+
+	config := LoadConfigurationWithCreds("id", "secret")
+	config.TLSCAFile = "./test_files/empty.properties"
+
+	_, err := config.TLSConfig()
+	assert.Error(t, err)
+
+	config = LoadConfigurationWithCreds("id", "secret")
+	config.TLSCertFile = "./test_files/doesnotexist.pem"
+	config.TLSKeyFile = "./test_files/doesnotexist.key"
+
+	_, err = config.TLSConfig()
+	assert.Error(t, err)
+}
+
+func TestRequestWithInvalidTLSConfiguration(t *testing.T) {
+	t.Parallel()
+
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+	}))
+	defer server.Close()
+
+	config := LoadConfigurationWithCreds("id", "secret")
+	config.BaseURL = server.URL + "/v1/"
+	config.CacheManagerEnabled = false
+	config.RetryMaxAttempts = 3
+	config.RetryBaseDelay = time.Second
+	config.TLSCAFile = "./test_files/doesnotexist.pem"
+
+	start := time.Now()
+	_, err := CurrentTenantWithContext(NewContext(context.Background(), NewClient(config, nil)))
+
+	assert.Error(t, err)
+	assert.True(t, errors.Is(err, ErrTLSConfiguration))
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+	assert.True(t, time.Since(start) < time.Second)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&requests))
+}